@@ -7,14 +7,18 @@ import (
 	v1 "myesi-sbom-service-golang/internal/api/v1"
 	"myesi-sbom-service-golang/internal/config"
 	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/grpcapi"
 	"myesi-sbom-service-golang/internal/services"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // org time zones on images without zoneinfo
 
 	fiber "github.com/gofiber/fiber/v2"
 	fiberSwagger "github.com/gofiber/swagger"
+	"google.golang.org/grpc"
 )
 
 // @title MyESI SBOM Service API
@@ -27,9 +31,22 @@ func main() {
 	cfg := config.LoadConfig()
 	db.InitPostgres(cfg.DatabaseURL)
 
+	if cfg.OSVDataPath != "" {
+		if err := services.LoadOSVDatabase(cfg.OSVDataPath); err != nil {
+			log.Printf("[OSV][ERR] offline matcher disabled: %v", err)
+		}
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	if cfg.MaliciousFeedPath != "" {
+		if err := services.LoadMaliciousFeed(cfg.MaliciousFeedPath); err != nil {
+			log.Printf("[MAL][ERR] initial import failed: %v", err)
+		}
+		services.StartMaliciousFeedRefresher(ctx, cfg.MaliciousFeedPath, 5*time.Minute)
+	}
+
 	app := fiber.New(fiber.Config{BodyLimit: 25 * 1024 * 1024})
 	api := app.Group("/api")
 
@@ -39,13 +56,20 @@ func main() {
 	projectsGroup := api.Group("/projects")
 	v1.RegisterProjectRoutes(projectsGroup)
 
+	graphqlGroup := api.Group("/graphql")
+	v1.RegisterGraphQLRoutes(graphqlGroup)
+
 	services.StartCodeScanConsumer(ctx)
 	services.StartOutboxDispatcher(ctx)
+	services.StartRiskScoreWorker(ctx)
+	services.StartRetentionWorker(ctx, cfg.RetentionInterval)
+	services.StartProjectDeletionWorker(ctx, 5*time.Minute)
+	services.StartIdempotencyKeyPurger(ctx, time.Hour)
 
 	app.Get("/swagger/*", fiberSwagger.HandlerDefault) // Swagger UI endpoint
 	log.Println("SBOM service listening on port 8002")
 
-	errCh := make(chan error, 1)
+	errCh := make(chan error, 2)
 	go func() {
 		if err := app.Listen(":8002"); err != nil {
 			errCh <- err
@@ -54,6 +78,8 @@ func main() {
 		errCh <- nil
 	}()
 
+	grpcServer := startGRPC(cfg, errCh)
+
 	log.Println("[STARTUP] SBOM Service running...")
 
 	select {
@@ -61,7 +87,7 @@ func main() {
 		log.Println("[SHUTDOWN] signal received, shutting down...")
 	case err := <-errCh:
 		if err != nil {
-			log.Printf("[SHUTDOWN] Fiber server error: %v", err)
+			log.Printf("[SHUTDOWN] server error: %v", err)
 		}
 	}
 
@@ -71,9 +97,52 @@ func main() {
 	if err := app.Shutdown(); err != nil {
 		log.Printf("[SHUTDOWN][ERR] Fiber shutdown: %v", err)
 	}
+	if grpcServer != nil {
+		stopGRPC(grpcServer, 10*time.Second)
+	}
 
 	db.CloseDB()
 	services.CloseKafkaWriters()
 
 	log.Println("[EXIT] SBOM Service stopped gracefully")
 }
+
+// startGRPC serves the internal gRPC API when it is configured. A bad gRPC
+// configuration only disables it, so the HTTP API keeps running.
+func startGRPC(cfg *config.Config, errCh chan<- error) *grpc.Server {
+	if !cfg.GRPCEnabled {
+		log.Println("[STARTUP] gRPC API disabled, set GRPC_ADDR or GRPC_TLS_* to enable it")
+		return nil
+	}
+	srv, err := grpcapi.NewGRPCServer(cfg, db.Conn)
+	if err != nil {
+		log.Printf("[STARTUP][WARN] gRPC API disabled: %v", err)
+		return nil
+	}
+	lis, err := net.Listen("tcp", cfg.GRPCAddr)
+	if err != nil {
+		log.Printf("[STARTUP][WARN] gRPC API disabled: listen on %s: %v", cfg.GRPCAddr, err)
+		return nil
+	}
+	go func() {
+		log.Printf("SBOM gRPC API listening on %s", cfg.GRPCAddr)
+		if err := srv.Serve(lis); err != nil {
+			errCh <- err
+		}
+	}()
+	return srv
+}
+
+// stopGRPC drains in-flight calls, cutting open change streams after timeout.
+func stopGRPC(srv *grpc.Server, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		srv.Stop()
+	}
+}
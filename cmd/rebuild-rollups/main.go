@@ -0,0 +1,38 @@
+// Command rebuild-rollups recomputes the daily analytics rollups from the
+// sboms table, for one organization or all of them:
+//
+//	go run ./cmd/rebuild-rollups -org 42
+//
+// Run it after backfills, restores or an organization time zone change.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	_ "time/tzdata"
+
+	"myesi-sbom-service-golang/internal/config"
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+)
+
+func main() {
+	orgID := flag.Int("org", 0, "organization to rebuild; 0 rebuilds every organization")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+	db.InitPostgres(cfg.DatabaseURL)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	out, err := services.RebuildRollups(ctx, db.Conn, *orgID)
+	stop()
+	db.CloseDB()
+	if err != nil {
+		log.Fatalf("[ROLLUP][ERR] rebuild failed: %v", err)
+	}
+	log.Printf("[ROLLUP] done: org=%d sboms=%d duration=%s", out.OrganizationID, out.SBOMs, out.Duration)
+}
@@ -15,9 +15,877 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/github": {
+        "/analytics": {
+            "get": {
+                "description": "SBOM, component and license series over a window, bucketed by day, week or month in the organization's time zone",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "SBOM analytics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD), default 13 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD), default today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "day|week|month (default day)",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "source|project|ecosystem|label:\u003ckey\u003e",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone, default the organization's",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector (e.g. team=payments,tier!=internal)",
+                        "name": "labels",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/components": {
+            "get": {
+                "description": "Find components of the organization's SBOMs by name, ecosystem and version range (e.g. \"\u003c4.17.21\", \"[1.0,2.0)\", \"vers:npm/\u003e=1.0.0|\u003c2.0.0\")",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Query components by version range",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Component name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ecosystem (npm|pypi|maven|golang|nuget|gem|cargo|...)",
+                        "name": "ecosystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version range expression",
+                        "name": "version_range",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project Name",
+                        "name": "project_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector (e.g. team=payments,tier!=internal)",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit (max 1000, default 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/components/export": {
+            "get": {
+                "description": "Streams every component of the organization's SBOMs as CSV or NDJSON, optionally limited to one project or a label selector",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Export components",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv (default) or ndjson",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector (e.g. team=payments,tier!=internal)",
+                        "name": "labels",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/components/inventory": {
+            "get": {
+                "description": "Paginated list of every distinct component used in the organization, with versions in use, project counts, licenses and first/last seen dates",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Component inventory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ecosystem (purl type, e.g. npm, pypi, maven, golang)",
+                        "name": "ecosystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "License id or name (case-insensitive)",
+                        "name": "license",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by component name",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (name|ecosystem|projects|versions|first_seen|last_seen)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc|desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (max 200, default 50)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/components/search": {
+            "get": {
+                "description": "Answer \"where do we use X?\": find a package by purl or name in every current SBOM of the organization, grouped by project",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Search components across the organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Package URL (version optional)",
+                        "name": "purl",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Component name (required without purl)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ecosystem",
+                        "name": "ecosystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version range expression",
+                        "name": "version_range",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector (e.g. team=payments,tier!=internal)",
+                        "name": "labels",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard": {
+            "get": {
+                "description": "One-call home page summary: project counts by status, SBOM freshness, component totals, top ecosystems, license risk buckets, vulnerability severity totals and the riskiest projects",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Organization security dashboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Days without an SBOM before a project counts as stale (default 30, max 365)",
+                        "name": "stale_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries in ranked lists (default 5, max 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "description": "Read-only GraphQL API over the organization's projects, SBOMs, components and vulnerabilities",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GraphQL"
+                ],
+                "summary": "GraphQL query",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/internal-packages": {
+            "get": {
+                "description": "Package names the organization publishes privately, used for dependency-confusion checks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Internal package names",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the organization's internal package list. A trailing \"*\" matches by prefix.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Replace internal package names",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/list": {
+            "get": {
+                "description": "List the organization's SBOMs with filters, sorting and cursor pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "List SBOMs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Name",
+                        "name": "project_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Source (manual, auto-code-scan, ...)",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Manifest file name",
+                        "name": "manifest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only SBOMs containing components of this ecosystem (purl type)",
+                        "name": "ecosystem",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC3339, or YYYY-MM-DD inclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum component count",
+                        "name": "min_components",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum component count",
+                        "name": "max_components",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at|updated_at|project_name|manifest_name|components",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc|desc (default desc)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (max 200, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to sbom to include the full SBOM document of each item",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/recent": {
+            "get": {
+                "description": "Get recent SBOM uploads with optional project filter",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Recent SBOM uploads",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Name",
+                        "name": "project_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Source (manual|auto-code-scan)",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search (project or manifest)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label selector (e.g. team=payments,tier!=internal)",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (max 100, default 10)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/retention": {
+            "get": {
+                "description": "Effective retention policy (organization or plan) and the organization's legal holds",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "SBOM retention policy",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the organization's policy. A null rule is not enforced; legal_hold suspends all purging.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Set organization retention policy",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/retention/holds": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Place a project on legal hold",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/retention/holds/{id}": {
+            "delete": {
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Release a legal hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/retention/run": {
+            "post": {
+                "description": "Applies the organization's policy immediately and reports what was purged. dry_run=true only reports.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Enforce retention now",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Report without deleting",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/settings": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Organization SBOM settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Fields left out of the payload keep their current value",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Update organization SBOM settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/upload": {
             "post": {
-                "description": "Fetch a manifest file from GitHub using REST API and generate a SBOM",
+                "description": "Upload a manifest file and generate an SBOM",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Upload a manifest file to generate SBOM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project Name",
+                        "name": "project_name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project ID, takes precedence over project_name",
+                        "name": "project_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Manifest file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the first response for retries with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/{id}": {
+            "get": {
+                "description": "Retrieve a single SBOM by its ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -27,16 +895,14 @@ const docTemplate = `{
                 "tags": [
                     "SBOM"
                 ],
-                "summary": "Upload a manifest from a GitHub repository to generate SBOM",
+                "summary": "Get SBOM by ID",
                 "parameters": [
                     {
-                        "description": "GitHub repo and file info",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/v1.GitHubSBOMRequest"
-                        }
+                        "type": "string",
+                        "description": "SBOM ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -47,15 +913,43 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an SBOM with its vulnerabilities, scan jobs and stored object",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Delete SBOM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SBOM ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -64,30 +958,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/list": {
+        "/{id}/components/export": {
             "get": {
-                "description": "Get list of SBOMs filtered by project name",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Streams the components of one SBOM as CSV or NDJSON",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/x-ndjson"
                 ],
                 "tags": [
                     "SBOM"
                 ],
-                "summary": "List SBOMs",
+                "summary": "Export SBOM components",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Project Name",
-                        "name": "project_name",
-                        "in": "query"
+                        "description": "SBOM ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Limit",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "csv (default) or ndjson",
+                        "name": "format",
                         "in": "query"
                     }
                 ],
@@ -95,15 +988,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
+                            "type": "file"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -112,46 +1001,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/recent": {
+        "/{id}/download": {
             "get": {
-                "description": "Get recent SBOM uploads with optional project filter",
-                "consumes": [
+                "description": "Streams the stored SBOM from object storage, falling back to the Postgres copy. Honours Accept-Encoding: gzip.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Download SBOM document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SBOM ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/{id}/findings": {
+            "get": {
+                "description": "Typosquatting and dependency-confusion findings recorded for an SBOM",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "SBOM"
                 ],
-                "summary": "Recent SBOM uploads",
+                "summary": "Supply-chain findings of an SBOM",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Project Name",
-                        "name": "project_name",
-                        "in": "query"
+                        "description": "SBOM ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Limit",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "typosquat|dependency_confusion",
+                        "name": "kind",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -160,32 +1073,59 @@ const docTemplate = `{
                 }
             }
         },
-        "/upload": {
-            "post": {
-                "description": "Upload a manifest file and generate an SBOM",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/{id}/labels": {
+            "get": {
+                "description": "Return the labels set on the SBOM and the effective labels including those inherited from its project",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "SBOM"
                 ],
-                "summary": "Upload a manifest file to generate SBOM",
+                "summary": "Get SBOM labels",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Project Name",
-                        "name": "project_name",
-                        "in": "formData",
+                        "description": "SBOM ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Replace SBOM labels",
+                "parameters": [
                     {
-                        "type": "file",
-                        "description": "Manifest file",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "SBOM ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -207,19 +1147,88 @@ const docTemplate = `{
                 }
             }
         },
-        "/{id}": {
+        "/{id}/labels/{key}": {
+            "delete": {
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Remove an SBOM label",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SBOM ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/{id}/report": {
             "get": {
-                "description": "Retrieve a single SBOM by its ID",
-                "consumes": [
-                    "application/json"
+                "description": "Single-file HTML report of one SBOM: project metadata, SBOM summary, components, license breakdown and vulnerabilities",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "SBOM"
+                ],
+                "summary": "Download SBOM HTML report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SBOM ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/{id}/url": {
+            "get": {
+                "description": "Returns a short-lived GET URL for the stored SBOM object. ttl (seconds) may shorten, never extend, the configured lifetime.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "SBOM"
                 ],
-                "summary": "Get SBOM by ID",
+                "summary": "Presigned SBOM URL",
                 "parameters": [
                     {
                         "type": "string",
@@ -227,6 +1236,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Lifetime in seconds",
+                        "name": "ttl",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -247,29 +1262,6 @@ const docTemplate = `{
                 }
             }
         }
-    },
-    "definitions": {
-        "v1.GitHubSBOMRequest": {
-            "type": "object",
-            "properties": {
-                "branch": {
-                    "type": "string"
-                },
-                "file": {
-                    "description": "e.g package.json, go.mod, requirements.txt...",
-                    "type": "string"
-                },
-                "owner": {
-                    "type": "string"
-                },
-                "project_name": {
-                    "type": "string"
-                },
-                "repo": {
-                    "type": "string"
-                }
-            }
-        }
     }
 }`
 
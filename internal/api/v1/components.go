@@ -0,0 +1,214 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+	"myesi-sbom-service-golang/internal/versioning"
+	"sort"
+	"strings"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// listComponents godoc
+// @Summary Query components by version range
+// @Description Find components of the organization's SBOMs by name, ecosystem and version range (e.g. "<4.17.21", "[1.0,2.0)", "vers:npm/>=1.0.0|<2.0.0")
+// @Tags SBOM
+// @Produce json
+// @Param name query string true "Component name"
+// @Param ecosystem query string false "Ecosystem (npm|pypi|maven|golang|nuget|gem|cargo|...)"
+// @Param version_range query string false "Version range expression"
+// @Param project_name query string false "Project Name"
+// @Param labels query string false "Label selector (e.g. team=payments,tier!=internal)"
+// @Param limit query int false "Limit (max 1000, default 200)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /components [get]
+func listComponents(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(c.Query("name"))
+	if name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name required"})
+	}
+	limit := c.QueryInt("limit", 200)
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+
+	q := services.ComponentQuery{
+		Name:         name,
+		Ecosystem:    strings.ToLower(strings.TrimSpace(c.Query("ecosystem"))),
+		VersionRange: strings.TrimSpace(c.Query("version_range")),
+		Project:      c.Query("project_name"),
+		Limit:        limit,
+	}
+	if q.Labels, err = labelSelectorQuery(c); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if _, err := versioning.ParseRange(q.Ecosystem, q.VersionRange); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	list, err := findComponentsService(c.Context(), db.Conn, orgID, q)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if list == nil {
+		list = []services.ComponentOccurrence{}
+	}
+
+	return c.JSON(fiber.Map{
+		"data":  list,
+		"total": len(list),
+	})
+}
+
+// searchComponents godoc
+// @Summary Search components across the organization
+// @Description Answer "where do we use X?": find a package by purl or name in every current SBOM of the organization, grouped by project
+// @Tags SBOM
+// @Produce json
+// @Param purl query string false "Package URL (version optional)"
+// @Param name query string false "Component name (required without purl)"
+// @Param ecosystem query string false "Ecosystem"
+// @Param version_range query string false "Version range expression"
+// @Param labels query string false "Label selector (e.g. team=payments,tier!=internal)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /components/search [get]
+func searchComponents(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+
+	filter, err := services.NewComponentFilter(
+		c.Query("name"),
+		c.Query("purl"),
+		c.Query("ecosystem"),
+		c.Query("version_range"),
+	)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	selector, err := labelSelectorQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	args := []interface{}{orgID, filter.SearchName()}
+	labelClause := selector.SQL(services.SBOMLabelValue("s.id", "s.project_id"), func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	})
+
+	query := fmt.Sprintf(`
+		SELECT s.id, COALESCE(s.project_id, 0), s.project_name, COALESCE(s.manifest_name, ''), c,
+		       root.deps, COALESCE(jsonb_typeof(s.sbom->'dependencies') = 'array', FALSE)
+		FROM sboms s
+		CROSS JOIN LATERAL jsonb_array_elements(COALESCE(s.sbom->'components', '[]'::jsonb)) c
+		LEFT JOIN LATERAL (
+			SELECT d->'dependsOn' AS deps
+			FROM jsonb_array_elements(COALESCE(s.sbom->'dependencies', '[]'::jsonb)) d
+			WHERE d->>'ref' = s.sbom->'metadata'->'component'->>'bom-ref'
+			LIMIT 1
+		) root ON TRUE
+		WHERE %s
+		  AND LOWER(c->>'name') = LOWER($2)
+		  AND %s
+		ORDER BY s.project_name, s.manifest_name
+	`, orgProjectFilterClause(), labelClause)
+
+	rows, err := db.Conn.QueryContext(c.Context(), query, args...)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer rows.Close()
+
+	type ProjectHit struct {
+		ProjectID   int                            `json:"project_id"`
+		ProjectName string                         `json:"project_name"`
+		Versions    []string                       `json:"versions"`
+		Occurrences []services.ComponentOccurrence `json:"occurrences"`
+	}
+
+	var (
+		order       []string
+		byProject   = map[string]*ProjectHit{}
+		occurrences int
+	)
+	for rows.Next() {
+		var (
+			sbomID, projectName, manifestName string
+			projectID                         int
+			rawComp, rawDeps                  []byte
+			hasGraph                          bool
+		)
+		if err := rows.Scan(&sbomID, &projectID, &projectName, &manifestName, &rawComp, &rawDeps, &hasGraph); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		var comp map[string]interface{}
+		if err := json.Unmarshal(rawComp, &comp); err != nil {
+			continue
+		}
+		occ, ok := filter.Match(comp)
+		if !ok {
+			continue
+		}
+		var rootDeps []string
+		if len(rawDeps) > 0 {
+			_ = json.Unmarshal(rawDeps, &rootDeps)
+		}
+		occ.SbomID = sbomID
+		occ.ProjectID = projectID
+		occ.ProjectName = projectName
+		occ.ManifestName = manifestName
+		occ.Relationship = services.ComponentRelationship(comp, rootDeps, hasGraph)
+
+		hit, exists := byProject[projectName]
+		if !exists {
+			hit = &ProjectHit{ProjectID: projectID, ProjectName: projectName, Versions: []string{}}
+			byProject[projectName] = hit
+			order = append(order, projectName)
+		}
+		hit.Occurrences = append(hit.Occurrences, occ)
+		if !containsString(hit.Versions, occ.Version) {
+			hit.Versions = append(hit.Versions, occ.Version)
+		}
+		occurrences++
+	}
+	if err := rows.Err(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	list := make([]ProjectHit, 0, len(order))
+	for _, name := range order {
+		hit := byProject[name]
+		eco := hit.Occurrences[0].Ecosystem
+		sort.Slice(hit.Versions, func(i, j int) bool {
+			return versioning.Compare(eco, hit.Versions[i], hit.Versions[j]) < 0
+		})
+		list = append(list, *hit)
+	}
+
+	return c.JSON(fiber.Map{
+		"data":              list,
+		"total_projects":    len(list),
+		"total_occurrences": occurrences,
+	})
+}
+
+func containsString(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListComponents_Success(t *testing.T) {
+	app := newTestApp()
+
+	orig := findComponentsService
+	t.Cleanup(func() { findComponentsService = orig })
+
+	findComponentsService = func(ctx context.Context, conn *sql.DB, orgID int, q services.ComponentQuery) ([]services.ComponentOccurrence, error) {
+		require.Equal(t, 7, orgID)
+		require.Equal(t, "lodash", q.Name)
+		require.Equal(t, "npm", q.Ecosystem)
+		require.Equal(t, "<4.17.21", q.VersionRange)
+		return []services.ComponentOccurrence{
+			{SbomID: "sb1", ProjectName: "proj1", Name: "lodash", Version: "4.17.20", Ecosystem: "npm"},
+		}, nil
+	}
+
+	req := httptest.NewRequest("GET", "/api/sbom/components?name=lodash&ecosystem=npm&version_range="+url.QueryEscape("<4.17.21"), nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestListComponents_InvalidRange_400(t *testing.T) {
+	app := newTestApp()
+
+	req := httptest.NewRequest("GET", "/api/sbom/components?name=lodash&version_range="+url.QueryEscape(">="), nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestListComponents_MissingName_400(t *testing.T) {
+	app := newTestApp()
+
+	req := httptest.NewRequest("GET", "/api/sbom/components", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSearchComponents_GroupsByProject(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	log4j := func(version, ref string) []byte {
+		return []byte(`{"bom-ref":"` + ref + `","name":"log4j-core","version":"` + version +
+			`","purl":"pkg:maven/org.apache.logging.log4j/log4j-core@` + version + `"}`)
+	}
+	rows := sqlmock.NewRows([]string{"id", "project_id", "project_name", "manifest_name", "c", "deps", "has_graph"}).
+		AddRow("sb1", 1, "billing", "pom.xml", log4j("2.14.1", "a"), []byte(`["a"]`), true).
+		AddRow("sb2", 1, "billing", "build.gradle", log4j("2.17.1", "b"), []byte(`["x"]`), true).
+		AddRow("sb3", 2, "search", "pom.xml", log4j("2.9.0", "c"), nil, false).
+		AddRow("sb4", 3, "other", "pom.xml", []byte(`{"name":"log4j-core","version":"2.14.1","purl":"pkg:maven/com.evil/log4j-core@2.14.1"}`), nil, false)
+
+	mock.ExpectQuery(`SELECT s.id, COALESCE\(s.project_id, 0\)`).
+		WithArgs(7, "log4j-core").
+		WillReturnRows(rows)
+
+	purl := "pkg:maven/org.apache.logging.log4j/log4j-core"
+	req := httptest.NewRequest("GET", "/api/sbom/components/search?purl="+url.QueryEscape(purl)+"&version_range="+url.QueryEscape("<2.17.0"), nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []struct {
+			ProjectName string                         `json:"project_name"`
+			Versions    []string                       `json:"versions"`
+			Occurrences []services.ComponentOccurrence `json:"occurrences"`
+		} `json:"data"`
+		TotalProjects    int `json:"total_projects"`
+		TotalOccurrences int `json:"total_occurrences"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Equal(t, 2, body.TotalProjects)
+	require.Equal(t, 2, body.TotalOccurrences)
+	require.Equal(t, "billing", body.Data[0].ProjectName)
+	require.Equal(t, []string{"2.14.1"}, body.Data[0].Versions)
+	require.Equal(t, services.RelationshipDirect, body.Data[0].Occurrences[0].Relationship)
+	require.Equal(t, services.RelationshipUnknown, body.Data[1].Occurrences[0].Relationship)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchComponents_RequiresNameOrPurl_400(t *testing.T) {
+	app := newTestApp()
+
+	req := httptest.NewRequest("GET", "/api/sbom/components/search?ecosystem=npm", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// sbomDashboard godoc
+// @Summary Organization security dashboard
+// @Description One-call home page summary: project counts by status, SBOM freshness, component totals, top ecosystems, license risk buckets, vulnerability severity totals and the riskiest projects
+// @Tags SBOM
+// @Produce json
+// @Param stale_days query int false "Days without an SBOM before a project counts as stale (default 30, max 365)"
+// @Param limit query int false "Entries in ranked lists (default 5, max 20)"
+// @Success 200 {object} map[string]interface{}
+// @Router /dashboard [get]
+func sbomDashboard(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+
+	dashboard, err := services.LoadOrgDashboard(c.Context(), db.Conn, orgID, services.DashboardQuery{
+		StaleDays: c.QueryInt("stale_days", services.DefaultDashboardStaleDays),
+		Limit:     c.QueryInt("limit", services.DefaultDashboardLimit),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": dashboard})
+}
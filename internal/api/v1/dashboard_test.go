@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSBOMDashboard_Success(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	recent := time.Now().Add(-24 * time.Hour)
+
+	mock.ExpectQuery(`LEFT JOIN project_deletions d`).
+		WithArgs(7, services.ProjectDeletionCompleted).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "active", "archived", "deleting", "vulnerable"}).AddRow(5, 3, 2, 1, 2))
+	mock.ExpectQuery(`MAX\(COALESCE\(s.updated_at, s.created_at\)\)`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "risk", "vulns", "last"}).
+			AddRow(1, "shop", 7.5, 4, recent).
+			AddRow(2, "legacy", 2.0, 1, old).
+			AddRow(3, "empty", 0.0, 0, nil))
+	mock.ExpectQuery(`COUNT\(DISTINCT COALESCE\(NULLIF\(split_part\(c->>'purl'`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "unique"}).AddRow(120, 80))
+	mock.ExpectQuery(`FROM sbom_daily_ecosystem_rollups`).
+		WithArgs(7, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"ecosystem", "components"}).AddRow("npm", 90).AddRow("pypi", 30))
+	mock.ExpectQuery(`FROM sbom_daily_license_rollups`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"license", "components"}).
+			AddRow("MIT", 70).AddRow("Apache-2.0", 20).AddRow("GPL-3.0", 5).AddRow("UNKNOWN", 25))
+	mock.ExpectQuery(`FROM vulnerabilities v`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"severity", "count"}).
+			AddRow("CRITICAL", 1).AddRow("moderate", 2).AddRow("medium", 1).AddRow("", 1))
+	mock.ExpectQuery(`ORDER BY COALESCE\(p.avg_risk_score, 0\) DESC`).
+		WithArgs(7, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "risk", "vulns", "last"}).
+			AddRow(1, "shop", 7.5, 4, recent))
+
+	req := httptest.NewRequest("GET", "/api/sbom/dashboard", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data services.OrgDashboard `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	d := body.Data
+	require.EqualValues(t, 1, d.Projects.Deleting)
+	require.EqualValues(t, 1, d.Freshness.Fresh)
+	require.EqualValues(t, 1, d.Freshness.Stale)
+	require.EqualValues(t, 1, d.Freshness.NeverScanned)
+	require.Equal(t, []string{"empty", "legacy"}, []string{d.Freshness.StaleProjects[0].Name, d.Freshness.StaleProjects[1].Name})
+	require.EqualValues(t, 80, d.Components.Unique)
+	require.Equal(t, "npm", d.TopEcosystems[0].Name)
+	require.EqualValues(t, 90, d.LicenseRisk[services.LicenseRiskPermissive])
+	require.EqualValues(t, 5, d.LicenseRisk[services.LicenseRiskStrongCopyleft])
+	require.EqualValues(t, 25, d.LicenseRisk[services.LicenseRiskUnknown])
+	require.EqualValues(t, 3, d.Vulnerabilities["medium"])
+	require.EqualValues(t, 1, d.Vulnerabilities["unknown"])
+	require.Len(t, d.RiskiestProjects, 1)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSBOMDashboard_DBError_500(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	mock.ExpectQuery(`LEFT JOIN project_deletions d`).WillReturnError(assertErr("db down"))
+
+	req := httptest.NewRequest("GET", "/api/sbom/dashboard", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
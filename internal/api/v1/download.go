@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+var (
+	openSBOMObjectService    = services.OpenSBOMObject
+	presignSBOMObjectService = services.PresignSBOMObject
+)
+
+// downloadSBOM godoc
+// @Summary Download SBOM document
+// @Description Streams the stored SBOM from object storage, falling back to the Postgres copy. Honours Accept-Encoding: gzip.
+// @Tags SBOM
+// @Produce json
+// @Param id path string true "SBOM ID"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{}
+// @Router /{id}/download [get]
+func downloadSBOM(c *fiber.Ctx) error {
+	id := c.Params("id")
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	if err := ensureSBOMAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+	sbom, err := getSBOMService(c.Context(), db.Conn, id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "SBOM not found"})
+	}
+
+	var body io.ReadCloser
+	if sbom.ObjectURL.Valid && sbom.ObjectURL.String != "" {
+		body, err = openSBOMObjectService(c.Context(), sbom.ObjectURL.String)
+		if err != nil {
+			log.Printf("[DOWNLOAD][WARN] sbom=%s object unavailable, serving db copy: %v", id, err)
+			body = nil
+		}
+	}
+	if body == nil {
+		if len(sbom.Sbom) == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "SBOM document not available"})
+		}
+		body = io.NopCloser(bytes.NewReader(sbom.Sbom))
+	}
+
+	filename := services.SBOMDownloadFilename(sbom.ProjectName, sbom.ManifestName.String)
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Set(fiber.HeaderVary, fiber.HeaderAcceptEncoding)
+
+	gz := acceptsGzip(c.Get(fiber.HeaderAcceptEncoding))
+	if gz {
+		c.Set(fiber.HeaderContentEncoding, "gzip")
+	}
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer body.Close()
+		var dst io.Writer = w
+		var zw *gzip.Writer
+		if gz {
+			zw = gzip.NewWriter(w)
+			dst = zw
+		}
+		if _, err := io.Copy(dst, body); err != nil {
+			log.Printf("[DOWNLOAD][ERR] sbom=%s stream: %v", id, err)
+		}
+		if zw != nil {
+			_ = zw.Close()
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
+
+// presignSBOM godoc
+// @Summary Presigned SBOM URL
+// @Description Returns a short-lived GET URL for the stored SBOM object. ttl (seconds) may shorten, never extend, the configured lifetime.
+// @Tags SBOM
+// @Produce json
+// @Param id path string true "SBOM ID"
+// @Param ttl query int false "Lifetime in seconds"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /{id}/url [get]
+func presignSBOM(c *fiber.Ctx) error {
+	id := c.Params("id")
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	if err := ensureSBOMAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	ttl := services.PresignTTL()
+	if raw := strings.TrimSpace(c.Query("ttl")); raw != "" {
+		secs, err := strconv.Atoi(raw)
+		if err != nil || secs <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ttl must be a positive number of seconds"})
+		}
+		if requested := time.Duration(secs) * time.Second; requested < ttl {
+			ttl = requested
+		}
+	}
+
+	sbom, err := getSBOMService(c.Context(), db.Conn, id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "SBOM not found"})
+	}
+	if !sbom.ObjectURL.Valid || sbom.ObjectURL.String == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "SBOM is not stored in object storage, use /download"})
+	}
+
+	url, expiresAt, err := presignSBOMObjectService(c.Context(), sbom.ObjectURL.String, ttl)
+	if err != nil {
+		if errors.Is(err, services.ErrSBOMObjectNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "SBOM object not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to presign SBOM object"})
+	}
+	return c.JSON(fiber.Map{
+		"url":        url,
+		"expires_at": expiresAt,
+		"ttl":        int(ttl.Seconds()),
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(fields[0]), "gzip") {
+			continue
+		}
+		for _, param := range fields[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package v1
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	null "github.com/aarondl/null/v8"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func stubDownloadSBOM(t *testing.T, sbom *models.Sbom, object func(ctx context.Context, url string) (io.ReadCloser, error)) {
+	t.Helper()
+	origGet, origOpen := getSBOMService, openSBOMObjectService
+	t.Cleanup(func() {
+		getSBOMService = origGet
+		openSBOMObjectService = origOpen
+	})
+	getSBOMService = func(ctx context.Context, conn *sql.DB, id string) (*models.Sbom, error) {
+		return sbom, nil
+	}
+	openSBOMObjectService = object
+}
+
+func TestDownloadSBOM_FallsBackToDB(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	mock.ExpectQuery(`SELECT 1\s+FROM sboms s`).
+		WithArgs(7, "sb1").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	stubDownloadSBOM(t, &models.Sbom{
+		ID:           "sb1",
+		ProjectName:  "Shop API",
+		ManifestName: null.StringFrom("package.json"),
+		ObjectURL:    null.StringFrom("https://bucket.s3.amazonaws.com/sbom/org-7/project-1/package.json/1-x.json"),
+		Sbom:         []byte(`{"bomFormat":"CycloneDX"}`),
+	}, func(ctx context.Context, url string) (io.ReadCloser, error) {
+		return nil, errors.New("s3 down")
+	})
+
+	req := httptest.NewRequest("GET", "/api/sbom/sb1/download", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, `attachment; filename="shop-api-package.json.sbom.json"`, resp.Header.Get("Content-Disposition"))
+	require.Empty(t, resp.Header.Get("Content-Encoding"))
+
+	body, _ := io.ReadAll(resp.Body)
+	require.JSONEq(t, `{"bomFormat":"CycloneDX"}`, string(body))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDownloadSBOM_StreamsObjectGzip(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	mock.ExpectQuery(`SELECT 1\s+FROM sboms s`).
+		WithArgs(7, "sb1").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	stubDownloadSBOM(t, &models.Sbom{
+		ID:          "sb1",
+		ProjectName: "shop",
+		ObjectURL:   null.StringFrom("https://bucket.s3.amazonaws.com/sbom/org-7/project-1/manifest/1-x.json"),
+	}, func(ctx context.Context, url string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(`{"from":"s3"}`)), nil
+	})
+
+	req := httptest.NewRequest("GET", "/api/sbom/sb1/download", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	req.Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	require.Equal(t, `attachment; filename="shop.sbom.json"`, resp.Header.Get("Content-Disposition"))
+
+	zr, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(zr)
+	require.JSONEq(t, `{"from":"s3"}`, string(body))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	require.True(t, acceptsGzip("gzip, deflate"))
+	require.True(t, acceptsGzip("br, GZIP;q=0.5"))
+	require.False(t, acceptsGzip("gzip;q=0"))
+	require.False(t, acceptsGzip("deflate"))
+	require.False(t, acceptsGzip(""))
+}
+
+func TestPresignSBOM_ClampsTTL(t *testing.T) {
+	app := newTestApp()
+	t.Setenv("S3_PRESIGN_TTL", "10m")
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	mock.ExpectQuery(`SELECT 1\s+FROM sboms s`).
+		WithArgs(7, "sb1").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	stubDownloadSBOM(t, &models.Sbom{ID: "sb1", ObjectURL: null.StringFrom("s3://bom/sbom/org-7/project-1/m/1-x.json")}, nil)
+	origPresign := presignSBOMObjectService
+	t.Cleanup(func() { presignSBOMObjectService = origPresign })
+	var gotTTL time.Duration
+	presignSBOMObjectService = func(ctx context.Context, objectURL string, ttl time.Duration) (string, time.Time, error) {
+		gotTTL = ttl
+		return "http://localhost:9000/bom/sbom/org-7/project-1/m/1-x.json?X-Amz-Signature=abc", time.Now().Add(ttl), nil
+	}
+
+	req := httptest.NewRequest("GET", "/api/sbom/sb1/url?ttl=3600", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, 10*time.Minute, gotTTL)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPresignSBOM_NoObject_404(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	mock.ExpectQuery(`SELECT 1\s+FROM sboms s`).
+		WithArgs(7, "sb1").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	stubDownloadSBOM(t, &models.Sbom{ID: "sb1"}, nil)
+
+	req := httptest.NewRequest("GET", "/api/sbom/sb1/url", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var exportColumns = []string{"project_name", "manifest_name", "id", "c", "deps", "has_graph", "vulns"}
+
+const exportRoot = `{"name":"express","version":"4.18.2","purl":"pkg:npm/express@4.18.2","bom-ref":"a",` +
+	`"licenses":[{"license":{"id":"MIT"}}]}`
+
+const exportDep = `{"name":"qs","version":"6.5.2","purl":"pkg:npm/qs@6.5.2","bom-ref":"b",` +
+	`"licenses":[{"expression":"BSD-3-Clause OR MIT"}]}`
+
+func TestExportOrgComponents_CSV(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	mock.ExpectQuery(`SELECT name\s+FROM projects`).
+		WithArgs(3, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Shop API"))
+	mock.ExpectQuery(`FROM sboms s`).
+		WithArgs(7, 3).
+		WillReturnRows(sqlmock.NewRows(exportColumns).
+			AddRow("Shop API", "package.json", "sb1", []byte(exportRoot), []byte(`["a"]`), true, 0).
+			AddRow("Shop API", "package.json", "sb1", []byte(exportDep), []byte(`["a"]`), true, 2))
+
+	req := httptest.NewRequest("GET", "/api/sbom/components/export?project_id=3", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	require.Equal(t, `attachment; filename="shop-api-components.csv"`, resp.Header.Get("Content-Disposition"))
+
+	body, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	require.Equal(t, []string{
+		"project,manifest,sbom_id,name,version,ecosystem,purl,licenses,relationship,vulnerabilities",
+		"Shop API,package.json,sb1,express,4.18.2,npm,pkg:npm/express@4.18.2,MIT,direct,0",
+		"Shop API,package.json,sb1,qs,6.5.2,npm,pkg:npm/qs@6.5.2,BSD-3-Clause OR MIT,transitive,2",
+	}, lines)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportSBOMComponents_NDJSON(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	mock.ExpectQuery(`SELECT 1\s+FROM sboms s`).
+		WithArgs(7, "sb1").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock.ExpectQuery(`SELECT project_name, COALESCE\(manifest_name, ''\) FROM sboms`).
+		WithArgs("sb1").
+		WillReturnRows(sqlmock.NewRows([]string{"project_name", "manifest_name"}).AddRow("Shop API", "package.json"))
+	mock.ExpectQuery(`FROM sboms s`).
+		WithArgs(7, "sb1").
+		WillReturnRows(sqlmock.NewRows(exportColumns).
+			AddRow("Shop API", "package.json", "sb1", []byte(exportDep), nil, false, 1))
+
+	req := httptest.NewRequest("GET", "/api/sbom/sb1/components/export?format=ndjson", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	require.Equal(t, `attachment; filename="shop-api-package.json-components.ndjson"`, resp.Header.Get("Content-Disposition"))
+
+	body, _ := io.ReadAll(resp.Body)
+	require.JSONEq(t, `{"project":"Shop API","manifest":"package.json","sbom_id":"sb1","name":"qs","version":"6.5.2",
+		"ecosystem":"npm","purl":"pkg:npm/qs@6.5.2","licenses":["BSD-3-Clause OR MIT"],"relationship":"unknown","vulnerabilities":1}`,
+		strings.TrimSpace(string(body)))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportComponents_BadFormat(t *testing.T) {
+	app := newTestApp()
+
+	req := httptest.NewRequest("GET", "/api/sbom/components/export?format=xlsx", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
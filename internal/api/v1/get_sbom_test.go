@@ -23,7 +23,7 @@ func TestGetSBOM_Success(t *testing.T) {
 	db.Conn = sqlDB
 
 	mock.ExpectQuery(`SELECT 1\s+FROM sboms s`).
-		WithArgs("sb1", 7).
+		WithArgs(7, "sb1").
 		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
 
 	orig := getSBOMService
@@ -50,7 +50,7 @@ func TestGetSBOM_NotAccessible_404(t *testing.T) {
 	db.Conn = sqlDB
 
 	mock.ExpectQuery(`SELECT 1\s+FROM sboms s`).
-		WithArgs("sb1", 7).
+		WithArgs(7, "sb1").
 		WillReturnError(sql.ErrNoRows)
 
 	req := httptest.NewRequest("GET", "/api/sbom/sb1", nil)
@@ -71,7 +71,7 @@ func TestGetSBOM_ServiceError_404(t *testing.T) {
 	db.Conn = sqlDB
 
 	mock.ExpectQuery(`SELECT 1\s+FROM sboms s`).
-		WithArgs("sb1", 7).
+		WithArgs(7, "sb1").
 		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
 
 	orig := getSBOMService
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"strings"
+
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/gql"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+var graphqlSchema = gql.NewSchema()
+
+// RegisterGraphQLRoutes mounts the GraphQL endpoint.
+func RegisterGraphQLRoutes(r fiber.Router) {
+	r.Post("/", graphqlQuery)
+	r.Get("/", graphqlQuery)
+}
+
+// graphqlQuery godoc
+// @Summary GraphQL query
+// @Description Read-only GraphQL API over the organization's projects, SBOMs, components and vulnerabilities
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /graphql [post]
+func graphqlQuery(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+
+	var req gql.Request
+	if c.Method() == fiber.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if raw := c.Query("variables"); raw != "" {
+			if err := c.App().Config().JSONDecoder([]byte(raw), &req.Variables); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid variables"})
+			}
+		}
+	} else if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "query is required"})
+	}
+
+	ctx := gql.WithOrganization(c.UserContext(), db.Conn, orgID)
+	return c.JSON(gql.Execute(ctx, graphqlSchema, req))
+}
@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	fiber "github.com/gofiber/fiber/v2"
+
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// idempotent makes a POST handler safe to retry. With an Idempotency-Key
+// header the first response for the key is stored per organization and
+// scope and replayed for retries; reusing the key with a different request
+// is rejected with 422. Server errors and 429s are not stored, so a retry
+// after them runs the request again. Requests without the header, or
+// without an organization, go straight to the handler.
+func idempotent(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := strings.TrimSpace(c.Get(idempotencyKeyHeader))
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "Idempotency-Key must be at most 255 characters"})
+		}
+		orgID, err := requireOrgID(c)
+		if err != nil {
+			return c.Next()
+		}
+
+		fingerprint, err := requestFingerprint(c)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "cannot read request body"})
+		}
+
+		cached, err := services.BeginIdempotentRequest(c.Context(), db.Conn, orgID, scope, key, fingerprint)
+		switch {
+		case errors.Is(err, services.ErrIdempotencyKeyReused):
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, services.ErrIdempotencyInProgress):
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		case err != nil:
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "idempotency check failed"})
+		case cached != nil:
+			c.Set(idempotentReplayedHeader, "true")
+			if cached.ContentType != "" {
+				c.Set(fiber.HeaderContentType, cached.ContentType)
+			}
+			return c.Status(cached.Status).Send(cached.Body)
+		}
+
+		// Render handler errors now so the stored response matches what the
+		// client receives.
+		if err := c.Next(); err != nil {
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = services.ReleaseIdempotencyKey(c.Context(), db.Conn, orgID, scope, key)
+				return herr
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			if err := services.ReleaseIdempotencyKey(c.Context(), db.Conn, orgID, scope, key); err != nil {
+				log.Printf("[IDEMPOTENCY][ERR] release key org=%d scope=%s: %v", orgID, scope, err)
+			}
+			return nil
+		}
+		resp := services.IdempotentResponse{
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		}
+		if err := services.CompleteIdempotentRequest(c.Context(), db.Conn, orgID, scope, key, resp); err != nil {
+			log.Printf("[IDEMPOTENCY][ERR] store response org=%d scope=%s: %v", orgID, scope, err)
+		}
+		return nil
+	}
+}
+
+// requestFingerprint hashes what the request asks for. Multipart bodies are
+// hashed by field and file content rather than raw bytes, since clients pick
+// a new boundary on every retry.
+func requestFingerprint(c *fiber.Ctx) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, c.Method()+" "+c.Path()+"\n")
+
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		h.Write(c.Body())
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(form.Value))
+	for name := range form.Value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range form.Value[name] {
+			io.WriteString(h, "value "+name+"="+v+"\n")
+		}
+	}
+
+	names = names[:0]
+	for name := range form.File {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, fh := range form.File[name] {
+			io.WriteString(h, "file "+name+"="+fh.Filename+"\n")
+			f, err := fh.Open()
+			if err != nil {
+				return "", err
+			}
+			_, err = io.Copy(h, f)
+			f.Close()
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotentApp(handler fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Post("/things", idempotent("thing_create"), handler)
+	return app
+}
+
+func postThing(t *testing.T, app *fiber.App, body, key string) (int, string, string) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/things", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Organization-ID", "7")
+	req.Header.Set(idempotencyKeyHeader, key)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	out, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(out), resp.Header.Get(idempotentReplayedHeader)
+}
+
+func TestIdempotent_StoresFirstResponse(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	calls := 0
+	app := newIdempotentApp(func(c *fiber.Ctx) error {
+		calls++
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": 1})
+	})
+
+	mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+		WithArgs(7, "thing_create", "k1", sqlmock.AnyArg(), "in_progress", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"idempotency_key"}).AddRow("k1"))
+	mock.ExpectExec(`UPDATE idempotency_keys`).
+		WithArgs(7, "thing_create", "k1", "completed", 201, "application/json", []byte(`{"id":1}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	status, body, replayed := postThing(t, app, `{"name":"a"}`, "k1")
+	require.Equal(t, fiber.StatusCreated, status)
+	require.JSONEq(t, `{"id":1}`, body)
+	require.Empty(t, replayed)
+	require.Equal(t, 1, calls)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotent_ReplayAndMismatch(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	calls := 0
+	app := newIdempotentApp(func(c *fiber.Ctx) error {
+		calls++
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	// Learn the fingerprint of the original body from the first claim.
+	var fingerprint string
+	mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+		WithArgs(7, "thing_create", "k1", fingerprintArg{&fingerprint}, "in_progress", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"idempotency_key"}))
+	mock.ExpectQuery(`SELECT fingerprint, status`).
+		WithArgs(7, "thing_create", "k1").
+		WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status", "response_status", "content_type", "response_body"}).
+			AddRow("", "completed", 201, "application/json", []byte(`{"id":1}`)))
+	status, _, _ := postThing(t, app, `{"name":"a"}`, "k1")
+	require.Equal(t, fiber.StatusUnprocessableEntity, status)
+
+	mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+		WillReturnRows(sqlmock.NewRows([]string{"idempotency_key"}))
+	mock.ExpectQuery(`SELECT fingerprint, status`).
+		WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status", "response_status", "content_type", "response_body"}).
+			AddRow(fingerprint, "completed", 201, "application/json", []byte(`{"id":1}`)))
+	status, body, replayed := postThing(t, app, `{"name":"a"}`, "k1")
+	require.Equal(t, fiber.StatusCreated, status)
+	require.JSONEq(t, `{"id":1}`, body)
+	require.Equal(t, "true", replayed)
+
+	mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+		WillReturnRows(sqlmock.NewRows([]string{"idempotency_key"}))
+	mock.ExpectQuery(`SELECT fingerprint, status`).
+		WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status", "response_status", "content_type", "response_body"}).
+			AddRow(fingerprint, "in_progress", nil, nil, nil))
+	status, _, _ = postThing(t, app, `{"name":"a"}`, "k1")
+	require.Equal(t, fiber.StatusConflict, status)
+
+	require.Zero(t, calls)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotent_ReleasesKeyOnServerError(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	app := newIdempotentApp(func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusInternalServerError, "boom")
+	})
+
+	mock.ExpectQuery(`INSERT INTO idempotency_keys`).
+		WillReturnRows(sqlmock.NewRows([]string{"idempotency_key"}).AddRow("k1"))
+	mock.ExpectExec(`DELETE FROM idempotency_keys`).
+		WithArgs(7, "thing_create", "k1", "in_progress").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	status, body, _ := postThing(t, app, `{}`, "k1")
+	require.Equal(t, fiber.StatusInternalServerError, status)
+	require.Equal(t, "boom", body)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequestFingerprint_IgnoresMultipartBoundary(t *testing.T) {
+	var seen []string
+	app := fiber.New()
+	app.Post("/upload", func(c *fiber.Ctx) error {
+		fp, err := requestFingerprint(c)
+		require.NoError(t, err)
+		seen = append(seen, fp)
+		return nil
+	})
+
+	send := func(boundary, content string) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		require.NoError(t, w.SetBoundary(boundary))
+		require.NoError(t, w.WriteField("project_id", "3"))
+		part, err := w.CreateFormFile("file", "package.json")
+		require.NoError(t, err)
+		part.Write([]byte(content))
+		require.NoError(t, w.Close())
+		req := httptest.NewRequest("POST", "/upload", &buf)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		_, err = app.Test(req)
+		require.NoError(t, err)
+	}
+	send("boundary-one", `{"name":"a"}`)
+	send("boundary-two", `{"name":"a"}`)
+	send("boundary-two", `{"name":"b"}`)
+
+	require.Len(t, seen, 3)
+	require.Equal(t, seen[0], seen[1])
+	require.NotEqual(t, seen[1], seen[2])
+}
+
+// fingerprintArg matches any string and records it.
+type fingerprintArg struct{ out *string }
+
+func (a fingerprintArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	*a.out = s
+	return ok
+}
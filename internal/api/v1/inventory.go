@@ -0,0 +1,227 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+	"myesi-sbom-service-golang/internal/versioning"
+	"sort"
+	"strings"
+	"time"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// inventorySortColumns whitelists the sort keys accepted by componentInventory.
+var inventorySortColumns = map[string]string{
+	"name":       "i.name",
+	"ecosystem":  "i.ecosystem",
+	"projects":   "i.projects",
+	"versions":   "i.version_count",
+	"first_seen": "i.first_seen",
+	"last_seen":  "i.last_seen",
+}
+
+// inventoryCTE flattens every component of the organization's SBOMs and
+// aggregates them per (ecosystem, name), where name carries the component's
+// namespace so Maven groups and npm scopes stay apart. $1 is the organization id.
+func inventoryCTE() string {
+	return `
+		WITH comps AS (
+			SELECT s.project_id,
+			       s.created_at,
+			       COALESCE(s.updated_at, s.created_at) AS seen_at,
+			       COALESCE(NULLIF(split_part(split_part(c->>'purl', ':', 2), '/', 1), ''), 'unknown') AS ecosystem,
+			       ` + services.ComponentFullNameSQL + ` AS name,
+			       COALESCE(c->>'version', '') AS version,
+			       CASE WHEN jsonb_typeof(c->'licenses') = 'array' THEN c->'licenses' ELSE '[]'::jsonb END AS licenses
+			FROM sboms s
+			CROSS JOIN LATERAL jsonb_array_elements(COALESCE(s.sbom->'components', '[]'::jsonb)) c
+			WHERE ` + orgProjectFilterClause() + `
+			  AND c->>'name' IS NOT NULL
+		),
+		comp_licenses AS (
+			SELECT DISTINCT cp.ecosystem, cp.name,
+			       COALESCE(l->'license'->>'id', l->'license'->>'name', l->>'expression') AS license
+			FROM comps cp
+			CROSS JOIN LATERAL jsonb_array_elements(cp.licenses) l
+		),
+		versions AS (
+			SELECT ecosystem, name, version,
+			       COUNT(DISTINCT project_id) AS projects,
+			       MIN(created_at) AS first_seen,
+			       MAX(seen_at) AS last_seen
+			FROM comps
+			GROUP BY ecosystem, name, version
+		),
+		inventory AS (
+			SELECT ecosystem, name,
+			       COUNT(DISTINCT project_id) AS projects,
+			       COUNT(DISTINCT version) AS version_count,
+			       MIN(created_at) AS first_seen,
+			       MAX(seen_at) AS last_seen
+			FROM comps
+			GROUP BY ecosystem, name
+		)
+	`
+}
+
+// componentInventory godoc
+// @Summary Component inventory
+// @Description Paginated list of every distinct component used in the organization, with versions in use, project counts, licenses and first/last seen dates
+// @Tags SBOM
+// @Produce json
+// @Param ecosystem query string false "Ecosystem (purl type, e.g. npm, pypi, maven, golang)"
+// @Param license query string false "License id or name (case-insensitive)"
+// @Param q query string false "Search by component name"
+// @Param sort query string false "Sort field (name|ecosystem|projects|versions|first_seen|last_seen)"
+// @Param order query string false "asc|desc"
+// @Param page query int false "Page (default 1)"
+// @Param page_size query int false "Page size (max 200, default 50)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /components/inventory [get]
+func componentInventory(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	pageSize := c.QueryInt("page_size", 50)
+	if pageSize <= 0 || pageSize > 200 {
+		pageSize = 50
+	}
+	offset := (page - 1) * pageSize
+
+	sortKey := strings.ToLower(strings.TrimSpace(c.Query("sort", "projects")))
+	sortColumn, ok := inventorySortColumns[sortKey]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid sort field"})
+	}
+	order := "DESC"
+	if strings.EqualFold(c.Query("order"), "asc") || (c.Query("order") == "" && (sortKey == "name" || sortKey == "ecosystem")) {
+		order = "ASC"
+	}
+
+	var (
+		whereParts []string
+		args       = []interface{}{orgID}
+	)
+	nextParam := func() string {
+		return fmt.Sprintf("$%d", len(args)+1)
+	}
+	if eco := strings.ToLower(strings.TrimSpace(c.Query("ecosystem"))); eco != "" && eco != "all" {
+		whereParts = append(whereParts, "i.ecosystem = "+nextParam())
+		args = append(args, eco)
+	}
+	if license := strings.TrimSpace(c.Query("license")); license != "" {
+		whereParts = append(whereParts, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM comp_licenses cl
+			WHERE cl.ecosystem = i.ecosystem AND cl.name = i.name AND cl.license ILIKE %s
+		)`, nextParam()))
+		args = append(args, license)
+	}
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		whereParts = append(whereParts, "i.name ILIKE "+nextParam())
+		args = append(args, "%"+search+"%")
+	}
+	whereClause := "TRUE"
+	if len(whereParts) > 0 {
+		whereClause = strings.Join(whereParts, " AND ")
+	}
+
+	countQuery := inventoryCTE() + `SELECT COUNT(*) FROM inventory i WHERE ` + whereClause
+	var total int
+	if err := db.Conn.QueryRowContext(c.Context(), countQuery, args...).Scan(&total); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	argsWithPaging := append([]interface{}{}, args...)
+	argsWithPaging = append(argsWithPaging, pageSize, offset)
+	listQuery := inventoryCTE() + fmt.Sprintf(`
+		SELECT i.ecosystem, i.name, i.projects, i.version_count, i.first_seen, i.last_seen,
+		       COALESCE((
+		           SELECT jsonb_agg(jsonb_build_object(
+		                      'version', v.version,
+		                      'projects', v.projects,
+		                      'first_seen', v.first_seen,
+		                      'last_seen', v.last_seen))
+		           FROM versions v
+		           WHERE v.ecosystem = i.ecosystem AND v.name = i.name
+		       ), '[]'::jsonb) AS versions,
+		       COALESCE((
+		           SELECT jsonb_agg(cl.license ORDER BY cl.license)
+		           FROM comp_licenses cl
+		           WHERE cl.ecosystem = i.ecosystem AND cl.name = i.name AND cl.license IS NOT NULL
+		       ), '[]'::jsonb) AS licenses
+		FROM inventory i
+		WHERE %s
+		ORDER BY %s %s NULLS LAST, i.name ASC, i.ecosystem ASC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, sortColumn, order, len(args)+1, len(args)+2)
+
+	rows, err := db.Conn.QueryContext(c.Context(), listQuery, argsWithPaging...)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer rows.Close()
+
+	type VersionUsage struct {
+		Version   string `json:"version"`
+		Projects  int    `json:"projects"`
+		FirstSeen string `json:"first_seen,omitempty"`
+		LastSeen  string `json:"last_seen,omitempty"`
+	}
+	type InventoryItem struct {
+		Ecosystem    string         `json:"ecosystem"`
+		Name         string         `json:"name"`
+		Projects     int            `json:"projects"`
+		VersionCount int            `json:"version_count"`
+		Versions     []VersionUsage `json:"versions"`
+		Licenses     []string       `json:"licenses"`
+		FirstSeen    *time.Time     `json:"first_seen,omitempty"`
+		LastSeen     *time.Time     `json:"last_seen,omitempty"`
+	}
+
+	list := []InventoryItem{}
+	for rows.Next() {
+		var (
+			item                     InventoryItem
+			firstSeen, lastSeen      sql.NullTime
+			rawVersions, rawLicenses []byte
+		)
+		if err := rows.Scan(&item.Ecosystem, &item.Name, &item.Projects, &item.VersionCount,
+			&firstSeen, &lastSeen, &rawVersions, &rawLicenses); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		if firstSeen.Valid {
+			item.FirstSeen = &firstSeen.Time
+		}
+		if lastSeen.Valid {
+			item.LastSeen = &lastSeen.Time
+		}
+		_ = json.Unmarshal(rawVersions, &item.Versions)
+		_ = json.Unmarshal(rawLicenses, &item.Licenses)
+		if item.Licenses == nil {
+			item.Licenses = []string{}
+		}
+		eco := item.Ecosystem
+		sort.Slice(item.Versions, func(i, j int) bool {
+			return versioning.Compare(eco, item.Versions[i].Version, item.Versions[j].Version) > 0
+		})
+		list = append(list, item)
+	}
+
+	return c.JSON(fiber.Map{
+		"data":      list,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
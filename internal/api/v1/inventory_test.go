@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentInventory_FiltersAndPaging(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM inventory i WHERE`).
+		WithArgs(7, "npm", "MIT").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	seen := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT i.ecosystem, i.name, i.projects`).
+		WithArgs(7, "npm", "MIT", 20, 20).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"ecosystem", "name", "projects", "version_count", "first_seen", "last_seen", "versions", "licenses",
+		}).AddRow("npm", "lodash", 3, 2, seen, seen,
+			[]byte(`[{"version":"4.17.9","projects":1},{"version":"4.17.21","projects":2}]`),
+			[]byte(`["MIT"]`)))
+
+	req := httptest.NewRequest("GET", "/api/sbom/components/inventory?ecosystem=npm&license=MIT&page=2&page_size=20", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []struct {
+			Name     string `json:"name"`
+			Versions []struct {
+				Version string `json:"version"`
+			} `json:"versions"`
+		} `json:"data"`
+		Total int `json:"total"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Equal(t, 1, body.Total)
+	require.Equal(t, "4.17.21", body.Data[0].Versions[0].Version)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestComponentInventory_InvalidSort_400(t *testing.T) {
+	app := newTestApp()
+
+	req := httptest.NewRequest("GET", "/api/sbom/components/inventory?sort=drop_table", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,206 @@
+package v1
+
+import (
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// labelSelectorQuery parses the ?labels= selector shared by list endpoints.
+func labelSelectorQuery(c *fiber.Ctx) (services.LabelSelector, error) {
+	return services.ParseLabelSelector(c.Query("labels"))
+}
+
+// Get the labels of a project
+func project_getLabels(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	if _, err := ensureProjectIDAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	labels, err := services.GetProjectLabels(c.Context(), db.Conn, id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{"data": labels})
+}
+
+// Replace the labels of a project
+func project_setLabels(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	var body struct {
+		Labels services.Labels `json:"labels"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid body")
+	}
+	if err := services.ValidateLabels(body.Labels); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if _, err := ensureProjectIDAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	tx, err := db.Conn.BeginTx(c.Context(), nil)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+	if err := services.SetProjectLabels(c.Context(), tx, id, body.Labels); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if err := tx.Commit(); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if body.Labels == nil {
+		body.Labels = services.Labels{}
+	}
+	return c.JSON(fiber.Map{"data": body.Labels})
+}
+
+// Remove one label of a project
+func project_deleteLabel(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	if _, err := ensureProjectIDAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	removed, err := services.DeleteProjectLabel(c.Context(), db.Conn, id, c.Params("key"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if !removed {
+		return fiber.NewError(fiber.StatusNotFound, "label not found")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// getSBOMLabels godoc
+// @Summary Get SBOM labels
+// @Description Return the labels set on the SBOM and the effective labels including those inherited from its project
+// @Tags SBOM
+// @Produce json
+// @Param id path string true "SBOM ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /{id}/labels [get]
+func getSBOMLabels(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	id := c.Params("id")
+	if err := ensureSBOMAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	own, err := services.GetSBOMLabels(c.Context(), db.Conn, id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	var projectID int
+	if err := db.Conn.QueryRowContext(c.Context(),
+		`SELECT COALESCE(project_id, 0) FROM sboms WHERE id = $1`, id).Scan(&projectID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	effective, err := services.EventLabels(c.Context(), db.Conn, projectID, id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": own, "effective": effective})
+}
+
+// setSBOMLabels godoc
+// @Summary Replace SBOM labels
+// @Tags SBOM
+// @Accept json
+// @Produce json
+// @Param id path string true "SBOM ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /{id}/labels [put]
+func setSBOMLabels(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	id := c.Params("id")
+	var body struct {
+		Labels services.Labels `json:"labels"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	if err := services.ValidateLabels(body.Labels); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := ensureSBOMAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	tx, err := db.Conn.BeginTx(c.Context(), nil)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer tx.Rollback()
+	if err := services.SetSBOMLabels(c.Context(), tx, id, body.Labels); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if body.Labels == nil {
+		body.Labels = services.Labels{}
+	}
+	return c.JSON(fiber.Map{"data": body.Labels})
+}
+
+// deleteSBOMLabel godoc
+// @Summary Remove an SBOM label
+// @Tags SBOM
+// @Param id path string true "SBOM ID"
+// @Param key path string true "Label key"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /{id}/labels/{key} [delete]
+func deleteSBOMLabel(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	id := c.Params("id")
+	if err := ensureSBOMAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	removed, err := services.DeleteSBOMLabel(c.Context(), db.Conn, id, c.Params("key"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !removed {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "label not found"})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -3,10 +3,11 @@ package v1
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"net/http/httptest"
 	"testing"
 
-	"myesi-sbom-service-golang/models"
+	"myesi-sbom-service-golang/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/require"
@@ -18,12 +19,15 @@ func TestListSBOMs_Success(t *testing.T) {
 	orig := listSBOMService
 	t.Cleanup(func() { listSBOMService = orig })
 
-	listSBOMService = func(ctx context.Context, conn *sql.DB, project string, limit int, orgID int) ([]*models.Sbom, error) {
-		require.Equal(t, "proj1", project)
-		require.Equal(t, 5, limit)
+	listSBOMService = func(ctx context.Context, conn *sql.DB, orgID int, q services.SBOMListQuery) (*services.SBOMPage, error) {
+		require.Equal(t, "proj1", q.Project)
+		require.Equal(t, 5, q.Limit)
 		require.Equal(t, 7, orgID)
-		return []*models.Sbom{
-			{ID: "sb1", ProjectName: "proj1"},
+		require.False(t, q.IncludeSBOM)
+		return &services.SBOMPage{
+			Items:      []*services.SBOMListItem{{ID: "sb1", ProjectName: "proj1", ComponentCount: 12}},
+			NextCursor: "abc",
+			Total:      6,
 		}, nil
 	}
 
@@ -32,6 +36,71 @@ func TestListSBOMs_Success(t *testing.T) {
 	resp, err := app.Test(req)
 	require.NoError(t, err)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data       []map[string]interface{} `json:"data"`
+		NextCursor string                   `json:"next_cursor"`
+		PageSize   int                      `json:"page_size"`
+		Total      int                      `json:"total"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Data, 1)
+	require.EqualValues(t, 12, body.Data[0]["component_count"])
+	require.NotContains(t, body.Data[0], "sbom")
+	require.Equal(t, "abc", body.NextCursor)
+	require.Equal(t, 5, body.PageSize)
+	require.Equal(t, 6, body.Total)
+}
+
+func TestListSBOMs_FiltersAndSort(t *testing.T) {
+	app := newTestApp()
+
+	orig := listSBOMService
+	t.Cleanup(func() { listSBOMService = orig })
+
+	listSBOMService = func(ctx context.Context, conn *sql.DB, orgID int, q services.SBOMListQuery) (*services.SBOMPage, error) {
+		require.Equal(t, "manual", q.Source)
+		require.Equal(t, "package.json", q.Manifest)
+		require.Equal(t, "npm", q.Ecosystem)
+		require.Equal(t, "2025-01-01T00:00:00Z", q.From.Format("2006-01-02T15:04:05Z07:00"))
+		require.Equal(t, "2025-02-01T00:00:00Z", q.To.Format("2006-01-02T15:04:05Z07:00"))
+		require.Equal(t, 10, *q.MinComponents)
+		require.Nil(t, q.MaxComponents)
+		require.Equal(t, "components", q.Sort)
+		require.Equal(t, "asc", q.Order)
+		require.Equal(t, "cur", q.Cursor)
+		require.True(t, q.IncludeSBOM)
+		return &services.SBOMPage{}, nil
+	}
+
+	req := httptest.NewRequest("GET", "/api/sbom/list?source=manual&manifest=package.json&ecosystem=npm&from=2025-01-01&to=2025-01-31&min_components=10&sort=components&order=asc&cursor=cur&include=sbom", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestListSBOMs_BadInput_400(t *testing.T) {
+	app := newTestApp()
+
+	orig := listSBOMService
+	t.Cleanup(func() { listSBOMService = orig })
+	listSBOMService = func(ctx context.Context, conn *sql.DB, orgID int, q services.SBOMListQuery) (*services.SBOMPage, error) {
+		return nil, services.ErrInvalidCursor
+	}
+
+	for _, url := range []string{
+		"/api/sbom/list?sort=size",
+		"/api/sbom/list?from=yesterday",
+		"/api/sbom/list?min_components=-1",
+		"/api/sbom/list?cursor=stale",
+	} {
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("X-Organization-ID", "7")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode, url)
+	}
 }
 
 func TestListSBOMs_ServiceError_500(t *testing.T) {
@@ -40,7 +109,7 @@ func TestListSBOMs_ServiceError_500(t *testing.T) {
 	orig := listSBOMService
 	t.Cleanup(func() { listSBOMService = orig })
 
-	listSBOMService = func(ctx context.Context, conn *sql.DB, project string, limit int, orgID int) ([]*models.Sbom, error) {
+	listSBOMService = func(ctx context.Context, conn *sql.DB, orgID int, q services.SBOMListQuery) (*services.SBOMPage, error) {
 		return nil, assertErr("boom")
 	}
 
@@ -3,7 +3,9 @@ package v1
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"myesi-sbom-service-golang/internal/db"
 	"myesi-sbom-service-golang/internal/services"
 	"myesi-sbom-service-golang/models"
@@ -15,8 +17,6 @@ import (
 	"github.com/aarondl/null/v8"
 	"github.com/aarondl/sqlboiler/v4/boil"
 	"github.com/aarondl/sqlboiler/v4/queries/qm"
-	"github.com/aarondl/sqlboiler/v4/types"
-	"github.com/ericlagergren/decimal"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -38,12 +38,21 @@ func nullableInt(ni null.Int) *int {
 // RegisterProjectRoutes mounts CRUD routes for projects table.
 func RegisterProjectRoutes(r fiber.Router) {
 	r.Get("/", project_getAll)
-	r.Post("/", project_create)
+	r.Post("/", idempotent("project_create"), project_create)
 	r.Post("/import/github", importGithubProjects)
 	r.Get("/top-languages", project_topLanguages)
 	r.Put("/:id", project_update)
+	r.Get("/:id/risk", project_risk)
+	r.Post("/:id/risk/recompute", project_recomputeRisk)
 	r.Post("/:id/archive", project_archive)
 	r.Delete("/:id", project_delete)
+	r.Get("/:id/deletion", project_deletionStatus)
+	r.Post("/:id/transfer", project_transfer)
+	r.Get("/:id/labels", project_getLabels)
+	r.Put("/:id/labels", project_setLabels)
+	r.Delete("/:id/labels/:key", project_deleteLabel)
+	r.Get("/:id/components/export", project_exportComponents)
+	r.Get("/:id/report", project_report)
 	r.Get("/:id", project_getOne)
 }
 
@@ -69,6 +78,10 @@ func project_getAll(c *fiber.Ctx) error {
 	language := strings.TrimSpace(c.Query("language"))
 	findings := strings.ToLower(strings.TrimSpace(c.Query("findings")))
 	status := strings.ToLower(strings.TrimSpace(c.Query("status", "active")))
+	selector, err := labelSelectorQuery(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
 
 	baseMods := []qm.QueryMod{
 		qm.Where("organization_id = ?", orgID),
@@ -98,6 +111,14 @@ func project_getAll(c *fiber.Ctx) error {
 	} else if findings == "without" {
 		baseMods = append(baseMods, qm.Where("COALESCE(total_vulnerabilities,0) = 0"))
 	}
+	if len(selector) > 0 {
+		var labelArgs []interface{}
+		clause := selector.SQL(services.ProjectLabelValue("projects.id"), func(v interface{}) string {
+			labelArgs = append(labelArgs, v)
+			return "?"
+		})
+		baseMods = append(baseMods, qm.Where(clause, labelArgs...))
+	}
 
 	total, err := models.Projects(baseMods...).Count(c.Context(), db.Conn)
 	if err != nil {
@@ -116,24 +137,34 @@ func project_getAll(c *fiber.Ctx) error {
 	}
 
 	type ProjectDTO struct {
-		ID                   int64      `json:"id"`
-		Name                 string     `json:"name"`
-		Description          *string    `json:"description,omitempty"`
-		SourceType           *string    `json:"source_type,omitempty"`
-		RepoURL              *string    `json:"repo_url,omitempty"`
-		GithubFullName       *string    `json:"github_full_name,omitempty"`
-		GithubDefaultBranch  *string    `json:"github_default_branch,omitempty"`
-		GithubVisibility     *string    `json:"github_visibility,omitempty"`
-		GithubLastSync       *time.Time `json:"github_last_sync,omitempty"`
-		LastSbomUpload       *time.Time `json:"last_sbom_upload,omitempty"`
-		LastVulnScan         *time.Time `json:"last_vuln_scan,omitempty"`
-		AvgRiskScore         *float64   `json:"avg_risk_score,omitempty"`
-		TotalVulnerabilities *int       `json:"total_vulnerabilities,omitempty"`
-		OrganizationID       *int       `json:"organization_id,omitempty"`
-		Languages            []string   `json:"languages,omitempty"`
-		PrimaryLanguage      *string    `json:"primary_language,omitempty"`
-		CreatedAt            *time.Time `json:"created_at,omitempty"`
-		IsArchived           bool       `json:"is_archived"`
+		ID                   int64           `json:"id"`
+		Name                 string          `json:"name"`
+		Description          *string         `json:"description,omitempty"`
+		SourceType           *string         `json:"source_type,omitempty"`
+		RepoURL              *string         `json:"repo_url,omitempty"`
+		GithubFullName       *string         `json:"github_full_name,omitempty"`
+		GithubDefaultBranch  *string         `json:"github_default_branch,omitempty"`
+		GithubVisibility     *string         `json:"github_visibility,omitempty"`
+		GithubLastSync       *time.Time      `json:"github_last_sync,omitempty"`
+		LastSbomUpload       *time.Time      `json:"last_sbom_upload,omitempty"`
+		LastVulnScan         *time.Time      `json:"last_vuln_scan,omitempty"`
+		AvgRiskScore         *float64        `json:"avg_risk_score,omitempty"`
+		TotalVulnerabilities *int            `json:"total_vulnerabilities,omitempty"`
+		OrganizationID       *int            `json:"organization_id,omitempty"`
+		Languages            []string        `json:"languages,omitempty"`
+		PrimaryLanguage      *string         `json:"primary_language,omitempty"`
+		CreatedAt            *time.Time      `json:"created_at,omitempty"`
+		IsArchived           bool            `json:"is_archived"`
+		Labels               services.Labels `json:"labels"`
+	}
+
+	ids := make([]int, 0, len(list))
+	for _, p := range list {
+		ids = append(ids, p.ID)
+	}
+	labelsByProject, err := services.LabelsForProjects(c.Context(), db.Conn, ids)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
 	resp := make([]ProjectDTO, 0, len(list))
@@ -180,6 +211,10 @@ func project_getAll(c *fiber.Ctx) error {
 			dto.CreatedAt = &p.CreatedAt.Time
 		}
 		dto.IsArchived = p.IsArchived.Valid && p.IsArchived.Bool
+		dto.Labels = labelsByProject[p.ID]
+		if dto.Labels == nil {
+			dto.Labels = services.Labels{}
+		}
 
 		resp = append(resp, dto)
 	}
@@ -370,31 +405,126 @@ func project_update(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid payload")
 	}
-
-	if payload.Name != nil {
-		existing.Name = *payload.Name
+	// Older clients still send the computed fields; they are ignored.
+	if payload.AvgRisk != nil || payload.TotalVuln != nil {
+		log.Printf("[PROJECT][WARN] project=%d update ignored deprecated avg_risk_score/total_vulnerabilities", id)
+		c.Set(fiber.HeaderWarning, `299 - "avg_risk_score and total_vulnerabilities are computed by the server and were ignored"`)
 	}
+
 	if payload.Description != nil {
 		existing.Description = null.StringFromPtr(payload.Description)
 	}
 	if payload.RepoURL != nil {
 		existing.RepoURL = null.StringFromPtr(payload.RepoURL)
 	}
-	if payload.AvgRisk != nil {
-		d := new(decimal.Big).SetFloat64(*payload.AvgRisk)
-		existing.AvgRiskScore = types.NewNullDecimal(d)
+
+	tx, err := db.Conn.BeginTx(c.Context(), nil)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
+	defer tx.Rollback()
 
-	if payload.TotalVuln != nil {
-		existing.TotalVulnerabilities = null.IntFrom(*payload.TotalVuln)
+	// A rename goes through the service so SBOMs and vulnerabilities follow.
+	if payload.Name != nil && strings.TrimSpace(*payload.Name) != existing.Name {
+		newName := strings.TrimSpace(*payload.Name)
+		if newName == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "name cannot be empty")
+		}
+		if _, err := services.RenameProject(c.Context(), tx, existing.ID, orgID, existing.Name, newName); err != nil {
+			if errors.Is(err, services.ErrProjectNameTaken) {
+				return fiber.NewError(fiber.StatusConflict, err.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		existing.Name = newName
 	}
 
-	if _, err := existing.Update(c.Context(), db.Conn, boil.Infer()); err != nil {
+	if _, err := existing.Update(c.Context(), tx, boil.Infer()); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if err := tx.Commit(); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 	return c.JSON(existing)
 }
 
+// Risk score breakdown of a project, computed from its current findings
+func project_risk(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid project id")
+	}
+
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+
+	project, err := models.Projects(
+		qm.Where("id = ? AND organization_id = ?", id, orgID),
+	).One(c.Context(), db.Conn)
+	if err == sql.ErrNoRows {
+		return fiber.NewError(fiber.StatusNotFound, "project not found")
+	} else if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	breakdown, err := services.ComputeProjectRisk(c.Context(), db.Conn, id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	var stored *float64
+	if project.AvgRiskScore.Big != nil {
+		f, _ := project.AvgRiskScore.Big.Float64()
+		stored = &f
+	}
+	var evaluated sql.NullTime
+	if err := db.Conn.QueryRowContext(c.Context(),
+		`SELECT risk_evaluated_at FROM projects WHERE id = $1`, id).Scan(&evaluated); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	var evaluatedAt *time.Time
+	if evaluated.Valid {
+		evaluatedAt = &evaluated.Time
+	}
+
+	return c.JSON(fiber.Map{
+		"data":              breakdown,
+		"stored_score":      stored,
+		"last_evaluated_at": evaluatedAt,
+	})
+}
+
+// Recompute and persist a project's risk score
+func project_recomputeRisk(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid project id")
+	}
+
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+
+	exists, err := models.Projects(
+		qm.Where("id = ? AND organization_id = ?", id, orgID),
+	).Exists(c.Context(), db.Conn)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if !exists {
+		return fiber.NewError(fiber.StatusNotFound, "project not found")
+	}
+
+	breakdown, err := services.RecomputeProjectRisk(c.Context(), db.Conn, id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{"data": breakdown})
+}
+
 // Delete a project
 func project_delete(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
@@ -416,10 +546,115 @@ func project_delete(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	if _, err := p.Delete(c.Context(), db.Conn); err != nil {
+	// Deleting cascades to SBOMs and stored objects, so the caller must echo
+	// the project name back; without it we only report the impact.
+	if c.Query("confirm") != p.Name {
+		impact, err := services.PreviewProjectDeletion(c.Context(), db.Conn, p.ID, p.Name)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error":  "confirm deletion by passing ?confirm=<project name>",
+			"impact": impact,
+		})
+	}
+
+	if _, err := services.StartProjectDeletion(c.Context(), db.Conn, p.ID, orgID, p.Name); err != nil {
+		if errors.Is(err, services.ErrProjectOnLegalHold) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
-	return c.JSON(fiber.Map{"message": "project deleted"})
+	deletion, err := services.RunProjectDeletion(c.Context(), db.Conn, p.ID, orgID)
+	if err != nil {
+		// The deletion worker resumes from where this attempt stopped.
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": "project deletion incomplete, it will resume in the background",
+			"data":    deletion,
+		})
+	}
+	return c.JSON(fiber.Map{"message": "project deleted", "data": deletion})
+}
+
+// project_deletionStatus reports the progress of a project deletion.
+func project_deletionStatus(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+
+	deletion, err := services.LoadProjectDeletion(c.Context(), db.Conn, id, orgID)
+	if errors.Is(err, services.ErrProjectDeletionNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "no deletion requested for this project")
+	} else if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{"data": deletion})
+}
+
+// Move a project with its SBOMs and stored objects to another organization
+func project_transfer(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var payload struct {
+		TargetOrganizationID int    `json:"target_organization_id"`
+		NewName              string `json:"new_name"`
+		OnConflict           string `json:"on_conflict"`
+	}
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid payload")
+	}
+	if payload.TargetOrganizationID <= 0 || payload.TargetOrganizationID == orgID {
+		return fiber.NewError(fiber.StatusBadRequest, "target_organization_id must be another organization")
+	}
+	switch payload.OnConflict {
+	case "":
+		payload.OnConflict = services.TransferConflictFail
+	case services.TransferConflictFail, services.TransferConflictRename:
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "on_conflict must be fail or rename")
+	}
+
+	transfer, err := services.TransferProject(c.Context(), db.Conn, services.ProjectTransferRequest{
+		ProjectID:   id,
+		SourceOrgID: orgID,
+		TargetOrgID: payload.TargetOrganizationID,
+		UserID:      userID,
+		NewName:     payload.NewName,
+		OnConflict:  payload.OnConflict,
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return fiber.NewError(fiber.StatusNotFound, "project not found")
+	case errors.Is(err, services.ErrNotOrgMember), errors.Is(err, services.ErrNotOrgAdmin):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrProjectNameTaken), errors.Is(err, services.ErrProjectOnLegalHold),
+		errors.Is(err, services.ErrProjectArchived), errors.Is(err, services.ErrProjectDeletionPending):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrSBOMQuotaExceeded):
+		return fiber.NewError(fiber.StatusTooManyRequests, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{"message": "project transferred", "data": transfer})
 }
 
 type RepoInput struct {
@@ -583,6 +818,16 @@ func project_archive(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid payload")
 	}
 
+	if !payload.Archived {
+		deleting, err := services.IsProjectDeletionActive(c.Context(), db.Conn, id)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		if deleting {
+			return fiber.NewError(fiber.StatusConflict, "project is being deleted")
+		}
+	}
+
 	updates := models.M{
 		"is_archived": payload.Archived,
 		"updated_at":  time.Now(),
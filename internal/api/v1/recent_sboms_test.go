@@ -116,3 +116,40 @@ func TestRecentSBOMs_DBCountError_500(t *testing.T) {
 type assertErr string
 
 func (e assertErr) Error() string { return string(e) }
+
+func TestRecentSBOMs_LabelSelector(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	// Selector args follow the regular filters: key, value per requirement.
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM sboms s WHERE .*sbom_labels sl WHERE sl.sbom_id = s.id AND sl.key = \$2.* = \$3 AND .* IS DISTINCT FROM \$5`).
+		WithArgs(7, "team", "payments", "tier", "internal").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT id, project_name, manifest_name, object_url, created_at, source,`).
+		WithArgs(7, "team", "payments", "tier", "internal", 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "project_name", "manifest_name", "object_url", "created_at", "source", "findings",
+		}))
+
+	req := httptest.NewRequest("GET", "/api/sbom/recent?labels=team%3Dpayments,tier!%3Dinternal", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecentSBOMs_InvalidLabelSelector_400(t *testing.T) {
+	app := newTestApp()
+
+	req := httptest.NewRequest("GET", "/api/sbom/recent?labels=team%3D%3D%3D", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
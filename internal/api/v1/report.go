@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// sbomReport godoc
+// @Summary Download SBOM HTML report
+// @Description Single-file HTML report of one SBOM: project metadata, SBOM summary, components, license breakdown and vulnerabilities
+// @Tags SBOM
+// @Produce html
+// @Param id path string true "SBOM ID"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{}
+// @Router /{id}/report [get]
+func sbomReport(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	id := c.Params("id")
+	if err := ensureSBOMAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	report, err := services.BuildReport(c.Context(), db.Conn, services.ReportQuery{OrganizationID: orgID, SbomID: id})
+	if errors.Is(err, services.ErrReportNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "SBOM not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	name := report.Project.Name
+	if len(report.SBOMs) == 1 && report.SBOMs[0].Manifest != "" {
+		name += "-" + report.SBOMs[0].Manifest
+	}
+	return sendReport(c, report, name)
+}
+
+// Download the HTML report of a project
+func project_report(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+	}
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	if _, err := ensureProjectIDAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	report, err := services.BuildReport(c.Context(), db.Conn, services.ReportQuery{OrganizationID: orgID, ProjectID: id})
+	if errors.Is(err, services.ErrReportNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "Project not found")
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return sendReport(c, report, report.Project.Name)
+}
+
+// sendReport renders into memory first so a template error still returns 500
+// rather than a truncated document.
+func sendReport(c *fiber.Ctx, report *services.Report, name string) error {
+	var buf bytes.Buffer
+	if err := services.RenderReport(&buf, report); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	c.Set(fiber.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="%s"`, services.ReportFilename(name, report.GeneratedAt)))
+	return c.Send(buf.Bytes())
+}
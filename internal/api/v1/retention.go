@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"strconv"
+	"strings"
+
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// getRetention godoc
+// @Summary SBOM retention policy
+// @Description Effective retention policy (organization or plan) and the organization's legal holds
+// @Tags SBOM
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /retention [get]
+func getRetention(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	policy, err := services.LoadRetentionPolicy(c.Context(), db.Conn, orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	holds, err := services.ListLegalHolds(c.Context(), db.Conn, orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": policy, "legal_holds": holds})
+}
+
+// updateRetention godoc
+// @Summary Set organization retention policy
+// @Description Replaces the organization's policy. A null rule is not enforced; legal_hold suspends all purging.
+// @Tags SBOM
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /retention [put]
+func updateRetention(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	var payload services.RetentionPolicy
+	if err := c.BodyParser(&payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	if payload.KeepRevisions != nil && *payload.KeepRevisions < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "keep_revisions must be at least 1"})
+	}
+	if payload.MaxAgeDays != nil && *payload.MaxAgeDays < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "max_age_days must be at least 1"})
+	}
+	if err := services.SaveOrgRetentionPolicy(c.Context(), db.Conn, orgID, payload); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	payload.Source = "organization"
+	return c.JSON(fiber.Map{"data": payload})
+}
+
+// addLegalHold godoc
+// @Summary Place a project on legal hold
+// @Tags SBOM
+// @Accept json
+// @Produce json
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /retention/holds [post]
+func addLegalHold(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	var payload struct {
+		ProjectName string `json:"project_name"`
+		Reason      string `json:"reason"`
+	}
+	if err := c.BodyParser(&payload); err != nil || strings.TrimSpace(payload.ProjectName) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "project_name is required"})
+	}
+	projectID, err := ensureProjectAccessible(c.Context(), strings.TrimSpace(payload.ProjectName), orgID)
+	if err != nil {
+		return err
+	}
+	id, err := services.AddLegalHold(c.Context(), db.Conn, orgID, projectID, strings.TrimSpace(payload.Reason))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": fiber.Map{
+		"id":           id,
+		"project_id":   projectID,
+		"project_name": payload.ProjectName,
+		"reason":       payload.Reason,
+	}})
+}
+
+// removeLegalHold godoc
+// @Summary Release a legal hold
+// @Tags SBOM
+// @Param id path int true "Hold ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /retention/holds/{id} [delete]
+func removeLegalHold(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	holdID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || holdID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid hold id"})
+	}
+	ok, err := services.RemoveLegalHold(c.Context(), db.Conn, orgID, holdID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "legal hold not found"})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// runRetention godoc
+// @Summary Enforce retention now
+// @Description Applies the organization's policy immediately and reports what was purged. dry_run=true only reports.
+// @Tags SBOM
+// @Produce json
+// @Param dry_run query bool false "Report without deleting"
+// @Success 200 {object} map[string]interface{}
+// @Router /retention/run [post]
+func runRetention(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	report, err := services.EnforceRetention(c.Context(), db.Conn, orgID, c.QueryBool("dry_run", false))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error(), "data": report})
+	}
+	return c.JSON(fiber.Map{"data": report})
+}
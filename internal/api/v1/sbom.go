@@ -2,28 +2,57 @@ package v1
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"myesi-sbom-service-golang/internal/db"
 	"myesi-sbom-service-golang/internal/services"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	null "github.com/aarondl/null/v8"
 	fiber "github.com/gofiber/fiber/v2"
 )
 
 var (
-	listSBOMService = services.ListSBOM
-	getSBOMService  = services.GetSBOM
+	listSBOMService       = services.ListSBOM
+	getSBOMService        = services.GetSBOM
+	findComponentsService = services.FindComponents
+	deleteSBOMObject      = services.DeleteSBOMObject
 )
 
 func RegisterSBOMRoutes(r fiber.Router) {
-	r.Post("/upload", uploadSBOM)
+	r.Post("/upload", idempotent("sbom_upload"), uploadSBOM)
 	r.Get("/list", listSBOMs)
 	r.Get("/recent", recentSBOMs)
 	r.Get("/analytics", sbomAnalytics)
+	r.Get("/dashboard", sbomDashboard)
+	r.Get("/components", listComponents)
+	r.Get("/components/search", searchComponents)
+	r.Get("/components/inventory", componentInventory)
+	r.Get("/components/export", exportOrgComponents)
+	r.Get("/internal-packages", listInternalPackages)
+	r.Put("/internal-packages", replaceInternalPackages)
+	r.Get("/settings", getOrgSettings)
+	r.Put("/settings", updateOrgSettings)
+	r.Get("/retention", getRetention)
+	r.Put("/retention", updateRetention)
+	r.Post("/retention/holds", addLegalHold)
+	r.Delete("/retention/holds/:id", removeLegalHold)
+	r.Post("/retention/run", runRetention)
+	r.Get("/:id/findings", sbomFindings)
+	r.Get("/:id/download", downloadSBOM)
+	r.Get("/:id/url", presignSBOM)
+	r.Get("/:id/components/export", exportSBOMComponents)
+	r.Get("/:id/report", sbomReport)
+	r.Get("/:id/labels", getSBOMLabels)
+	r.Put("/:id/labels", setSBOMLabels)
+	r.Delete("/:id/labels/:key", deleteSBOMLabel)
 	r.Get("/:id", getSBOM)
+	r.Delete("/:id", deleteSBOM)
 }
 
 // uploadSBOM godoc
@@ -32,19 +61,13 @@ func RegisterSBOMRoutes(r fiber.Router) {
 // @Tags SBOM
 // @Accept multipart/form-data
 // @Produce json
-// @Param project_name formData string true "Project Name"
+// @Param project_name formData string false "Project Name"
+// @Param project_id formData int false "Project ID, takes precedence over project_name"
 // @Param file formData file true "Manifest file"
+// @Param Idempotency-Key header string false "Replays the first response for retries with the same key"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Router /upload [post]
-// uploadSBOM godoc
-// @Summary Upload a manifest file to generate SBOM
-// @Description Upload a manifest file and generate an SBOM
-// @Tags SBOM
-// @Accept multipart/form-data
-// @Produce json
-// @Param project_name formData string true "Project Name"
-// @Param file formData file true "Manifest file"
 func uploadSBOM(c *fiber.Ctx) error {
 	projectName := c.FormValue("project_name")
 	file, err := c.FormFile("file")
@@ -60,7 +83,18 @@ func uploadSBOM(c *fiber.Ctx) error {
 	// ---------------------------------------------------------
 	// 1. Lấy project_id
 	// ---------------------------------------------------------
-	projectID, err := ensureProjectAccessible(c.Context(), projectName, orgID)
+	var projectID int
+	if rawID := strings.TrimSpace(c.FormValue("project_id")); rawID != "" {
+		// Resolving by id keeps clients working across project renames.
+		id, convErr := strconv.Atoi(rawID)
+		if convErr != nil || id <= 0 {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid project_id"})
+		}
+		projectName, err = ensureProjectIDAccessible(c.Context(), id, orgID)
+		projectID = id
+	} else {
+		projectID, err = ensureProjectAccessible(c.Context(), projectName, orgID)
+	}
 	if err != nil {
 		return err
 	}
@@ -121,6 +155,28 @@ func uploadSBOM(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	components := services.ExtractComponents(sbomResult.Data)
+
+	// ---------------------------------------------------------
+	// 6b. Known-malicious packages
+	// ---------------------------------------------------------
+	malicious := services.CheckMaliciousPackages(components)
+	if len(malicious) > 0 {
+		settings, err := services.LoadOrgSettings(c.Context(), db.Conn, orgID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "failed to load organization settings"})
+		}
+		if settings.BlockMaliciousPackages {
+			if err := services.RecordMaliciousMatches(c.Context(), db.Conn, "", orgID, projectID, projectName, manifestName, malicious, true); err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "failed to record malicious packages"})
+			}
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error":              "upload blocked: manifest contains known-malicious packages",
+				"malicious_packages": malicious,
+			})
+		}
+	}
+
 	// ---------------------------------------------------------
 	// 7. Upload JSON to S3 (optional)
 	// ---------------------------------------------------------
@@ -136,8 +192,6 @@ func uploadSBOM(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	components := services.ExtractComponents(sbomResult.Data)
-
 	tx, err := db.Conn.BeginTx(c.Context(), nil)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "failed to start transaction"})
@@ -156,6 +210,24 @@ func uploadSBOM(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	vulnCount, err := services.MatchAndStoreVulnerabilities(c.Context(), tx, id, projectName, components)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "vulnerability matching failed: " + err.Error()})
+	}
+
+	if err := services.RecordMaliciousMatches(c.Context(), tx, id, orgID, projectID, projectName, manifestName, malicious, false); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to record malicious packages"})
+	}
+
+	supplyFindings, err := services.CheckSupplyChain(c.Context(), tx, id, projectName, projectID, orgID, components)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "supply-chain check failed: " + err.Error()})
+	}
+
+	if _, err := services.RecomputeProjectRisk(c.Context(), tx, projectID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "risk score update failed: " + err.Error()})
+	}
+
 	if err := services.QueueSBOMEvent(
 		c.Context(),
 		tx,
@@ -175,7 +247,7 @@ func uploadSBOM(c *fiber.Ctx) error {
 		orgID,
 		projectName,
 		len(components),
-		0,
+		vulnCount,
 		"completed",
 	); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "failed to queue notification"})
@@ -191,20 +263,36 @@ func uploadSBOM(c *fiber.Ctx) error {
 		"id":           id,
 		"project_id":   projectID,
 		"project_name": projectName,
-		"object_url":   url,
+		"has_object":   url != "",
+		"components":   len(components),
+		"vulns":        vulnCount,
+		"findings":     len(supplyFindings),
+		"malicious":    len(malicious),
 		"message":      "SBOM uploaded and queued for vulnerability scan",
 	})
 }
 
 // listSBOMs godoc
 // @Summary List SBOMs
-// @Description Get list of SBOMs filtered by project name
+// @Description List the organization's SBOMs with filters, sorting and cursor pagination
 // @Tags SBOM
 // @Accept json
 // @Produce json
 // @Param project_name query string false "Project Name"
-// @Param limit query int false "Limit"
-// @Success 200 {array} map[string]interface{}
+// @Param source query string false "Source (manual, auto-code-scan, ...)"
+// @Param manifest query string false "Manifest file name"
+// @Param ecosystem query string false "Only SBOMs containing components of this ecosystem (purl type)"
+// @Param from query string false "Created at or after (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Created before (RFC3339, or YYYY-MM-DD inclusive)"
+// @Param min_components query int false "Minimum component count"
+// @Param max_components query int false "Maximum component count"
+// @Param sort query string false "created_at|updated_at|project_name|manifest_name|components"
+// @Param order query string false "asc|desc (default desc)"
+// @Param limit query int false "Page size (max 200, default 50)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param include query string false "Set to sbom to include the full SBOM document of each item"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /list [get]
 func listSBOMs(c *fiber.Ctx) error {
@@ -212,13 +300,83 @@ func listSBOMs(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
-	project := c.Query("project_name")
-	limit := c.QueryInt("limit", 50)
-	sboms, err := listSBOMService(c.Context(), db.Conn, project, limit, orgID)
+
+	q := services.SBOMListQuery{
+		Project:   c.Query("project_name"),
+		Source:    strings.TrimSpace(c.Query("source")),
+		Manifest:  strings.TrimSpace(c.Query("manifest")),
+		Ecosystem: strings.TrimSpace(c.Query("ecosystem")),
+		Sort:      strings.ToLower(strings.TrimSpace(c.Query("sort", "created_at"))),
+		Order:     c.Query("order", "desc"),
+		Limit:     c.QueryInt("limit", 50),
+		Cursor:    strings.TrimSpace(c.Query("cursor")),
+	}
+	for _, inc := range strings.Split(c.Query("include"), ",") {
+		if strings.EqualFold(strings.TrimSpace(inc), "sbom") {
+			q.IncludeSBOM = true
+		}
+	}
+	if !services.ValidSBOMSort(q.Sort) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid sort field"})
+	}
+	if q.From, err = parseDateParam(c.Query("from"), false); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid from date"})
+	}
+	if q.To, err = parseDateParam(c.Query("to"), true); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid to date"})
+	}
+	for key, target := range map[string]**int{"min_components": &q.MinComponents, "max_components": &q.MaxComponents} {
+		if raw := c.Query(key); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid " + key})
+			}
+			*target = &n
+		}
+	}
+
+	page, err := listSBOMService(c.Context(), db.Conn, orgID, q)
+	if errors.Is(err, services.ErrInvalidCursor) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.JSON(sboms)
+
+	var nextCursor interface{}
+	if page.NextCursor != "" {
+		nextCursor = page.NextCursor
+	}
+	limit := q.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	return c.JSON(fiber.Map{
+		"data":        page.Items,
+		"next_cursor": nextCursor,
+		"page_size":   limit,
+		"total":       page.Total,
+	})
+}
+
+// parseDateParam accepts RFC3339 timestamps or YYYY-MM-DD dates. An end date
+// given as a plain day covers that whole day.
+func parseDateParam(raw string, endOfRange bool) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, err
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
 }
 
 // getSBOM godoc
@@ -244,9 +402,63 @@ func getSBOM(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "not found ád"})
 	}
+	// The object reference is internal; clients use /:id/url or /:id/download.
+	sbom.ObjectURL = null.String{}
 	return c.JSON(sbom)
 }
 
+// deleteSBOM godoc
+// @Summary Delete SBOM
+// @Description Delete an SBOM with its vulnerabilities, scan jobs and stored object
+// @Tags SBOM
+// @Produce json
+// @Param id path string true "SBOM ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /{id} [delete]
+func deleteSBOM(c *fiber.Ctx) error {
+	id := c.Params("id")
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	if err := ensureSBOMAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	tx, err := db.Conn.BeginTx(c.Context(), nil)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to start transaction"})
+	}
+	defer tx.Rollback()
+
+	deleted, err := services.DeleteSBOM(c.Context(), tx, id, orgID)
+	if err != nil {
+		if errors.Is(err, services.ErrSBOMNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "SBOM not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := tx.Commit(); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to commit transaction"})
+	}
+
+	// The row is gone either way; a leftover object is only logged.
+	objectDeleted := true
+	if deleted.ObjectURL != "" {
+		if err := deleteSBOMObject(c.Context(), deleted.ObjectURL); err != nil {
+			log.Printf("[SBOM][ERR] sbom=%s object cleanup failed: %v", id, err)
+			objectDeleted = false
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"data":           deleted,
+		"object_deleted": objectDeleted,
+		"message":        "SBOM deleted",
+	})
+}
+
 type GitHubSBOMRequest struct {
 	Owner   string `json:"owner"`
 	Repo    string `json:"repo"`
@@ -264,6 +476,7 @@ type GitHubSBOMRequest struct {
 // @Param project_name query string false "Project Name"
 // @Param source query string false "Source (manual|auto-code-scan)"
 // @Param q query string false "Search (project or manifest)"
+// @Param labels query string false "Label selector (e.g. team=payments,tier!=internal)"
 // @Param page query int false "Page (default 1)"
 // @Param page_size query int false "Page size (max 100, default 10)"
 // @Success 200 {object} map[string]interface{}
@@ -277,6 +490,10 @@ func recentSBOMs(c *fiber.Ctx) error {
 	project := c.Query("project_name")
 	source := strings.ToLower(strings.TrimSpace(c.Query("source")))
 	search := strings.TrimSpace(c.Query("q"))
+	selector, err := labelSelectorQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	page := c.QueryInt("page", 1)
 	if page < 1 {
 		page = 1
@@ -312,6 +529,13 @@ func recentSBOMs(c *fiber.Ctx) error {
 		whereParts = append(whereParts, fmt.Sprintf("(s.manifest_name ILIKE %s OR s.project_name ILIKE %s)", placeholder, placeholder))
 		args = append(args, "%"+search+"%")
 	}
+	if len(selector) > 0 {
+		whereParts = append(whereParts, selector.SQL(services.SBOMLabelValue("s.id", "s.project_id"), func(v interface{}) string {
+			placeholder := nextParam()
+			args = append(args, v)
+			return placeholder
+		}))
+	}
 
 	whereClause := strings.Join(whereParts, " AND ")
 
@@ -344,7 +568,7 @@ func recentSBOMs(c *fiber.Ctx) error {
 		ID           string `json:"id"`
 		ProjectName  string `json:"project_name"`
 		ManifestName string `json:"manifest_name"`
-		ObjectURL    string `json:"object_url"`
+		HasObject    bool   `json:"has_object"`
 		CreatedAt    string `json:"created_at"`
 		Source       string `json:"source"`
 		Findings     int    `json:"findings"`
@@ -366,7 +590,7 @@ func recentSBOMs(c *fiber.Ctx) error {
 			ID:           id,
 			ProjectName:  projectName,
 			ManifestName: manifestName.String, // nếu null -> ""
-			ObjectURL:    objectURL.String,
+			HasObject:    objectURL.Valid && objectURL.String != "",
 			CreatedAt:    createdAt.Format(time.RFC3339),
 			Source:       sourceVal,
 			Findings:     findings,
@@ -381,69 +605,81 @@ func recentSBOMs(c *fiber.Ctx) error {
 	})
 }
 
+// sbomAnalytics godoc
+// @Summary SBOM analytics
+// @Description SBOM, component and license series over a window, bucketed by day, week or month in the organization's time zone
+// @Tags SBOM
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD), default 13 days before to"
+// @Param to query string false "Last day (YYYY-MM-DD), default today"
+// @Param granularity query string false "day|week|month (default day)"
+// @Param group_by query string false "source|project|ecosystem|label:<key>"
+// @Param tz query string false "IANA time zone, default the organization's"
+// @Param labels query string false "Label selector (e.g. team=payments,tier!=internal)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /analytics [get]
 func sbomAnalytics(c *fiber.Ctx) error {
 	orgID, err := requireOrgID(c)
 	if err != nil {
 		return err
 	}
+	selector, err := labelSelectorQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	ctx := c.Context()
-	baseClause := orgProjectFilterClause()
 
-	// 1) scannedToday
-	var scannedToday int
-	err = db.Conn.QueryRowContext(
-		ctx,
-		`SELECT COUNT(*) FROM sboms s WHERE `+baseClause+` AND s.created_at::date = CURRENT_DATE`,
-		orgID,
-	).Scan(&scannedToday)
+	// Rollups are bucketed in the org's zone; an explicit tz scans live rows.
+	var loc *time.Location
+	tz := strings.TrimSpace(c.Query("tz"))
+	if tz != "" {
+		if loc, err = services.LoadTimeZone(tz); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	} else {
+		settings, err := services.LoadOrgSettings(ctx, db.Conn, orgID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		loc = settings.Location()
+	}
 
+	q, err := services.NewAnalyticsQuery(c.Query("from"), c.Query("to"), c.Query("granularity"), c.Query("group_by"), loc, time.Now())
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
+	q.Labels = selector
+	q.FromRollups = tz == ""
 
-	// 2) sbomTrend (last 14 days)
-	rows, err := db.Conn.QueryContext(ctx, `
-        SELECT
-            s.created_at::date AS date,
-            COUNT(*) AS scanned,
-            COUNT(*) FILTER (WHERE s.source = 'upload') AS uploaded
-        FROM sboms s
-        WHERE `+baseClause+`
-          AND s.created_at >= CURRENT_DATE - INTERVAL '14 days'
-        GROUP BY s.created_at::date
-        ORDER BY date ASC
-    `, orgID)
-
+	analytics, err := services.LoadAnalytics(ctx, db.Conn, orgID, q)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
-	defer rows.Close()
 
+	// sbomTrend keeps the original per-bucket shape. Ecosystem groups
+	// overlap, so their counts cannot be summed back into it.
 	type TrendItem struct {
 		Date     string `json:"date"`
-		Scanned  int    `json:"scanned"`
-		Uploaded int    `json:"uploaded"`
+		Scanned  int64  `json:"scanned"`
+		Uploaded int64  `json:"uploaded"`
 	}
-
 	trends := []TrendItem{}
-	for rows.Next() {
-		var rawDate time.Time
-		var scanned int
-		var uploaded int
-
-		if err := rows.Scan(&rawDate, &scanned, &uploaded); err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	for _, p := range analytics.SBOMs {
+		if q.GroupBy == services.GroupByEcosystem {
+			break
 		}
-
-		trends = append(trends, TrendItem{
-			Date:     rawDate.Format("2006-01-02"),
-			Scanned:  scanned,
-			Uploaded: uploaded,
-		})
+		if n := len(trends); n > 0 && trends[n-1].Date == p.Bucket {
+			trends[n-1].Scanned += p.Scanned
+			trends[n-1].Uploaded += p.Uploaded
+			continue
+		}
+		trends = append(trends, TrendItem{Date: p.Bucket, Scanned: p.Scanned, Uploaded: p.Uploaded})
 	}
 
 	return c.JSON(fiber.Map{
-		"scannedToday": scannedToday,
+		"scannedToday": analytics.ScannedToday,
 		"sbomTrend":    trends,
+		"data":         analytics,
 	})
 }
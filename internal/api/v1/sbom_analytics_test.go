@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"encoding/json"
 	"net/http/httptest"
 	"testing"
 	"time"
@@ -12,7 +13,13 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestSBOMAnalytics_Success(t *testing.T) {
+func expectOrgTimeZone(mock sqlmock.Sqlmock, orgID int, tz string) {
+	mock.ExpectQuery(`FROM organization_settings`).
+		WithArgs(orgID, "UTC").
+		WillReturnRows(sqlmock.NewRows([]string{"block", "time_zone"}).AddRow(false, tz))
+}
+
+func TestSBOMAnalytics_FromRollups(t *testing.T) {
 	app := newTestApp()
 
 	sqlDB, mock, err := sqlmock.New()
@@ -20,29 +27,125 @@ func TestSBOMAnalytics_Success(t *testing.T) {
 	defer sqlDB.Close()
 	db.Conn = sqlDB
 
-	// scannedToday
-	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM sboms s WHERE`).
-		WithArgs(7).
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	expectOrgTimeZone(mock, 7, "Asia/Ho_Chi_Minh")
+
+	// Without a tz override the org's rollups answer the query.
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(sboms\), 0\) FROM sbom_daily_rollups`).
+		WithArgs(7, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(5))
+	mock.ExpectQuery(`SELECT date_trunc\(\$4::text, r.day::timestamp\) AS bucket, '' AS grp,`).
+		WithArgs(7, "2025-12-01", "2025-12-02", "day", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "grp", "scanned", "uploaded", "components"}).
+			AddRow(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), "", 2, 1, 40).
+			AddRow(time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC), "", 3, 0, 0))
+	mock.ExpectQuery(`FROM sbom_daily_license_rollups r`).
+		WithArgs(7, "2025-12-01", "2025-12-02", "day").
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "license", "components"}).
+			AddRow(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), "MIT", 30).
+			AddRow(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), "UNKNOWN", 10))
+
+	req := httptest.NewRequest("GET", "/api/sbom/analytics?from=2025-12-01&to=2025-12-02", nil)
+	req.Header.Set("X-Organization-ID", "7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		ScannedToday int `json:"scannedToday"`
+		SBOMTrend    []struct {
+			Date     string `json:"date"`
+			Scanned  int    `json:"scanned"`
+			Uploaded int    `json:"uploaded"`
+		} `json:"sbomTrend"`
+		Data struct {
+			TimeZone    string `json:"time_zone"`
+			FromRollups bool   `json:"from_rollups"`
+			Components  []struct {
+				Components int `json:"components"`
+			} `json:"components"`
+			Licenses []struct {
+				License string `json:"license"`
+			} `json:"licenses"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Equal(t, 5, body.ScannedToday)
+	require.Len(t, body.SBOMTrend, 2)
+	require.Equal(t, "2025-12-01", body.SBOMTrend[0].Date)
+	require.Equal(t, 1, body.SBOMTrend[0].Uploaded)
+	require.Equal(t, "Asia/Ho_Chi_Minh", body.Data.TimeZone)
+	require.True(t, body.Data.FromRollups)
+	require.Len(t, body.Data.Components, 1)
+	require.Equal(t, 40, body.Data.Components[0].Components)
+	require.Len(t, body.Data.Licenses, 2)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
 
-	// trend query
-	rows := sqlmock.NewRows([]string{"date", "scanned", "uploaded"}).
-		AddRow(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), 2, 1).
-		AddRow(time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC), 3, 0)
+func TestSBOMAnalytics_GroupByLabelWeekly(t *testing.T) {
+	app := newTestApp()
 
-	mock.ExpectQuery(`SELECT\s+s\.created_at::date AS date,`).
-		WithArgs(7).
-		WillReturnRows(rows)
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
 
-	req := httptest.NewRequest("GET", "/api/sbom/analytics", nil)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM sboms s WHERE`).
+		WithArgs(7, "UTC").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`COALESCE\(COALESCE\(\s+\(SELECT sl.value FROM sbom_labels sl WHERE sl.sbom_id = s.id AND sl.key = \$6\)`).
+		WithArgs(7, sqlmock.AnyArg(), sqlmock.AnyArg(), "UTC", "week", "team", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "grp", "scanned", "uploaded"}).
+			AddRow(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), "", 1, 0).
+			AddRow(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), "payments", 2, 2))
+	mock.ExpectQuery(`AS components`).
+		WithArgs(7, sqlmock.AnyArg(), sqlmock.AnyArg(), "UTC", "week", "team").
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "grp", "components"}))
+	mock.ExpectQuery(`AS license`).
+		WithArgs(7, sqlmock.AnyArg(), sqlmock.AnyArg(), "UTC", "week").
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "license", "components"}))
+
+	req := httptest.NewRequest("GET", "/api/sbom/analytics?granularity=week&group_by=label:team&tz=UTC", nil)
 	req.Header.Set("X-Organization-ID", "7")
 	resp, err := app.Test(req)
 	require.NoError(t, err)
 	require.Equal(t, fiber.StatusOK, resp.StatusCode)
 
+	var body struct {
+		SBOMTrend []struct {
+			Scanned  int `json:"scanned"`
+			Uploaded int `json:"uploaded"`
+		} `json:"sbomTrend"`
+		Data struct {
+			GroupBy string `json:"group_by"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Equal(t, "label:team", body.Data.GroupBy)
+	require.Len(t, body.SBOMTrend, 1)
+	require.Equal(t, 3, body.SBOMTrend[0].Scanned)
+	require.Equal(t, 2, body.SBOMTrend[0].Uploaded)
+
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSBOMAnalytics_InvalidParams_400(t *testing.T) {
+	app := newTestApp()
+
+	for _, q := range []string{
+		"?granularity=hour&tz=UTC",
+		"?group_by=team&tz=UTC",
+		"?from=2025-12-10&to=2025-12-01&tz=UTC",
+		"?tz=Mars/Olympus",
+	} {
+		req := httptest.NewRequest("GET", "/api/sbom/analytics"+q, nil)
+		req.Header.Set("X-Organization-ID", "7")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode, q)
+	}
+}
+
 func TestSBOMAnalytics_DBErrorOnScannedToday_500(t *testing.T) {
 	app := newTestApp()
 
@@ -52,10 +155,10 @@ func TestSBOMAnalytics_DBErrorOnScannedToday_500(t *testing.T) {
 	db.Conn = sqlDB
 
 	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM sboms s WHERE`).
-		WithArgs(7).
+		WithArgs(7, "UTC").
 		WillReturnError(assertErr("db err"))
 
-	req := httptest.NewRequest("GET", "/api/sbom/analytics", nil)
+	req := httptest.NewRequest("GET", "/api/sbom/analytics?tz=UTC", nil)
 	req.Header.Set("X-Organization-ID", "7")
 	resp, err := app.Test(req)
 	require.NoError(t, err)
@@ -73,14 +176,13 @@ func TestSBOMAnalytics_DBErrorOnTrendQuery_500(t *testing.T) {
 	db.Conn = sqlDB
 
 	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM sboms s WHERE`).
-		WithArgs(7).
+		WithArgs(7, "UTC").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-	mock.ExpectQuery(`SELECT\s+s\.created_at::date AS date,`).
-		WithArgs(7).
+	mock.ExpectQuery(`AS scanned`).
 		WillReturnError(assertErr("trend err"))
 
-	req := httptest.NewRequest("GET", "/api/sbom/analytics", nil)
+	req := httptest.NewRequest("GET", "/api/sbom/analytics?tz=UTC", nil)
 	req.Header.Set("X-Organization-ID", "7")
 	resp, err := app.Test(req)
 	require.NoError(t, err)
@@ -25,12 +25,16 @@ func resetServiceMocks(t *testing.T) {
 	// restore to real implementations
 	listSBOMService = services.ListSBOM
 	getSBOMService = services.GetSBOM
+	findComponentsService = services.FindComponents
+	openSBOMObjectService = services.OpenSBOMObject
+	deleteSBOMObject = services.DeleteSBOMObject
+	presignSBOMObjectService = services.PresignSBOMObject
 }
 
 // (Optional) nếu bạn vẫn muốn giữ “real refs” để gọi,
 // thì dùng context.Context thay vì fiberCtx.
-func servicesListSBOMReal(ctx context.Context, conn *sql.DB, project string, limit int, orgID int) ([]*models.Sbom, error) {
-	return services.ListSBOM(ctx, conn, project, limit, orgID)
+func servicesListSBOMReal(ctx context.Context, conn *sql.DB, orgID int, q services.SBOMListQuery) (*services.SBOMPage, error) {
+	return services.ListSBOM(ctx, conn, orgID, q)
 }
 
 func servicesGetSBOMReal(ctx context.Context, conn *sql.DB, id string) (*models.Sbom, error) {
@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"context"
+	"log"
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// getOrgSettings godoc
+// @Summary Organization SBOM settings
+// @Tags SBOM
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /settings [get]
+func getOrgSettings(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	settings, err := services.LoadOrgSettings(c.Context(), db.Conn, orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": settings})
+}
+
+// updateOrgSettings godoc
+// @Summary Update organization SBOM settings
+// @Description Fields left out of the payload keep their current value
+// @Tags SBOM
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /settings [put]
+func updateOrgSettings(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+
+	var payload struct {
+		BlockMaliciousPackages *bool   `json:"block_malicious_packages"`
+		TimeZone               *string `json:"time_zone"`
+	}
+	if err := c.BodyParser(&payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+
+	settings, err := services.LoadOrgSettings(c.Context(), db.Conn, orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if payload.BlockMaliciousPackages != nil {
+		settings.BlockMaliciousPackages = *payload.BlockMaliciousPackages
+	}
+	previousZone := settings.TimeZone
+	if payload.TimeZone != nil {
+		loc, err := services.LoadTimeZone(*payload.TimeZone)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		settings.TimeZone = loc.String()
+	}
+	if err := services.SaveOrgSettings(c.Context(), db.Conn, orgID, settings); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if settings.TimeZone != previousZone {
+		// Rollup days are bucketed in the org's zone.
+		go func() {
+			if _, err := services.RebuildRollups(context.Background(), db.Conn, orgID); err != nil {
+				log.Printf("[ROLLUP][ERR] org=%d rebuild after time zone change: %v", orgID, err)
+			}
+		}()
+	}
+	return c.JSON(fiber.Map{"data": settings})
+}
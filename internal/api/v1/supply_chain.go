@@ -0,0 +1,151 @@
+package v1
+
+import (
+	"database/sql"
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
+	"strings"
+	"time"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+// listInternalPackages godoc
+// @Summary Internal package names
+// @Description Package names the organization publishes privately, used for dependency-confusion checks
+// @Tags SBOM
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /internal-packages [get]
+func listInternalPackages(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	list, err := services.LoadInternalPackages(c.Context(), db.Conn, orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": list})
+}
+
+// replaceInternalPackages godoc
+// @Summary Replace internal package names
+// @Description Replace the organization's internal package list. A trailing "*" matches by prefix.
+// @Tags SBOM
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /internal-packages [put]
+func replaceInternalPackages(c *fiber.Ctx) error {
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+
+	var payload struct {
+		Packages []services.InternalPackage `json:"packages"`
+	}
+	if err := c.BodyParser(&payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	for i, p := range payload.Packages {
+		p.Name = strings.TrimSpace(p.Name)
+		p.Ecosystem = strings.ToLower(strings.TrimSpace(p.Ecosystem))
+		if p.Name == "" || p.Name == "*" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "package name required"})
+		}
+		payload.Packages[i] = p
+	}
+
+	tx, err := db.Conn.BeginTx(c.Context(), nil)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(c.Context(), `DELETE FROM organization_internal_packages WHERE organization_id = $1`, orgID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	for _, p := range payload.Packages {
+		if _, err := tx.ExecContext(c.Context(), `
+			INSERT INTO organization_internal_packages (organization_id, ecosystem, name, created_at)
+			VALUES ($1, NULLIF($2, ''), $3, NOW())
+			ON CONFLICT DO NOTHING
+		`, orgID, p.Ecosystem, p.Name); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to commit transaction"})
+	}
+
+	if payload.Packages == nil {
+		payload.Packages = []services.InternalPackage{}
+	}
+	return c.JSON(fiber.Map{"data": payload.Packages})
+}
+
+// sbomFindings godoc
+// @Summary Supply-chain findings of an SBOM
+// @Description Typosquatting and dependency-confusion findings recorded for an SBOM
+// @Tags SBOM
+// @Produce json
+// @Param id path string true "SBOM ID"
+// @Param kind query string false "typosquat|dependency_confusion"
+// @Success 200 {object} map[string]interface{}
+// @Router /{id}/findings [get]
+func sbomFindings(c *fiber.Ctx) error {
+	id := c.Params("id")
+	orgID, err := requireOrgID(c)
+	if err != nil {
+		return err
+	}
+	if err := ensureSBOMAccessible(c.Context(), id, orgID); err != nil {
+		return err
+	}
+
+	query := `
+		SELECT kind, component_name, COALESCE(component_version, ''), COALESCE(ecosystem, ''),
+		       COALESCE(purl, ''), COALESCE(similar_to, ''), COALESCE(distance, 0), COALESCE(reason, ''), created_at
+		FROM sbom_supply_chain_findings
+		WHERE sbom_id = $1
+	`
+	args := []interface{}{id}
+	if kind := strings.TrimSpace(c.Query("kind")); kind != "" {
+		query += ` AND kind = $2`
+		args = append(args, kind)
+	}
+	query += ` ORDER BY kind, component_name`
+
+	rows, err := db.Conn.QueryContext(c.Context(), query, args...)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer rows.Close()
+
+	type FindingItem struct {
+		services.SupplyChainFinding
+		DetectedAt *time.Time `json:"detected_at,omitempty"`
+	}
+	list := []FindingItem{}
+	for rows.Next() {
+		var item FindingItem
+		var detectedAt sql.NullTime
+		f := &item.SupplyChainFinding
+		if err := rows.Scan(&f.Kind, &f.ComponentName, &f.ComponentVersion, &f.Ecosystem,
+			&f.Purl, &f.SimilarTo, &f.Distance, &f.Reason, &detectedAt); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		if detectedAt.Valid {
+			item.DetectedAt = &detectedAt.Time
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"data": list, "total": len(list)})
+}
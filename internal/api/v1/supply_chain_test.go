@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSBOMFindings_ListsByKind(t *testing.T) {
+	app := newTestApp()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db.Conn = sqlDB
+
+	mock.ExpectQuery(`SELECT 1\s+FROM sboms s`).
+		WithArgs(3, "sbom-1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(1))
+	mock.ExpectQuery(`FROM sbom_supply_chain_findings`).
+		WithArgs("sbom-1", "typosquat").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"kind", "component_name", "component_version", "ecosystem", "purl", "similar_to", "distance", "reason", "created_at",
+		}).AddRow("typosquat", "lodahs", "1.0.0", "npm", "pkg:npm/lodahs@1.0.0", "lodash", 0.5, "close to lodash", time.Now()))
+
+	req := httptest.NewRequest("GET", "/api/sbom/sbom-1/findings?kind=typosquat", nil)
+	req.Header.Set("X-Organization-ID", "3")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []struct {
+			Kind      string  `json:"kind"`
+			SimilarTo string  `json:"similar_to"`
+			Distance  float64 `json:"distance"`
+		} `json:"data"`
+		Total int `json:"total"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Equal(t, 1, body.Total)
+	require.Equal(t, "lodash", body.Data[0].SimilarTo)
+	require.Equal(t, 0.5, body.Data[0].Distance)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceInternalPackages_RejectsEmptyName(t *testing.T) {
+	app := newTestApp()
+
+	req := httptest.NewRequest("PUT", "/api/sbom/internal-packages", strings.NewReader(`{"packages":[{"ecosystem":"npm","name":" "}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Organization-ID", "3")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
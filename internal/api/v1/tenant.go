@@ -11,6 +11,7 @@ import (
 	fiber "github.com/gofiber/fiber/v2"
 
 	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/internal/services"
 )
 
 func requireOrgID(c *fiber.Ctx) (int, error) {
@@ -27,6 +28,23 @@ func requireOrgID(c *fiber.Ctx) (int, error) {
 	return orgID, nil
 }
 
+// requireUserID reads the caller from X-User-ID. The header is set by the API
+// gateway from the authenticated session and is not verified here, so the
+// service must not be exposed without the gateway in front of it.
+func requireUserID(c *fiber.Ctx) (int, error) {
+	userHeader := strings.TrimSpace(c.Get("X-User-ID"))
+	if userHeader == "" {
+		return 0, fiber.NewError(fiber.StatusUnauthorized, "User context missing")
+	}
+
+	userID, err := strconv.Atoi(userHeader)
+	if err != nil || userID <= 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Invalid X-User-ID header")
+	}
+
+	return userID, nil
+}
+
 func ensureProjectAccessible(ctx context.Context, name string, orgID int) (int, error) {
 	const query = `
         SELECT id
@@ -47,24 +65,36 @@ func ensureProjectAccessible(ctx context.Context, name string, orgID int) (int,
 	return projectID, nil
 }
 
-func ensureSBOMAccessible(ctx context.Context, sbomID string, orgID int) error {
+func ensureProjectIDAccessible(ctx context.Context, projectID, orgID int) (string, error) {
 	const query = `
+        SELECT name
+        FROM projects
+        WHERE id = $1
+          AND organization_id = $2
+          AND (is_archived IS NULL OR is_archived = FALSE)
+    `
+
+	var name string
+	err := db.Conn.QueryRowContext(ctx, query, projectID, orgID).Scan(&name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fiber.NewError(fiber.StatusNotFound, "Project not found")
+		}
+		return "", fmt.Errorf("verify project ownership: %w", err)
+	}
+	return name, nil
+}
+
+func ensureSBOMAccessible(ctx context.Context, sbomID string, orgID int) error {
+	query := `
         SELECT 1
         FROM sboms s
-        LEFT JOIN projects p ON p.id = s.project_id
-        WHERE s.id = $1
-          AND (
-                (p.id IS NOT NULL AND p.organization_id = $2)
-             OR EXISTS (
-                    SELECT 1 FROM projects px
-                    WHERE px.name = s.project_name
-                      AND px.organization_id = $2
-                )
-          )
+        WHERE ` + orgProjectFilterClause() + `
+          AND s.id = $2
     `
 
 	var exists int
-	err := db.Conn.QueryRowContext(ctx, query, sbomID, orgID).Scan(&exists)
+	err := db.Conn.QueryRowContext(ctx, query, orgID, sbomID).Scan(&exists)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return fiber.NewError(fiber.StatusNotFound, "SBOM not found")
@@ -76,11 +106,5 @@ func ensureSBOMAccessible(ctx context.Context, sbomID string, orgID int) error {
 }
 
 func orgProjectFilterClause() string {
-	return `
-        EXISTS (
-            SELECT 1 FROM projects p
-            WHERE p.id = s.project_id
-              AND p.organization_id = $1
-        )
-    `
+	return services.OrgSBOMFilterSQL
 }
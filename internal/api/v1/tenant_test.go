@@ -85,7 +85,7 @@ func TestEnsureSBOMAccessible_NotFound(t *testing.T) {
 	db.Conn = sqlDB
 
 	mock.ExpectQuery("SELECT 1\\s+FROM sboms").
-		WithArgs("sbom-1", 7).
+		WithArgs(7, "sbom-1").
 		WillReturnError(sql.ErrNoRows)
 
 	err = ensureSBOMAccessible(context.Background(), "sbom-1", 7)
@@ -102,7 +102,7 @@ func TestEnsureSBOMAccessible_DBError(t *testing.T) {
 	db.Conn = sqlDB
 
 	mock.ExpectQuery("SELECT 1\\s+FROM sboms").
-		WithArgs("sbom-1", 7).
+		WithArgs(7, "sbom-1").
 		WillReturnError(errors.New("db down"))
 
 	err = ensureSBOMAccessible(context.Background(), "sbom-1", 7)
@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +19,21 @@ type Config struct {
 	Token       string
 	KafkaBroker string
 	ApiPrefix   string
+	OSVDataPath string
+
+	MaliciousFeedPath string
+	RetentionInterval time.Duration
+
+	// GRPCEnabled is set when any gRPC variable is. GRPCAddr is where the
+	// internal gRPC API listens. It requires a certificate, key and client CA
+	// (mTLS) unless GRPCInsecure is set, which is meant for local development
+	// and listens on loopback by default.
+	GRPCEnabled  bool
+	GRPCAddr     string
+	GRPCTLSCert  string
+	GRPCTLSKey   string
+	GRPCClientCA string
+	GRPCInsecure bool
 }
 
 func LoadConfig() *Config {
@@ -31,6 +48,31 @@ func LoadConfig() *Config {
 		Token:       os.Getenv("GITHUB_TOKEN"),
 		KafkaBroker: os.Getenv("KAFKA_BROKER"),
 		ApiPrefix:   "/api/sbom",
+		OSVDataPath: os.Getenv("OSV_DATA_PATH"),
+
+		MaliciousFeedPath: os.Getenv("MALICIOUS_FEED_PATH"),
+		RetentionInterval: time.Hour,
+
+		GRPCAddr:     os.Getenv("GRPC_ADDR"),
+		GRPCTLSCert:  os.Getenv("GRPC_TLS_CERT"),
+		GRPCTLSKey:   os.Getenv("GRPC_TLS_KEY"),
+		GRPCClientCA: os.Getenv("GRPC_CLIENT_CA"),
+		GRPCInsecure: strings.EqualFold(os.Getenv("GRPC_INSECURE"), "true"),
+	}
+	cfg.GRPCEnabled = cfg.GRPCAddr != "" || cfg.GRPCTLSCert != "" || cfg.GRPCTLSKey != "" ||
+		cfg.GRPCClientCA != "" || cfg.GRPCInsecure
+	if cfg.GRPCAddr == "" {
+		cfg.GRPCAddr = ":9002"
+		if cfg.GRPCInsecure {
+			cfg.GRPCAddr = "127.0.0.1:9002"
+		}
+	}
+	if raw := os.Getenv("RETENTION_INTERVAL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			cfg.RetentionInterval = d
+		} else {
+			log.Printf("[CONFIG] invalid RETENTION_INTERVAL %q, using %s", raw, cfg.RetentionInterval)
+		}
 	}
 	if cfg.DatabaseURL == "" {
 		log.Fatal("DATABASE_URL missing")
@@ -0,0 +1,141 @@
+// Package gql serves the read-only GraphQL API over projects, SBOMs,
+// components and vulnerabilities. Every query runs on behalf of one
+// organization; resolvers refuse to run without one.
+package gql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Query limits; page sizes above maxPageSize are clamped.
+const (
+	maxDepth       = 15
+	maxParallelism = 10
+	maxPageSize    = 100
+)
+
+// errNoOrganization is returned by resolvers called outside WithOrganization.
+var errNoOrganization = errors.New("organization scope required")
+
+// NewSchema parses the embedded schema against the root resolver.
+func NewSchema() *graphql.Schema {
+	return graphql.MustParseSchema(schemaSDL, &Resolver{},
+		graphql.MaxDepth(maxDepth),
+		graphql.MaxParallelism(maxParallelism),
+	)
+}
+
+// Request is a GraphQL-over-HTTP request body.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type scopeKey struct{}
+
+// scope carries the caller's organization and the per-request loaders.
+type scope struct {
+	orgID   int
+	loaders *loaders
+}
+
+// WithOrganization scopes ctx to orgID with fresh loaders reading from exec.
+func WithOrganization(ctx context.Context, exec boil.ContextExecutor, orgID int) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &scope{orgID: orgID, loaders: newLoaders(exec, orgID)})
+}
+
+func scopeFrom(ctx context.Context) (*scope, error) {
+	sc, ok := ctx.Value(scopeKey{}).(*scope)
+	if !ok || sc.orgID <= 0 {
+		return nil, errNoOrganization
+	}
+	return sc, nil
+}
+
+// Execute runs one request for the organization in ctx.
+func Execute(ctx context.Context, schema *graphql.Schema, req Request) *graphql.Response {
+	return schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+}
+
+// page turns first/after arguments into an offset window; the schema
+// supplies the default page size. Cursors are opaque base64 offsets, valid
+// only with the same filters.
+func page(first int32, after *string) (offset, limit int, err error) {
+	if first < 0 {
+		return 0, 0, fmt.Errorf("first must not be negative")
+	}
+	limit = int(first)
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if after != nil && *after != "" {
+		n, err := decodeCursor(*after)
+		if err != nil {
+			return 0, 0, err
+		}
+		offset = n + 1
+	}
+	return offset, limit, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if v, ok := strings.CutPrefix(string(raw), "offset:"); ok {
+			if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+				return n, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor")
+}
+
+// window slices one page out of an already loaded list.
+func window[T any](items []T, first int32, after *string) (list []T, offset int, hasNext bool, err error) {
+	offset, limit, err := page(first, after)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if offset >= len(items) {
+		return nil, offset, false, nil
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end], offset, end < len(items), nil
+}
+
+type pageInfo struct {
+	hasNext bool
+	end     *string
+}
+
+func newPageInfo(offset, n int, hasNext bool) pageInfo {
+	info := pageInfo{hasNext: hasNext}
+	if n > 0 {
+		c := encodeCursor(offset + n - 1)
+		info.end = &c
+	}
+	return info
+}
+
+func (p pageInfo) HasNextPage() bool  { return p.hasNext }
+func (p pageInfo) EndCursor() *string { return p.end }
@@ -0,0 +1,151 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	mock.MatchExpectationsInOrder(false)
+	return sqlDB, mock
+}
+
+func TestExecute_BatchesChildren(t *testing.T) {
+	sqlDB, mock := newMockDB(t)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM "projects"`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT "projects"\.\* FROM "projects".*ORDER BY name, id LIMIT 3`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "organization_id"}).
+			AddRow(1, "Shop API", 7).
+			AddRow(2, "Billing", 7))
+	// One SBOM query for both projects.
+	mock.ExpectQuery(`FROM "sboms" WHERE \("sboms"\."project_id" IN \(\$1,\$2\)\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "project_id", "project_name", "manifest_name", "source", "created_at", "component_count"}).
+			AddRow("sb1", 1, "Shop API", "package.json", "manual", nil, 1).
+			AddRow("sb2", 1, "Shop API", "go.mod", "manual", nil, 1))
+	// One component query and one vulnerability query for both SBOMs.
+	mock.ExpectQuery(`FROM sboms s`).
+		WillReturnRows(sqlmock.NewRows([]string{"project_name", "manifest_name", "id", "c", "deps", "has_graph", "vulns"}).
+			AddRow("Shop API", "package.json", "sb1", []byte(`{"group":"@babel","name":"core","version":"7.0.0","purl":"pkg:npm/%40babel/core@7.0.0"}`), nil, false, 1).
+			AddRow("Shop API", "go.mod", "sb2", []byte(`{"name":"golang.org/x/net","version":"0.1.0"}`), nil, false, 0))
+	mock.ExpectQuery(`FROM "vulnerabilities" WHERE \("sbom_id" IN \(\$1,\$2\)\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "sbom_id", "component_name", "component_version", "vuln_id", "severity", "osv_metadata"}).
+			AddRow(10, "sb1", "@babel/core", "7.0.0", "GHSA-hrpp", "HIGH", []byte(`{"purl":"pkg:npm/%40babel/core@7.0.0"}`)).
+			AddRow(11, "sb1", "@angular/core", "7.0.0", "GHSA-angr", "HIGH", []byte(`{"purl":"pkg:npm/%40angular/core@7.0.0"}`)))
+
+	ctx := WithOrganization(context.Background(), sqlDB, 7)
+	resp := Execute(ctx, NewSchema(), Request{Query: `{
+		organization {
+			projects(first: 2) {
+				totalCount
+				pageInfo { hasNextPage }
+				edges { node {
+					name
+					sboms { edges { node {
+						id
+						components { totalCount edges { node { name ecosystem vulnerabilities { vulnId severity } } } }
+					} } }
+				} }
+			}
+		}
+	}`})
+	require.Empty(t, resp.Errors)
+
+	var out struct {
+		Organization struct {
+			Projects struct {
+				TotalCount int
+				PageInfo   struct{ HasNextPage bool }
+				Edges      []struct {
+					Node struct {
+						Name  string
+						Sboms struct {
+							Edges []struct {
+								Node struct {
+									ID         string
+									Components struct {
+										TotalCount int
+										Edges      []struct {
+											Node struct {
+												Name            string
+												Ecosystem       string
+												Vulnerabilities []struct{ VulnID, Severity string }
+											}
+										}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &out))
+	projects := out.Organization.Projects
+	require.Equal(t, 2, projects.TotalCount)
+	require.False(t, projects.PageInfo.HasNextPage)
+	require.Len(t, projects.Edges, 2)
+
+	sboms := projects.Edges[0].Node.Sboms.Edges
+	require.Len(t, sboms, 2)
+	comp := sboms[0].Node.Components.Edges[0].Node
+	require.Equal(t, "core", comp.Name)
+	require.Equal(t, "npm", comp.Ecosystem)
+	require.Len(t, comp.Vulnerabilities, 1)
+	require.Equal(t, "high", comp.Vulnerabilities[0].Severity)
+	require.Empty(t, sboms[1].Node.Components.Edges[0].Node.Vulnerabilities)
+	require.Empty(t, projects.Edges[1].Node.Sboms.Edges)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_ProjectOutsideOrganization(t *testing.T) {
+	sqlDB, mock := newMockDB(t)
+
+	mock.ExpectQuery(`FROM "projects" WHERE \("projects"\."id" IN \(\$1\)\) AND \(projects\.organization_id = \$2\)`).
+		WithArgs(5, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "organization_id"}))
+
+	ctx := WithOrganization(context.Background(), sqlDB, 7)
+	resp := Execute(ctx, NewSchema(), Request{Query: `{ project(id: "5") { name } }`})
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"project":null}`, string(resp.Data))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExecute_RequiresOrganization(t *testing.T) {
+	resp := Execute(context.Background(), NewSchema(), Request{Query: `{ organization { id } }`})
+	require.NotEmpty(t, resp.Errors)
+	require.Contains(t, resp.Errors[0].Message, errNoOrganization.Error())
+}
+
+func TestPageCursor(t *testing.T) {
+	items := []int{0, 1, 2, 3, 4}
+	list, offset, hasNext, err := window(items, 2, nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1}, list)
+	require.True(t, hasNext)
+
+	end := newPageInfo(offset, len(list), hasNext).EndCursor()
+	list, _, hasNext, err = window(items, 10, end)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 3, 4}, list)
+	require.False(t, hasNext)
+
+	bad := "not-a-cursor"
+	_, _, _, err = window(items, 2, &bad)
+	require.Error(t, err)
+}
@@ -0,0 +1,63 @@
+package gql
+
+import (
+	"context"
+	"sync"
+)
+
+// loader batches and caches lookups for one request. Resolvers that produce
+// a page of parents prime the loader with their keys; the first child lookup
+// then fetches every primed key in one query instead of one query per parent.
+type loader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   func(ctx context.Context, keys []K) (map[K]V, error)
+	cache   map[K]V
+	pending map[K]struct{}
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		cache:   map[K]V{},
+		pending: map[K]struct{}{},
+	}
+}
+
+// prime queues keys for the next batch.
+func (l *loader[K, V]) prime(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, k := range keys {
+		if _, ok := l.cache[k]; !ok {
+			l.pending[k] = struct{}{}
+		}
+	}
+}
+
+// load returns the value for key, fetching it together with every primed
+// key when it is not cached yet. Keys the fetch does not return cache as the
+// zero value.
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if v, ok := l.cache[key]; ok {
+		return v, nil
+	}
+
+	keys := []K{key}
+	for k := range l.pending {
+		if k != key {
+			keys = append(keys, k)
+		}
+	}
+	found, err := l.fetch(ctx, keys)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	for _, k := range keys {
+		l.cache[k] = found[k]
+		delete(l.pending, k)
+	}
+	return l.cache[key], nil
+}
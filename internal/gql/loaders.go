@@ -0,0 +1,145 @@
+package gql
+
+import (
+	"context"
+
+	"myesi-sbom-service-golang/internal/services"
+	"myesi-sbom-service-golang/models"
+
+	null "github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+)
+
+// sbomOrgClause keeps SBOM queries inside the organization.
+const sbomOrgClause = "EXISTS (SELECT 1 FROM projects p WHERE p.id = sboms.project_id AND p.organization_id = ?)"
+
+// sbomRow is an SBOM without its document, which GraphQL never returns whole.
+type sbomRow struct {
+	ID             string      `boil:"id"`
+	ProjectID      null.Int    `boil:"project_id"`
+	ProjectName    string      `boil:"project_name"`
+	ManifestName   null.String `boil:"manifest_name"`
+	Source         string      `boil:"source"`
+	CreatedAt      null.Time   `boil:"created_at"`
+	ComponentCount int         `boil:"component_count"`
+}
+
+var sbomRowColumns = []string{
+	"sboms.id", "sboms.project_id", "sboms.project_name", "sboms.manifest_name", "sboms.source", "sboms.created_at",
+	`CASE WHEN jsonb_typeof(sboms.sbom->'components') = 'array'
+		THEN jsonb_array_length(sboms.sbom->'components') ELSE 0 END AS component_count`,
+}
+
+var vulnerabilityColumns = []string{
+	"id", "sbom_id", "component_name", "component_version", "fix_available",
+	"fixed_version", "vuln_id", "severity", "cvss_vector", "osv_metadata",
+}
+
+// loaders holds the batched lookups of one request. Every fetch filters on
+// the request's organization, so a primed or guessed key from another
+// organization simply resolves to nothing.
+type loaders struct {
+	exec            boil.ContextExecutor
+	projects        *loader[int, *models.Project]
+	sboms           *loader[string, *sbomRow]
+	projectSBOMs    *loader[int, []*sbomRow]
+	components      *loader[string, []services.ComponentExportRow]
+	vulnerabilities *loader[string, []*models.Vulnerability]
+}
+
+func newLoaders(exec boil.ContextExecutor, orgID int) *loaders {
+	return &loaders{
+		exec: exec,
+		projects: newLoader(func(ctx context.Context, ids []int) (map[int]*models.Project, error) {
+			list, err := models.Projects(
+				qm.WhereIn("projects.id IN ?", toArgs(ids)...),
+				qm.Where("projects.organization_id = ?", orgID),
+			).All(ctx, exec)
+			if err != nil {
+				return nil, err
+			}
+			out := make(map[int]*models.Project, len(list))
+			for _, p := range list {
+				out[p.ID] = p
+			}
+			return out, nil
+		}),
+
+		sboms: newLoader(func(ctx context.Context, ids []string) (map[string]*sbomRow, error) {
+			var rows []*sbomRow
+			if err := models.Sboms(
+				qm.Select(sbomRowColumns...),
+				qm.WhereIn("sboms.id IN ?", toArgs(ids)...),
+				qm.Where(sbomOrgClause, orgID),
+			).Bind(ctx, exec, &rows); err != nil {
+				return nil, err
+			}
+			out := make(map[string]*sbomRow, len(rows))
+			for _, r := range rows {
+				out[r.ID] = r
+			}
+			return out, nil
+		}),
+
+		projectSBOMs: newLoader(func(ctx context.Context, ids []int) (map[int][]*sbomRow, error) {
+			var rows []*sbomRow
+			if err := models.Sboms(
+				qm.Select(sbomRowColumns...),
+				qm.WhereIn("sboms.project_id IN ?", toArgs(ids)...),
+				qm.Where(sbomOrgClause, orgID),
+				qm.OrderBy("sboms.manifest_name, sboms.id"),
+			).Bind(ctx, exec, &rows); err != nil {
+				return nil, err
+			}
+			out := map[int][]*sbomRow{}
+			for _, r := range rows {
+				out[r.ProjectID.Int] = append(out[r.ProjectID.Int], r)
+			}
+			return out, nil
+		}),
+
+		components: newLoader(func(ctx context.Context, ids []string) (map[string][]services.ComponentExportRow, error) {
+			export, err := services.OpenComponentExport(ctx, exec, services.ComponentExportScope{
+				OrganizationID: orgID,
+				SbomIDs:        ids,
+			}, services.ExportFormatNDJSON)
+			if err != nil {
+				return nil, err
+			}
+			defer export.Close()
+			out := map[string][]services.ComponentExportRow{}
+			_, err = export.Each(func(row services.ComponentExportRow) error {
+				out[row.SbomID] = append(out[row.SbomID], row)
+				return nil
+			})
+			return out, err
+		}),
+
+		vulnerabilities: newLoader(func(ctx context.Context, ids []string) (map[string][]*models.Vulnerability, error) {
+			list, err := models.Vulnerabilities(
+				qm.Select(vulnerabilityColumns...),
+				qm.WhereIn("sbom_id IN ?", toArgs(ids)...),
+				qm.Where(`EXISTS (SELECT 1 FROM sboms s JOIN projects p ON p.id = s.project_id
+					WHERE s.id = vulnerabilities.sbom_id AND p.organization_id = ?)`, orgID),
+				qm.OrderBy("id"),
+			).All(ctx, exec)
+			if err != nil {
+				return nil, err
+			}
+			out := map[string][]*models.Vulnerability{}
+			for _, v := range list {
+				out[v.SbomID] = append(out[v.SbomID], v)
+			}
+			return out, nil
+		}),
+	}
+}
+
+func toArgs[T any](keys []T) []interface{} {
+	out := make([]interface{}, len(keys))
+	for i, k := range keys {
+		out[i] = k
+	}
+	return out
+}
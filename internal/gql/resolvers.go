@@ -0,0 +1,401 @@
+package gql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"myesi-sbom-service-golang/internal/services"
+	"myesi-sbom-service-golang/models"
+
+	null "github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Resolver is the root Query resolver.
+type Resolver struct{}
+
+func (r *Resolver) Organization(ctx context.Context) (*organizationResolver, error) {
+	sc, err := scopeFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &organizationResolver{id: sc.orgID}, nil
+}
+
+func (r *Resolver) Project(ctx context.Context, args struct{ ID graphql.ID }) (*projectResolver, error) {
+	sc, err := scopeFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.Atoi(string(args.ID))
+	if err != nil {
+		return nil, nil
+	}
+	p, err := sc.loaders.projects.load(ctx, id)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return &projectResolver{p: p}, nil
+}
+
+func (r *Resolver) Sbom(ctx context.Context, args struct{ ID graphql.ID }) (*sbomResolver, error) {
+	sc, err := scopeFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := sc.loaders.sboms.load(ctx, string(args.ID))
+	if err != nil || s == nil {
+		return nil, err
+	}
+	return &sbomResolver{s: s}, nil
+}
+
+type organizationResolver struct {
+	id int
+}
+
+func (o *organizationResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(o.id))
+}
+
+type projectsArgs struct {
+	First           int32
+	After           *string
+	Labels          *string
+	IncludeArchived bool
+}
+
+func (o *organizationResolver) Projects(ctx context.Context, args projectsArgs) (*projectConnection, error) {
+	sc, err := scopeFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	offset, limit, err := page(args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	mods := []qm.QueryMod{qm.Where("organization_id = ?", sc.orgID)}
+	if !args.IncludeArchived {
+		mods = append(mods, qm.Where("(is_archived IS NULL OR is_archived = FALSE)"))
+	}
+	if args.Labels != nil {
+		selector, err := services.ParseLabelSelector(*args.Labels)
+		if err != nil {
+			return nil, err
+		}
+		if len(selector) > 0 {
+			var labelArgs []interface{}
+			clause := selector.SQL(services.ProjectLabelValue("projects.id"), func(v interface{}) string {
+				labelArgs = append(labelArgs, v)
+				return "?"
+			})
+			mods = append(mods, qm.Where(clause, labelArgs...))
+		}
+	}
+
+	exec := sc.loaders.exec
+	total, err := models.Projects(mods...).Count(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+	list, err := models.Projects(append(mods,
+		qm.OrderBy("name, id"),
+		qm.Limit(limit+1),
+		qm.Offset(offset),
+	)...).All(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+
+	hasNext := len(list) > limit
+	if hasNext {
+		list = list[:limit]
+	}
+	conn := &projectConnection{total: int32(total), info: newPageInfo(offset, len(list), hasNext)}
+	ids := make([]int, 0, len(list))
+	for i, p := range list {
+		ids = append(ids, p.ID)
+		conn.edges = append(conn.edges, &projectEdge{cursor: encodeCursor(offset + i), node: &projectResolver{p: p}})
+	}
+	sc.loaders.projectSBOMs.prime(ids...)
+	return conn, nil
+}
+
+type projectConnection struct {
+	total int32
+	edges []*projectEdge
+	info  pageInfo
+}
+
+func (c *projectConnection) TotalCount() int32     { return c.total }
+func (c *projectConnection) Edges() []*projectEdge { return c.edges }
+func (c *projectConnection) PageInfo() pageInfo    { return c.info }
+
+type projectEdge struct {
+	cursor string
+	node   *projectResolver
+}
+
+func (e *projectEdge) Cursor() string         { return e.cursor }
+func (e *projectEdge) Node() *projectResolver { return e.node }
+
+type projectResolver struct {
+	p *models.Project
+}
+
+func (r *projectResolver) ID() graphql.ID                { return graphql.ID(strconv.Itoa(r.p.ID)) }
+func (r *projectResolver) Name() string                  { return r.p.Name }
+func (r *projectResolver) Description() *string          { return r.p.Description.Ptr() }
+func (r *projectResolver) SourceType() *string           { return r.p.SourceType.Ptr() }
+func (r *projectResolver) RepoURL() *string              { return r.p.RepoURL.Ptr() }
+func (r *projectResolver) Archived() bool                { return r.p.IsArchived.Bool }
+func (r *projectResolver) CreatedAt() *graphql.Time      { return timePtr(r.p.CreatedAt) }
+func (r *projectResolver) LastSbomUpload() *graphql.Time { return timePtr(r.p.LastSbomUpload) }
+func (r *projectResolver) LastVulnScan() *graphql.Time   { return timePtr(r.p.LastVulnScan) }
+func (r *projectResolver) TotalVulnerabilities() int32   { return int32(r.p.TotalVulnerabilities.Int) }
+
+func (r *projectResolver) AvgRiskScore() *float64 {
+	if r.p.AvgRiskScore.Big == nil {
+		return nil
+	}
+	f, ok := r.p.AvgRiskScore.Float64()
+	if !ok {
+		return nil
+	}
+	return &f
+}
+
+type pageArgs struct {
+	First int32
+	After *string
+}
+
+func (r *projectResolver) Sboms(ctx context.Context, args pageArgs) (*sbomConnection, error) {
+	sc, err := scopeFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all, err := sc.loaders.projectSBOMs.load(ctx, r.p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newSBOMConnection(sc, all, args)
+}
+
+type sbomConnection struct {
+	total int32
+	edges []*sbomEdge
+	info  pageInfo
+}
+
+func (c *sbomConnection) TotalCount() int32  { return c.total }
+func (c *sbomConnection) Edges() []*sbomEdge { return c.edges }
+func (c *sbomConnection) PageInfo() pageInfo { return c.info }
+
+type sbomEdge struct {
+	cursor string
+	node   *sbomResolver
+}
+
+func (e *sbomEdge) Cursor() string      { return e.cursor }
+func (e *sbomEdge) Node() *sbomResolver { return e.node }
+
+// newSBOMConnection pages SBOMs and primes the loaders their children use.
+func newSBOMConnection(sc *scope, all []*sbomRow, args pageArgs) (*sbomConnection, error) {
+	list, offset, hasNext, err := window(all, args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+	conn := &sbomConnection{total: int32(len(all)), info: newPageInfo(offset, len(list), hasNext)}
+	ids := make([]string, 0, len(list))
+	for i, s := range list {
+		ids = append(ids, s.ID)
+		conn.edges = append(conn.edges, &sbomEdge{cursor: encodeCursor(offset + i), node: &sbomResolver{s: s}})
+	}
+	sc.loaders.components.prime(ids...)
+	sc.loaders.vulnerabilities.prime(ids...)
+	return conn, nil
+}
+
+type sbomResolver struct {
+	s *sbomRow
+}
+
+func (r *sbomResolver) ID() graphql.ID           { return graphql.ID(r.s.ID) }
+func (r *sbomResolver) ProjectName() string      { return r.s.ProjectName }
+func (r *sbomResolver) ManifestName() *string    { return r.s.ManifestName.Ptr() }
+func (r *sbomResolver) Source() string           { return r.s.Source }
+func (r *sbomResolver) CreatedAt() *graphql.Time { return timePtr(r.s.CreatedAt) }
+func (r *sbomResolver) ComponentCount() int32    { return int32(r.s.ComponentCount) }
+
+func (r *sbomResolver) Project(ctx context.Context) (*projectResolver, error) {
+	sc, err := scopeFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !r.s.ProjectID.Valid {
+		return nil, nil
+	}
+	p, err := sc.loaders.projects.load(ctx, r.s.ProjectID.Int)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return &projectResolver{p: p}, nil
+}
+
+func (r *sbomResolver) Components(ctx context.Context, args pageArgs) (*componentConnection, error) {
+	sc, err := scopeFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all, err := sc.loaders.components.load(ctx, r.s.ID)
+	if err != nil {
+		return nil, err
+	}
+	list, offset, hasNext, err := window(all, args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+	conn := &componentConnection{total: int32(len(all)), info: newPageInfo(offset, len(list), hasNext)}
+	for i, c := range list {
+		conn.edges = append(conn.edges, &componentEdge{cursor: encodeCursor(offset + i), node: &componentResolver{c: c}})
+	}
+	return conn, nil
+}
+
+type vulnerabilityArgs struct {
+	First    int32
+	After    *string
+	Severity *string
+}
+
+func (r *sbomResolver) Vulnerabilities(ctx context.Context, args vulnerabilityArgs) (*vulnerabilityConnection, error) {
+	sc, err := scopeFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all, err := sc.loaders.vulnerabilities.load(ctx, r.s.ID)
+	if err != nil {
+		return nil, err
+	}
+	if args.Severity != nil && *args.Severity != "" {
+		var filtered []*models.Vulnerability
+		for _, v := range all {
+			if strings.EqualFold(v.Severity.String, *args.Severity) {
+				filtered = append(filtered, v)
+			}
+		}
+		all = filtered
+	}
+	list, offset, hasNext, err := window(all, args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+	conn := &vulnerabilityConnection{total: int32(len(all)), info: newPageInfo(offset, len(list), hasNext)}
+	for i, v := range list {
+		conn.edges = append(conn.edges, &vulnEdge{cursor: encodeCursor(offset + i), node: &vulnerabilityResolver{v: v}})
+	}
+	return conn, nil
+}
+
+type componentConnection struct {
+	total int32
+	edges []*componentEdge
+	info  pageInfo
+}
+
+func (c *componentConnection) TotalCount() int32       { return c.total }
+func (c *componentConnection) Edges() []*componentEdge { return c.edges }
+func (c *componentConnection) PageInfo() pageInfo      { return c.info }
+
+type componentEdge struct {
+	cursor string
+	node   *componentResolver
+}
+
+func (e *componentEdge) Cursor() string           { return e.cursor }
+func (e *componentEdge) Node() *componentResolver { return e.node }
+
+type componentResolver struct {
+	c services.ComponentExportRow
+}
+
+func (r *componentResolver) Name() string         { return r.c.Name }
+func (r *componentResolver) Version() string      { return r.c.Version }
+func (r *componentResolver) Ecosystem() string    { return r.c.Ecosystem }
+func (r *componentResolver) Licenses() []string   { return r.c.Licenses }
+func (r *componentResolver) Relationship() string { return r.c.Relationship }
+
+func (r *componentResolver) Purl() *string {
+	if r.c.Purl == "" {
+		return nil
+	}
+	return &r.c.Purl
+}
+
+// Vulnerabilities reuses the SBOM's batch, so listing them on every
+// component of a page costs one query.
+func (r *componentResolver) Vulnerabilities(ctx context.Context) ([]*vulnerabilityResolver, error) {
+	sc, err := scopeFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all, err := sc.loaders.vulnerabilities.load(ctx, r.c.SbomID)
+	if err != nil {
+		return nil, err
+	}
+	out := []*vulnerabilityResolver{}
+	for _, v := range all {
+		if services.VulnerabilityOfComponent(v, r.c.Name, r.c.Purl) && v.ComponentVersion == r.c.Version {
+			out = append(out, &vulnerabilityResolver{v: v})
+		}
+	}
+	return out, nil
+}
+
+type vulnerabilityConnection struct {
+	total int32
+	edges []*vulnEdge
+	info  pageInfo
+}
+
+func (c *vulnerabilityConnection) TotalCount() int32  { return c.total }
+func (c *vulnerabilityConnection) Edges() []*vulnEdge { return c.edges }
+func (c *vulnerabilityConnection) PageInfo() pageInfo { return c.info }
+
+type vulnEdge struct {
+	cursor string
+	node   *vulnerabilityResolver
+}
+
+func (e *vulnEdge) Cursor() string               { return e.cursor }
+func (e *vulnEdge) Node() *vulnerabilityResolver { return e.node }
+
+type vulnerabilityResolver struct {
+	v *models.Vulnerability
+}
+
+func (r *vulnerabilityResolver) ID() graphql.ID           { return graphql.ID(strconv.FormatInt(r.v.ID, 10)) }
+func (r *vulnerabilityResolver) VulnID() *string          { return r.v.VulnID.Ptr() }
+func (r *vulnerabilityResolver) ComponentName() string    { return r.v.ComponentName }
+func (r *vulnerabilityResolver) ComponentVersion() string { return r.v.ComponentVersion }
+func (r *vulnerabilityResolver) FixAvailable() bool       { return r.v.FixAvailable.Bool }
+func (r *vulnerabilityResolver) FixedVersion() *string    { return r.v.FixedVersion.Ptr() }
+func (r *vulnerabilityResolver) CvssVector() *string      { return r.v.CVSSVector.Ptr() }
+
+func (r *vulnerabilityResolver) Severity() string {
+	if s := strings.ToLower(strings.TrimSpace(r.v.Severity.String)); s != "" {
+		return s
+	}
+	return "unknown"
+}
+
+func timePtr(t null.Time) *graphql.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &graphql.Time{Time: t.Time}
+}
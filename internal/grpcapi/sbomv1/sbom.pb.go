@@ -0,0 +1,1086 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: sbom/v1/sbom.proto
+
+package sbomv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SBOMChange_Type int32
+
+const (
+	SBOMChange_TYPE_UNSPECIFIED SBOMChange_Type = 0
+	SBOMChange_TYPE_UPSERTED    SBOMChange_Type = 1
+	SBOMChange_TYPE_DELETED     SBOMChange_Type = 2
+)
+
+// Enum value maps for SBOMChange_Type.
+var (
+	SBOMChange_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_UPSERTED",
+		2: "TYPE_DELETED",
+	}
+	SBOMChange_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_UPSERTED":    1,
+		"TYPE_DELETED":     2,
+	}
+)
+
+func (x SBOMChange_Type) Enum() *SBOMChange_Type {
+	p := new(SBOMChange_Type)
+	*p = x
+	return p
+}
+
+func (x SBOMChange_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SBOMChange_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_sbom_v1_sbom_proto_enumTypes[0].Descriptor()
+}
+
+func (SBOMChange_Type) Type() protoreflect.EnumType {
+	return &file_sbom_v1_sbom_proto_enumTypes[0]
+}
+
+func (x SBOMChange_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SBOMChange_Type.Descriptor instead.
+func (SBOMChange_Type) EnumDescriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{10, 0}
+}
+
+type SBOM struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProjectId      int64                  `protobuf:"varint,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	ProjectName    string                 `protobuf:"bytes,3,opt,name=project_name,json=projectName,proto3" json:"project_name,omitempty"`
+	ManifestName   string                 `protobuf:"bytes,4,opt,name=manifest_name,json=manifestName,proto3" json:"manifest_name,omitempty"`
+	Source         string                 `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	ComponentCount int32                  `protobuf:"varint,6,opt,name=component_count,json=componentCount,proto3" json:"component_count,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Labels         map[string]string      `protobuf:"bytes,9,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The CycloneDX JSON document, only set when include_document is true.
+	Document      []byte `protobuf:"bytes,10,opt,name=document,proto3" json:"document,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SBOM) Reset() {
+	*x = SBOM{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SBOM) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SBOM) ProtoMessage() {}
+
+func (x *SBOM) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SBOM.ProtoReflect.Descriptor instead.
+func (*SBOM) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SBOM) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SBOM) GetProjectId() int64 {
+	if x != nil {
+		return x.ProjectId
+	}
+	return 0
+}
+
+func (x *SBOM) GetProjectName() string {
+	if x != nil {
+		return x.ProjectName
+	}
+	return ""
+}
+
+func (x *SBOM) GetManifestName() string {
+	if x != nil {
+		return x.ManifestName
+	}
+	return ""
+}
+
+func (x *SBOM) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SBOM) GetComponentCount() int32 {
+	if x != nil {
+		return x.ComponentCount
+	}
+	return 0
+}
+
+func (x *SBOM) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *SBOM) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *SBOM) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *SBOM) GetDocument() []byte {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+type GetSBOMRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	IncludeDocument bool                   `protobuf:"varint,2,opt,name=include_document,json=includeDocument,proto3" json:"include_document,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetSBOMRequest) Reset() {
+	*x = GetSBOMRequest{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSBOMRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSBOMRequest) ProtoMessage() {}
+
+func (x *GetSBOMRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSBOMRequest.ProtoReflect.Descriptor instead.
+func (*GetSBOMRequest) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetSBOMRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetSBOMRequest) GetIncludeDocument() bool {
+	if x != nil {
+		return x.IncludeDocument
+	}
+	return false
+}
+
+type GetSBOMResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sbom          *SBOM                  `protobuf:"bytes,1,opt,name=sbom,proto3" json:"sbom,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSBOMResponse) Reset() {
+	*x = GetSBOMResponse{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSBOMResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSBOMResponse) ProtoMessage() {}
+
+func (x *GetSBOMResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSBOMResponse.ProtoReflect.Descriptor instead.
+func (*GetSBOMResponse) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSBOMResponse) GetSbom() *SBOM {
+	if x != nil {
+		return x.Sbom
+	}
+	return nil
+}
+
+type Component struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	SbomId       string                 `protobuf:"bytes,1,opt,name=sbom_id,json=sbomId,proto3" json:"sbom_id,omitempty"`
+	ProjectName  string                 `protobuf:"bytes,2,opt,name=project_name,json=projectName,proto3" json:"project_name,omitempty"`
+	ManifestName string                 `protobuf:"bytes,3,opt,name=manifest_name,json=manifestName,proto3" json:"manifest_name,omitempty"`
+	Name         string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Version      string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
+	Ecosystem    string                 `protobuf:"bytes,6,opt,name=ecosystem,proto3" json:"ecosystem,omitempty"`
+	Purl         string                 `protobuf:"bytes,7,opt,name=purl,proto3" json:"purl,omitempty"`
+	Licenses     []string               `protobuf:"bytes,8,rep,name=licenses,proto3" json:"licenses,omitempty"`
+	// direct, transitive or unknown
+	Relationship    string `protobuf:"bytes,9,opt,name=relationship,proto3" json:"relationship,omitempty"`
+	Vulnerabilities int32  `protobuf:"varint,10,opt,name=vulnerabilities,proto3" json:"vulnerabilities,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Component) Reset() {
+	*x = Component{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Component) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Component) ProtoMessage() {}
+
+func (x *Component) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Component.ProtoReflect.Descriptor instead.
+func (*Component) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Component) GetSbomId() string {
+	if x != nil {
+		return x.SbomId
+	}
+	return ""
+}
+
+func (x *Component) GetProjectName() string {
+	if x != nil {
+		return x.ProjectName
+	}
+	return ""
+}
+
+func (x *Component) GetManifestName() string {
+	if x != nil {
+		return x.ManifestName
+	}
+	return ""
+}
+
+func (x *Component) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Component) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Component) GetEcosystem() string {
+	if x != nil {
+		return x.Ecosystem
+	}
+	return ""
+}
+
+func (x *Component) GetPurl() string {
+	if x != nil {
+		return x.Purl
+	}
+	return ""
+}
+
+func (x *Component) GetLicenses() []string {
+	if x != nil {
+		return x.Licenses
+	}
+	return nil
+}
+
+func (x *Component) GetRelationship() string {
+	if x != nil {
+		return x.Relationship
+	}
+	return ""
+}
+
+func (x *Component) GetVulnerabilities() int32 {
+	if x != nil {
+		return x.Vulnerabilities
+	}
+	return 0
+}
+
+type ListComponentsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Exactly one of sbom_id or project_id.
+	SbomId        string `protobuf:"bytes,1,opt,name=sbom_id,json=sbomId,proto3" json:"sbom_id,omitempty"`
+	ProjectId     int64  `protobuf:"varint,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	PageSize      int32  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListComponentsRequest) Reset() {
+	*x = ListComponentsRequest{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListComponentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListComponentsRequest) ProtoMessage() {}
+
+func (x *ListComponentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListComponentsRequest.ProtoReflect.Descriptor instead.
+func (*ListComponentsRequest) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListComponentsRequest) GetSbomId() string {
+	if x != nil {
+		return x.SbomId
+	}
+	return ""
+}
+
+func (x *ListComponentsRequest) GetProjectId() int64 {
+	if x != nil {
+		return x.ProjectId
+	}
+	return 0
+}
+
+func (x *ListComponentsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListComponentsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListComponentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Components    []*Component           `protobuf:"bytes,1,rep,name=components,proto3" json:"components,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListComponentsResponse) Reset() {
+	*x = ListComponentsResponse{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListComponentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListComponentsResponse) ProtoMessage() {}
+
+func (x *ListComponentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListComponentsResponse.ProtoReflect.Descriptor instead.
+func (*ListComponentsResponse) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListComponentsResponse) GetComponents() []*Component {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+func (x *ListComponentsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type Project struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrganizationId       int64                  `protobuf:"varint,2,opt,name=organization_id,json=organizationId,proto3" json:"organization_id,omitempty"`
+	Name                 string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Description          string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	SourceType           string                 `protobuf:"bytes,5,opt,name=source_type,json=sourceType,proto3" json:"source_type,omitempty"`
+	RepoUrl              string                 `protobuf:"bytes,6,opt,name=repo_url,json=repoUrl,proto3" json:"repo_url,omitempty"`
+	Archived             bool                   `protobuf:"varint,7,opt,name=archived,proto3" json:"archived,omitempty"`
+	CreatedAt            *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastSbomUpload       *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=last_sbom_upload,json=lastSbomUpload,proto3" json:"last_sbom_upload,omitempty"`
+	LastVulnScan         *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=last_vuln_scan,json=lastVulnScan,proto3" json:"last_vuln_scan,omitempty"`
+	AvgRiskScore         *float64               `protobuf:"fixed64,11,opt,name=avg_risk_score,json=avgRiskScore,proto3,oneof" json:"avg_risk_score,omitempty"`
+	TotalVulnerabilities int32                  `protobuf:"varint,12,opt,name=total_vulnerabilities,json=totalVulnerabilities,proto3" json:"total_vulnerabilities,omitempty"`
+	Labels               map[string]string      `protobuf:"bytes,13,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Project) Reset() {
+	*x = Project{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Project) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Project) ProtoMessage() {}
+
+func (x *Project) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Project.ProtoReflect.Descriptor instead.
+func (*Project) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Project) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Project) GetOrganizationId() int64 {
+	if x != nil {
+		return x.OrganizationId
+	}
+	return 0
+}
+
+func (x *Project) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Project) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Project) GetSourceType() string {
+	if x != nil {
+		return x.SourceType
+	}
+	return ""
+}
+
+func (x *Project) GetRepoUrl() string {
+	if x != nil {
+		return x.RepoUrl
+	}
+	return ""
+}
+
+func (x *Project) GetArchived() bool {
+	if x != nil {
+		return x.Archived
+	}
+	return false
+}
+
+func (x *Project) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Project) GetLastSbomUpload() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSbomUpload
+	}
+	return nil
+}
+
+func (x *Project) GetLastVulnScan() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastVulnScan
+	}
+	return nil
+}
+
+func (x *Project) GetAvgRiskScore() float64 {
+	if x != nil && x.AvgRiskScore != nil {
+		return *x.AvgRiskScore
+	}
+	return 0
+}
+
+func (x *Project) GetTotalVulnerabilities() int32 {
+	if x != nil {
+		return x.TotalVulnerabilities
+	}
+	return 0
+}
+
+func (x *Project) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type GetProjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProjectRequest) Reset() {
+	*x = GetProjectRequest{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProjectRequest) ProtoMessage() {}
+
+func (x *GetProjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProjectRequest.ProtoReflect.Descriptor instead.
+func (*GetProjectRequest) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetProjectRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetProjectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       *Project               `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProjectResponse) Reset() {
+	*x = GetProjectResponse{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProjectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProjectResponse) ProtoMessage() {}
+
+func (x *GetProjectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProjectResponse.ProtoReflect.Descriptor instead.
+func (*GetProjectResponse) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetProjectResponse) GetProject() *Project {
+	if x != nil {
+		return x.Project
+	}
+	return nil
+}
+
+type StreamSBOMChangesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Limit the stream to one project; 0 streams the whole organization.
+	ProjectId     int64                  `protobuf:"varint,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamSBOMChangesRequest) Reset() {
+	*x = StreamSBOMChangesRequest{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamSBOMChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamSBOMChangesRequest) ProtoMessage() {}
+
+func (x *StreamSBOMChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamSBOMChangesRequest.ProtoReflect.Descriptor instead.
+func (*StreamSBOMChangesRequest) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamSBOMChangesRequest) GetProjectId() int64 {
+	if x != nil {
+		return x.ProjectId
+	}
+	return 0
+}
+
+func (x *StreamSBOMChangesRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+type SBOMChange struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Type         SBOMChange_Type        `protobuf:"varint,1,opt,name=type,proto3,enum=myesi.sbom.v1.SBOMChange_Type" json:"type,omitempty"`
+	SbomId       string                 `protobuf:"bytes,2,opt,name=sbom_id,json=sbomId,proto3" json:"sbom_id,omitempty"`
+	ProjectId    int64                  `protobuf:"varint,3,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	ProjectName  string                 `protobuf:"bytes,4,opt,name=project_name,json=projectName,proto3" json:"project_name,omitempty"`
+	ManifestName string                 `protobuf:"bytes,5,opt,name=manifest_name,json=manifestName,proto3" json:"manifest_name,omitempty"`
+	ChangedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
+	// Identifies the change; clients can use it to drop duplicates after reconnecting.
+	EventId       string `protobuf:"bytes,7,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SBOMChange) Reset() {
+	*x = SBOMChange{}
+	mi := &file_sbom_v1_sbom_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SBOMChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SBOMChange) ProtoMessage() {}
+
+func (x *SBOMChange) ProtoReflect() protoreflect.Message {
+	mi := &file_sbom_v1_sbom_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SBOMChange.ProtoReflect.Descriptor instead.
+func (*SBOMChange) Descriptor() ([]byte, []int) {
+	return file_sbom_v1_sbom_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SBOMChange) GetType() SBOMChange_Type {
+	if x != nil {
+		return x.Type
+	}
+	return SBOMChange_TYPE_UNSPECIFIED
+}
+
+func (x *SBOMChange) GetSbomId() string {
+	if x != nil {
+		return x.SbomId
+	}
+	return ""
+}
+
+func (x *SBOMChange) GetProjectId() int64 {
+	if x != nil {
+		return x.ProjectId
+	}
+	return 0
+}
+
+func (x *SBOMChange) GetProjectName() string {
+	if x != nil {
+		return x.ProjectName
+	}
+	return ""
+}
+
+func (x *SBOMChange) GetManifestName() string {
+	if x != nil {
+		return x.ManifestName
+	}
+	return ""
+}
+
+func (x *SBOMChange) GetChangedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return nil
+}
+
+func (x *SBOMChange) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+var File_sbom_v1_sbom_proto protoreflect.FileDescriptor
+
+const file_sbom_v1_sbom_proto_rawDesc = "" +
+	"\n" +
+	"\x12sbom/v1/sbom.proto\x12\rmyesi.sbom.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc4\x03\n" +
+	"\x04SBOM\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\x03R\tprojectId\x12!\n" +
+	"\fproject_name\x18\x03 \x01(\tR\vprojectName\x12#\n" +
+	"\rmanifest_name\x18\x04 \x01(\tR\fmanifestName\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12'\n" +
+	"\x0fcomponent_count\x18\x06 \x01(\x05R\x0ecomponentCount\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x127\n" +
+	"\x06labels\x18\t \x03(\v2\x1f.myesi.sbom.v1.SBOM.LabelsEntryR\x06labels\x12\x1a\n" +
+	"\bdocument\x18\n" +
+	" \x01(\fR\bdocument\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"K\n" +
+	"\x0eGetSBOMRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12)\n" +
+	"\x10include_document\x18\x02 \x01(\bR\x0fincludeDocument\":\n" +
+	"\x0fGetSBOMResponse\x12'\n" +
+	"\x04sbom\x18\x01 \x01(\v2\x13.myesi.sbom.v1.SBOMR\x04sbom\"\xb6\x02\n" +
+	"\tComponent\x12\x17\n" +
+	"\asbom_id\x18\x01 \x01(\tR\x06sbomId\x12!\n" +
+	"\fproject_name\x18\x02 \x01(\tR\vprojectName\x12#\n" +
+	"\rmanifest_name\x18\x03 \x01(\tR\fmanifestName\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\tR\aversion\x12\x1c\n" +
+	"\tecosystem\x18\x06 \x01(\tR\tecosystem\x12\x12\n" +
+	"\x04purl\x18\a \x01(\tR\x04purl\x12\x1a\n" +
+	"\blicenses\x18\b \x03(\tR\blicenses\x12\"\n" +
+	"\frelationship\x18\t \x01(\tR\frelationship\x12(\n" +
+	"\x0fvulnerabilities\x18\n" +
+	" \x01(\x05R\x0fvulnerabilities\"\x8b\x01\n" +
+	"\x15ListComponentsRequest\x12\x17\n" +
+	"\asbom_id\x18\x01 \x01(\tR\x06sbomId\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\x03R\tprojectId\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"z\n" +
+	"\x16ListComponentsResponse\x128\n" +
+	"\n" +
+	"components\x18\x01 \x03(\v2\x18.myesi.sbom.v1.ComponentR\n" +
+	"components\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xfd\x04\n" +
+	"\aProject\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12'\n" +
+	"\x0forganization_id\x18\x02 \x01(\x03R\x0eorganizationId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1f\n" +
+	"\vsource_type\x18\x05 \x01(\tR\n" +
+	"sourceType\x12\x19\n" +
+	"\brepo_url\x18\x06 \x01(\tR\arepoUrl\x12\x1a\n" +
+	"\barchived\x18\a \x01(\bR\barchived\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12D\n" +
+	"\x10last_sbom_upload\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x0elastSbomUpload\x12@\n" +
+	"\x0elast_vuln_scan\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\flastVulnScan\x12)\n" +
+	"\x0eavg_risk_score\x18\v \x01(\x01H\x00R\favgRiskScore\x88\x01\x01\x123\n" +
+	"\x15total_vulnerabilities\x18\f \x01(\x05R\x14totalVulnerabilities\x12:\n" +
+	"\x06labels\x18\r \x03(\v2\".myesi.sbom.v1.Project.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x11\n" +
+	"\x0f_avg_risk_score\"#\n" +
+	"\x11GetProjectRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"F\n" +
+	"\x12GetProjectResponse\x120\n" +
+	"\aproject\x18\x01 \x01(\v2\x16.myesi.sbom.v1.ProjectR\aproject\"k\n" +
+	"\x18StreamSBOMChangesRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\x03R\tprojectId\x120\n" +
+	"\x05since\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\"\xd9\x02\n" +
+	"\n" +
+	"SBOMChange\x122\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1e.myesi.sbom.v1.SBOMChange.TypeR\x04type\x12\x17\n" +
+	"\asbom_id\x18\x02 \x01(\tR\x06sbomId\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x03 \x01(\x03R\tprojectId\x12!\n" +
+	"\fproject_name\x18\x04 \x01(\tR\vprojectName\x12#\n" +
+	"\rmanifest_name\x18\x05 \x01(\tR\fmanifestName\x129\n" +
+	"\n" +
+	"changed_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tchangedAt\x12\x19\n" +
+	"\bevent_id\x18\a \x01(\tR\aeventId\"A\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rTYPE_UPSERTED\x10\x01\x12\x10\n" +
+	"\fTYPE_DELETED\x10\x022\xe4\x02\n" +
+	"\vSBOMService\x12H\n" +
+	"\aGetSBOM\x12\x1d.myesi.sbom.v1.GetSBOMRequest\x1a\x1e.myesi.sbom.v1.GetSBOMResponse\x12]\n" +
+	"\x0eListComponents\x12$.myesi.sbom.v1.ListComponentsRequest\x1a%.myesi.sbom.v1.ListComponentsResponse\x12Q\n" +
+	"\n" +
+	"GetProject\x12 .myesi.sbom.v1.GetProjectRequest\x1a!.myesi.sbom.v1.GetProjectResponse\x12Y\n" +
+	"\x11StreamSBOMChanges\x12'.myesi.sbom.v1.StreamSBOMChangesRequest\x1a\x19.myesi.sbom.v1.SBOMChange0\x01B:Z8myesi-sbom-service-golang/internal/grpcapi/sbomv1;sbomv1b\x06proto3"
+
+var (
+	file_sbom_v1_sbom_proto_rawDescOnce sync.Once
+	file_sbom_v1_sbom_proto_rawDescData []byte
+)
+
+func file_sbom_v1_sbom_proto_rawDescGZIP() []byte {
+	file_sbom_v1_sbom_proto_rawDescOnce.Do(func() {
+		file_sbom_v1_sbom_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sbom_v1_sbom_proto_rawDesc), len(file_sbom_v1_sbom_proto_rawDesc)))
+	})
+	return file_sbom_v1_sbom_proto_rawDescData
+}
+
+var file_sbom_v1_sbom_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sbom_v1_sbom_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_sbom_v1_sbom_proto_goTypes = []any{
+	(SBOMChange_Type)(0),             // 0: myesi.sbom.v1.SBOMChange.Type
+	(*SBOM)(nil),                     // 1: myesi.sbom.v1.SBOM
+	(*GetSBOMRequest)(nil),           // 2: myesi.sbom.v1.GetSBOMRequest
+	(*GetSBOMResponse)(nil),          // 3: myesi.sbom.v1.GetSBOMResponse
+	(*Component)(nil),                // 4: myesi.sbom.v1.Component
+	(*ListComponentsRequest)(nil),    // 5: myesi.sbom.v1.ListComponentsRequest
+	(*ListComponentsResponse)(nil),   // 6: myesi.sbom.v1.ListComponentsResponse
+	(*Project)(nil),                  // 7: myesi.sbom.v1.Project
+	(*GetProjectRequest)(nil),        // 8: myesi.sbom.v1.GetProjectRequest
+	(*GetProjectResponse)(nil),       // 9: myesi.sbom.v1.GetProjectResponse
+	(*StreamSBOMChangesRequest)(nil), // 10: myesi.sbom.v1.StreamSBOMChangesRequest
+	(*SBOMChange)(nil),               // 11: myesi.sbom.v1.SBOMChange
+	nil,                              // 12: myesi.sbom.v1.SBOM.LabelsEntry
+	nil,                              // 13: myesi.sbom.v1.Project.LabelsEntry
+	(*timestamppb.Timestamp)(nil),    // 14: google.protobuf.Timestamp
+}
+var file_sbom_v1_sbom_proto_depIdxs = []int32{
+	14, // 0: myesi.sbom.v1.SBOM.created_at:type_name -> google.protobuf.Timestamp
+	14, // 1: myesi.sbom.v1.SBOM.updated_at:type_name -> google.protobuf.Timestamp
+	12, // 2: myesi.sbom.v1.SBOM.labels:type_name -> myesi.sbom.v1.SBOM.LabelsEntry
+	1,  // 3: myesi.sbom.v1.GetSBOMResponse.sbom:type_name -> myesi.sbom.v1.SBOM
+	4,  // 4: myesi.sbom.v1.ListComponentsResponse.components:type_name -> myesi.sbom.v1.Component
+	14, // 5: myesi.sbom.v1.Project.created_at:type_name -> google.protobuf.Timestamp
+	14, // 6: myesi.sbom.v1.Project.last_sbom_upload:type_name -> google.protobuf.Timestamp
+	14, // 7: myesi.sbom.v1.Project.last_vuln_scan:type_name -> google.protobuf.Timestamp
+	13, // 8: myesi.sbom.v1.Project.labels:type_name -> myesi.sbom.v1.Project.LabelsEntry
+	7,  // 9: myesi.sbom.v1.GetProjectResponse.project:type_name -> myesi.sbom.v1.Project
+	14, // 10: myesi.sbom.v1.StreamSBOMChangesRequest.since:type_name -> google.protobuf.Timestamp
+	0,  // 11: myesi.sbom.v1.SBOMChange.type:type_name -> myesi.sbom.v1.SBOMChange.Type
+	14, // 12: myesi.sbom.v1.SBOMChange.changed_at:type_name -> google.protobuf.Timestamp
+	2,  // 13: myesi.sbom.v1.SBOMService.GetSBOM:input_type -> myesi.sbom.v1.GetSBOMRequest
+	5,  // 14: myesi.sbom.v1.SBOMService.ListComponents:input_type -> myesi.sbom.v1.ListComponentsRequest
+	8,  // 15: myesi.sbom.v1.SBOMService.GetProject:input_type -> myesi.sbom.v1.GetProjectRequest
+	10, // 16: myesi.sbom.v1.SBOMService.StreamSBOMChanges:input_type -> myesi.sbom.v1.StreamSBOMChangesRequest
+	3,  // 17: myesi.sbom.v1.SBOMService.GetSBOM:output_type -> myesi.sbom.v1.GetSBOMResponse
+	6,  // 18: myesi.sbom.v1.SBOMService.ListComponents:output_type -> myesi.sbom.v1.ListComponentsResponse
+	9,  // 19: myesi.sbom.v1.SBOMService.GetProject:output_type -> myesi.sbom.v1.GetProjectResponse
+	11, // 20: myesi.sbom.v1.SBOMService.StreamSBOMChanges:output_type -> myesi.sbom.v1.SBOMChange
+	17, // [17:21] is the sub-list for method output_type
+	13, // [13:17] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_sbom_v1_sbom_proto_init() }
+func file_sbom_v1_sbom_proto_init() {
+	if File_sbom_v1_sbom_proto != nil {
+		return
+	}
+	file_sbom_v1_sbom_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sbom_v1_sbom_proto_rawDesc), len(file_sbom_v1_sbom_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sbom_v1_sbom_proto_goTypes,
+		DependencyIndexes: file_sbom_v1_sbom_proto_depIdxs,
+		EnumInfos:         file_sbom_v1_sbom_proto_enumTypes,
+		MessageInfos:      file_sbom_v1_sbom_proto_msgTypes,
+	}.Build()
+	File_sbom_v1_sbom_proto = out.File
+	file_sbom_v1_sbom_proto_goTypes = nil
+	file_sbom_v1_sbom_proto_depIdxs = nil
+}
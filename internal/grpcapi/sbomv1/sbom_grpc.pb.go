@@ -0,0 +1,251 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: sbom/v1/sbom.proto
+
+package sbomv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SBOMService_GetSBOM_FullMethodName           = "/myesi.sbom.v1.SBOMService/GetSBOM"
+	SBOMService_ListComponents_FullMethodName    = "/myesi.sbom.v1.SBOMService/ListComponents"
+	SBOMService_GetProject_FullMethodName        = "/myesi.sbom.v1.SBOMService/GetProject"
+	SBOMService_StreamSBOMChanges_FullMethodName = "/myesi.sbom.v1.SBOMService/StreamSBOMChanges"
+)
+
+// SBOMServiceClient is the client API for SBOMService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SBOMService gives internal services typed, read-only access to SBOMs.
+// Every call is scoped to the organization sent in the
+// "x-organization-id" metadata entry.
+type SBOMServiceClient interface {
+	GetSBOM(ctx context.Context, in *GetSBOMRequest, opts ...grpc.CallOption) (*GetSBOMResponse, error)
+	ListComponents(ctx context.Context, in *ListComponentsRequest, opts ...grpc.CallOption) (*ListComponentsResponse, error)
+	GetProject(ctx context.Context, in *GetProjectRequest, opts ...grpc.CallOption) (*GetProjectResponse, error)
+	// StreamSBOMChanges sends SBOM uploads and deletions as they happen,
+	// starting after `since` (or now when unset).
+	StreamSBOMChanges(ctx context.Context, in *StreamSBOMChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SBOMChange], error)
+}
+
+type sBOMServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSBOMServiceClient(cc grpc.ClientConnInterface) SBOMServiceClient {
+	return &sBOMServiceClient{cc}
+}
+
+func (c *sBOMServiceClient) GetSBOM(ctx context.Context, in *GetSBOMRequest, opts ...grpc.CallOption) (*GetSBOMResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSBOMResponse)
+	err := c.cc.Invoke(ctx, SBOMService_GetSBOM_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sBOMServiceClient) ListComponents(ctx context.Context, in *ListComponentsRequest, opts ...grpc.CallOption) (*ListComponentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListComponentsResponse)
+	err := c.cc.Invoke(ctx, SBOMService_ListComponents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sBOMServiceClient) GetProject(ctx context.Context, in *GetProjectRequest, opts ...grpc.CallOption) (*GetProjectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProjectResponse)
+	err := c.cc.Invoke(ctx, SBOMService_GetProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sBOMServiceClient) StreamSBOMChanges(ctx context.Context, in *StreamSBOMChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SBOMChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SBOMService_ServiceDesc.Streams[0], SBOMService_StreamSBOMChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamSBOMChangesRequest, SBOMChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SBOMService_StreamSBOMChangesClient = grpc.ServerStreamingClient[SBOMChange]
+
+// SBOMServiceServer is the server API for SBOMService service.
+// All implementations must embed UnimplementedSBOMServiceServer
+// for forward compatibility.
+//
+// SBOMService gives internal services typed, read-only access to SBOMs.
+// Every call is scoped to the organization sent in the
+// "x-organization-id" metadata entry.
+type SBOMServiceServer interface {
+	GetSBOM(context.Context, *GetSBOMRequest) (*GetSBOMResponse, error)
+	ListComponents(context.Context, *ListComponentsRequest) (*ListComponentsResponse, error)
+	GetProject(context.Context, *GetProjectRequest) (*GetProjectResponse, error)
+	// StreamSBOMChanges sends SBOM uploads and deletions as they happen,
+	// starting after `since` (or now when unset).
+	StreamSBOMChanges(*StreamSBOMChangesRequest, grpc.ServerStreamingServer[SBOMChange]) error
+	mustEmbedUnimplementedSBOMServiceServer()
+}
+
+// UnimplementedSBOMServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSBOMServiceServer struct{}
+
+func (UnimplementedSBOMServiceServer) GetSBOM(context.Context, *GetSBOMRequest) (*GetSBOMResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSBOM not implemented")
+}
+func (UnimplementedSBOMServiceServer) ListComponents(context.Context, *ListComponentsRequest) (*ListComponentsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListComponents not implemented")
+}
+func (UnimplementedSBOMServiceServer) GetProject(context.Context, *GetProjectRequest) (*GetProjectResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetProject not implemented")
+}
+func (UnimplementedSBOMServiceServer) StreamSBOMChanges(*StreamSBOMChangesRequest, grpc.ServerStreamingServer[SBOMChange]) error {
+	return status.Error(codes.Unimplemented, "method StreamSBOMChanges not implemented")
+}
+func (UnimplementedSBOMServiceServer) mustEmbedUnimplementedSBOMServiceServer() {}
+func (UnimplementedSBOMServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeSBOMServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SBOMServiceServer will
+// result in compilation errors.
+type UnsafeSBOMServiceServer interface {
+	mustEmbedUnimplementedSBOMServiceServer()
+}
+
+func RegisterSBOMServiceServer(s grpc.ServiceRegistrar, srv SBOMServiceServer) {
+	// If the following call panics, it indicates UnimplementedSBOMServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SBOMService_ServiceDesc, srv)
+}
+
+func _SBOMService_GetSBOM_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSBOMRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SBOMServiceServer).GetSBOM(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SBOMService_GetSBOM_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SBOMServiceServer).GetSBOM(ctx, req.(*GetSBOMRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SBOMService_ListComponents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListComponentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SBOMServiceServer).ListComponents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SBOMService_ListComponents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SBOMServiceServer).ListComponents(ctx, req.(*ListComponentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SBOMService_GetProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SBOMServiceServer).GetProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SBOMService_GetProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SBOMServiceServer).GetProject(ctx, req.(*GetProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SBOMService_StreamSBOMChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamSBOMChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SBOMServiceServer).StreamSBOMChanges(m, &grpc.GenericServerStream[StreamSBOMChangesRequest, SBOMChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SBOMService_StreamSBOMChangesServer = grpc.ServerStreamingServer[SBOMChange]
+
+// SBOMService_ServiceDesc is the grpc.ServiceDesc for SBOMService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SBOMService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "myesi.sbom.v1.SBOMService",
+	HandlerType: (*SBOMServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSBOM",
+			Handler:    _SBOMService_GetSBOM_Handler,
+		},
+		{
+			MethodName: "ListComponents",
+			Handler:    _SBOMService_ListComponents_Handler,
+		},
+		{
+			MethodName: "GetProject",
+			Handler:    _SBOMService_GetProject_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSBOMChanges",
+			Handler:       _SBOMService_StreamSBOMChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sbom/v1/sbom.proto",
+}
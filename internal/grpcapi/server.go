@@ -0,0 +1,127 @@
+// Package grpcapi serves SBOMService, the typed API internal services use
+// instead of reading this service's tables. Every call is scoped to the
+// organization in the x-organization-id metadata entry, mirroring the
+// X-Organization-ID header of the REST API.
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"myesi-sbom-service-golang/internal/config"
+	"myesi-sbom-service-golang/internal/grpcapi/sbomv1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// OrganizationMetadataKey carries the caller's organization ID.
+const OrganizationMetadataKey = "x-organization-id"
+
+const defaultPollInterval = 2 * time.Second
+
+// Server implements sbomv1.SBOMServiceServer on top of the services package.
+type Server struct {
+	sbomv1.UnimplementedSBOMServiceServer
+
+	db *sql.DB
+	// PollInterval is how often StreamSBOMChanges checks for new changes.
+	PollInterval time.Duration
+}
+
+// NewServer returns an SBOMService reading from conn.
+func NewServer(conn *sql.DB) *Server {
+	return &Server{db: conn, PollInterval: defaultPollInterval}
+}
+
+// NewGRPCServer builds a gRPC server with the transport options from cfg and
+// SBOMService registered on it.
+func NewGRPCServer(cfg *config.Config, conn *sql.DB) (*grpc.Server, error) {
+	opts, err := ServerOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	srv := grpc.NewServer(opts...)
+	sbomv1.RegisterSBOMServiceServer(srv, NewServer(conn))
+	return srv, nil
+}
+
+// ServerOptions returns the transport options for cfg. The server only
+// starts with a certificate, key and client CA, and then requires and
+// verifies client certificates (mTLS). cfg.GRPCInsecure relaxes that for
+// local development: without a certificate the server runs in plaintext, and
+// without a client CA it does not check clients.
+func ServerOptions(cfg *config.Config) ([]grpc.ServerOption, error) {
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: 2 * time.Minute}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: 30 * time.Second, PermitWithoutStream: true}),
+	}
+
+	if !cfg.GRPCInsecure && (cfg.GRPCTLSCert == "" || cfg.GRPCTLSKey == "" || cfg.GRPCClientCA == "") {
+		return nil, errors.New("gRPC requires GRPC_TLS_CERT, GRPC_TLS_KEY and GRPC_CLIENT_CA; set GRPC_INSECURE=true for local development")
+	}
+	if cfg.GRPCTLSCert == "" && cfg.GRPCTLSKey == "" {
+		if cfg.GRPCClientCA != "" {
+			return nil, errors.New("GRPC_CLIENT_CA requires GRPC_TLS_CERT and GRPC_TLS_KEY")
+		}
+		log.Println("[GRPC][WARN] GRPC_INSECURE set, serving plaintext")
+		return opts, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.GRPCTLSCert, cfg.GRPCTLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("load grpc key pair: %w", err)
+	}
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if cfg.GRPCClientCA != "" {
+		pem, err := os.ReadFile(cfg.GRPCClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("read grpc client ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("grpc client ca %s: no certificates found", cfg.GRPCClientCA)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	} else {
+		log.Println("[GRPC][WARN] GRPC_INSECURE set, client certificates not verified")
+	}
+	return append(opts, grpc.Creds(credentials.NewTLS(tlsCfg))), nil
+}
+
+// organizationID reads the caller's organization from incoming metadata.
+func organizationID(ctx context.Context) (int, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(OrganizationMetadataKey)
+	if len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+		return 0, status.Error(codes.Unauthenticated, "organization context missing")
+	}
+	orgID, err := strconv.Atoi(strings.TrimSpace(values[0]))
+	if err != nil || orgID <= 0 {
+		return 0, status.Error(codes.InvalidArgument, "invalid "+OrganizationMetadataKey)
+	}
+	return orgID, nil
+}
+
+// internalError logs err and hides it from the caller.
+func internalError(method string, err error) error {
+	log.Printf("[GRPC][ERR] %s: %v", method, err)
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,164 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"testing"
+	"time"
+
+	"myesi-sbom-service-golang/internal/config"
+	"myesi-sbom-service-golang/internal/grpcapi/sbomv1"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newTestClient(t *testing.T) (sbomv1.SBOMServiceClient, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return startServer(t, sqlDB), mock
+}
+
+func startServer(t *testing.T, conn *sql.DB) sbomv1.SBOMServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	sbomv1.RegisterSBOMServiceServer(srv, NewServer(conn))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { cc.Close() })
+	return sbomv1.NewSBOMServiceClient(cc)
+}
+
+func orgContext(orgID string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), OrganizationMetadataKey, orgID)
+}
+
+func TestRequiresOrganization(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	_, err := client.GetProject(context.Background(), &sbomv1.GetProjectRequest{Id: 1})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.GetProject(orgContext("abc"), &sbomv1.GetProjectRequest{Id: 1})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServerOptions_FailsClosed(t *testing.T) {
+	_, err := ServerOptions(&config.Config{})
+	require.Error(t, err)
+	_, err = ServerOptions(&config.Config{GRPCTLSCert: "cert.pem", GRPCTLSKey: "key.pem"})
+	require.Error(t, err)
+
+	opts, err := ServerOptions(&config.Config{GRPCInsecure: true})
+	require.NoError(t, err)
+	require.NotEmpty(t, opts)
+}
+
+func TestGetProject(t *testing.T) {
+	client, mock := newTestClient(t)
+
+	mock.ExpectQuery(`SELECT "projects"\.\* FROM "projects" WHERE \(id = \$1\) AND \(organization_id = \$2\)`).
+		WithArgs(5, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "organization_id", "is_archived", "total_vulnerabilities"}).
+			AddRow(5, "Shop API", 7, true, 3))
+	mock.ExpectQuery(`FROM project_labels`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).AddRow("team", "payments"))
+
+	resp, err := client.GetProject(orgContext("7"), &sbomv1.GetProjectRequest{Id: 5})
+	require.NoError(t, err)
+	require.Equal(t, "Shop API", resp.Project.Name)
+	require.True(t, resp.Project.Archived)
+	require.EqualValues(t, 3, resp.Project.TotalVulnerabilities)
+	require.Equal(t, map[string]string{"team": "payments"}, resp.Project.Labels)
+	require.Nil(t, resp.Project.AvgRiskScore)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSBOM_OtherOrganization(t *testing.T) {
+	client, mock := newTestClient(t)
+
+	mock.ExpectQuery(`from "sboms" where "id"=\$1`).
+		WithArgs("sb1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "project_id", "project_name", "source", "sbom"}).
+			AddRow("sb1", 5, "Shop API", "manual", []byte(`{}`)))
+	mock.ExpectQuery(`FROM "projects" WHERE \(id = \$1\) AND \(organization_id = \$2\)`).
+		WithArgs(5, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := client.GetSBOM(orgContext("8"), &sbomv1.GetSBOMRequest{Id: "sb1"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListComponents_Pages(t *testing.T) {
+	client, mock := newTestClient(t)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM "sboms" WHERE \(id = \$1\) AND \(EXISTS`).
+		WithArgs("sb1", 7).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`FROM sboms s`).
+		WillReturnRows(sqlmock.NewRows([]string{"project_name", "manifest_name", "id", "c", "deps", "has_graph", "vulns"}).
+			AddRow("Shop API", "package.json", "sb1", []byte(`{"name":"a","version":"1.0.0"}`), nil, false, 0).
+			AddRow("Shop API", "package.json", "sb1", []byte(`{"name":"b","version":"1.0.0"}`), nil, false, 2).
+			AddRow("Shop API", "package.json", "sb1", []byte(`{"name":"c","version":"1.0.0"}`), nil, false, 0))
+
+	resp, err := client.ListComponents(orgContext("7"), &sbomv1.ListComponentsRequest{SbomId: "sb1", PageSize: 1, PageToken: "1"})
+	require.NoError(t, err)
+	require.Len(t, resp.Components, 1)
+	require.Equal(t, "b", resp.Components[0].Name)
+	require.EqualValues(t, 2, resp.Components[0].Vulnerabilities)
+	require.Equal(t, "2", resp.NextPageToken)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = client.ListComponents(orgContext("7"), &sbomv1.ListComponentsRequest{SbomId: "sb1", ProjectId: 5})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestStreamSBOMChanges(t *testing.T) {
+	client, mock := newTestClient(t)
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	changedAt := since.Add(time.Minute)
+	mock.ExpectQuery(`SELECT MIN\(change_seq\) - 1 FROM outbox_events`).
+		WithArgs(since).
+		WillReturnRows(sqlmock.NewRows([]string{"seq"}).AddRow(41))
+	mock.ExpectQuery(`FROM outbox_events`).
+		WithArgs("sbom-events", 7, 0, int64(41), changeBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"change_seq", "id", "event_type", "sbom_id", "project_id", "project_name", "manifest_name", "created_at"}).
+			AddRow(42, "ev0", "sbom.batch_created", nil, 5, "Shop API", "", changedAt).
+			AddRow(43, "ev1", "sbom.created", "sb1", 5, "Shop API", "go.mod", changedAt).
+			AddRow(44, "ev2", "sbom.deleted", "sb0", 5, "Shop API", "package.json", changedAt))
+
+	ctx, cancel := context.WithCancel(orgContext("7"))
+	defer cancel()
+	stream, err := client.StreamSBOMChanges(ctx, &sbomv1.StreamSBOMChangesRequest{Since: timestamppb.New(since)})
+	require.NoError(t, err)
+
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, sbomv1.SBOMChange_TYPE_UPSERTED, first.Type)
+	require.Equal(t, "sb1", first.SbomId)
+	second, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, sbomv1.SBOMChange_TYPE_DELETED, second.Type)
+	require.Equal(t, "package.json", second.ManifestName)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
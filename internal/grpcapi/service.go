@@ -0,0 +1,304 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"myesi-sbom-service-golang/internal/grpcapi/sbomv1"
+	"myesi-sbom-service-golang/internal/services"
+	"myesi-sbom-service-golang/models"
+
+	null "github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Component pages; larger page sizes are clamped.
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// changeBatchSize is how many outbox events one stream poll reads.
+const changeBatchSize = 100
+
+// errPageFull stops a component export once a page is filled.
+var errPageFull = errors.New("page full")
+
+// GetSBOM returns one SBOM of the caller's organization.
+func (s *Server) GetSBOM(ctx context.Context, req *sbomv1.GetSBOMRequest) (*sbomv1.GetSBOMResponse, error) {
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	sbom, err := services.GetSBOM(ctx, s.db, req.GetId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "sbom not found")
+		}
+		return nil, internalError("GetSBOM", err)
+	}
+	if _, err := s.project(ctx, orgID, sbom.ProjectID.Int); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.NotFound, "sbom not found")
+		}
+		return nil, err
+	}
+
+	labels, err := services.EventLabels(ctx, s.db, sbom.ProjectID.Int, sbom.ID)
+	if err != nil {
+		return nil, internalError("GetSBOM", err)
+	}
+
+	var doc struct {
+		Components []json.RawMessage `json:"components"`
+	}
+	_ = json.Unmarshal(sbom.Sbom, &doc)
+
+	out := &sbomv1.SBOM{
+		Id:             sbom.ID,
+		ProjectId:      int64(sbom.ProjectID.Int),
+		ProjectName:    sbom.ProjectName,
+		ManifestName:   sbom.ManifestName.String,
+		Source:         sbom.Source,
+		ComponentCount: int32(len(doc.Components)),
+		CreatedAt:      timestamp(sbom.CreatedAt),
+		UpdatedAt:      timestamp(sbom.UpdatedAt),
+		Labels:         labels,
+	}
+	if req.GetIncludeDocument() {
+		out.Document = sbom.Sbom
+	}
+	return &sbomv1.GetSBOMResponse{Sbom: out}, nil
+}
+
+// ListComponents pages through the components of one SBOM or project.
+func (s *Server) ListComponents(ctx context.Context, req *sbomv1.ListComponentsRequest) (*sbomv1.ListComponentsResponse, error) {
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if (req.GetSbomId() == "") == (req.GetProjectId() == 0) {
+		return nil, status.Error(codes.InvalidArgument, "exactly one of sbom_id or project_id is required")
+	}
+	offset := 0
+	if token := req.GetPageToken(); token != "" {
+		offset, err = strconv.Atoi(token)
+		if err != nil || offset < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+	}
+	size := int(req.GetPageSize())
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+
+	scope := services.ComponentExportScope{OrganizationID: orgID, SbomID: req.GetSbomId()}
+	if req.GetProjectId() != 0 {
+		scope.ProjectID = int(req.GetProjectId())
+		if _, err := s.project(ctx, orgID, scope.ProjectID); err != nil {
+			return nil, err
+		}
+	} else {
+		exists, err := models.Sboms(
+			qm.Where("id = ?", scope.SbomID),
+			qm.Where("EXISTS (SELECT 1 FROM projects p WHERE p.id = sboms.project_id AND p.organization_id = ?)", orgID),
+		).Exists(ctx, s.db)
+		if err != nil {
+			return nil, internalError("ListComponents", err)
+		}
+		if !exists {
+			return nil, status.Error(codes.NotFound, "sbom not found")
+		}
+	}
+
+	export, err := services.OpenComponentExport(ctx, s.db, scope, services.ExportFormatNDJSON)
+	if err != nil {
+		return nil, internalError("ListComponents", err)
+	}
+	defer export.Close()
+
+	resp := &sbomv1.ListComponentsResponse{}
+	seen := 0
+	_, err = export.Each(func(row services.ComponentExportRow) error {
+		seen++
+		if seen <= offset {
+			return nil
+		}
+		if len(resp.Components) == size {
+			resp.NextPageToken = strconv.Itoa(offset + size)
+			return errPageFull
+		}
+		resp.Components = append(resp.Components, &sbomv1.Component{
+			SbomId:          row.SbomID,
+			ProjectName:     row.Project,
+			ManifestName:    row.Manifest,
+			Name:            row.Name,
+			Version:         row.Version,
+			Ecosystem:       row.Ecosystem,
+			Purl:            row.Purl,
+			Licenses:        row.Licenses,
+			Relationship:    row.Relationship,
+			Vulnerabilities: int32(row.Vulnerabilities),
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, errPageFull) {
+		return nil, internalError("ListComponents", err)
+	}
+	return resp, nil
+}
+
+// GetProject returns one project of the caller's organization, archived
+// projects included.
+func (s *Server) GetProject(ctx context.Context, req *sbomv1.GetProjectRequest) (*sbomv1.GetProjectResponse, error) {
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	p, err := s.project(ctx, orgID, int(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+	labels, err := services.GetProjectLabels(ctx, s.db, p.ID)
+	if err != nil {
+		return nil, internalError("GetProject", err)
+	}
+
+	out := &sbomv1.Project{
+		Id:                   int64(p.ID),
+		OrganizationId:       int64(p.OrganizationID.Int),
+		Name:                 p.Name,
+		Description:          p.Description.String,
+		SourceType:           p.SourceType.String,
+		RepoUrl:              p.RepoURL.String,
+		Archived:             p.IsArchived.Bool,
+		CreatedAt:            timestamp(p.CreatedAt),
+		LastSbomUpload:       timestamp(p.LastSbomUpload),
+		LastVulnScan:         timestamp(p.LastVulnScan),
+		TotalVulnerabilities: int32(p.TotalVulnerabilities.Int),
+		Labels:               labels,
+	}
+	if p.AvgRiskScore.Big != nil {
+		if f, ok := p.AvgRiskScore.Float64(); ok {
+			out.AvgRiskScore = &f
+		}
+	}
+	return &sbomv1.GetProjectResponse{Project: out}, nil
+}
+
+// StreamSBOMChanges polls the outbox for SBOM uploads and deletions of the
+// caller's organization and sends them until the client goes away.
+func (s *Server) StreamSBOMChanges(req *sbomv1.StreamSBOMChangesRequest, stream sbomv1.SBOMService_StreamSBOMChangesServer) error {
+	ctx := stream.Context()
+	orgID, err := organizationID(ctx)
+	if err != nil {
+		return err
+	}
+	if req.GetProjectId() != 0 {
+		if _, err := s.project(ctx, orgID, int(req.GetProjectId())); err != nil {
+			return err
+		}
+	}
+
+	since := time.Now().UTC()
+	if req.GetSince() != nil {
+		since = req.GetSince().AsTime()
+	}
+	after, err := services.SBOMChangePosition(ctx, s.db, since)
+	if err != nil {
+		return internalError("StreamSBOMChanges", err)
+	}
+	q := services.SBOMChangeQuery{
+		OrganizationID: orgID,
+		ProjectID:      int(req.GetProjectId()),
+		AfterSeq:       after,
+		Limit:          changeBatchSize,
+	}
+
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		page, err := services.ListSBOMChanges(ctx, s.db, q)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return internalError("StreamSBOMChanges", err)
+		}
+		for _, c := range page.Changes {
+			if err := stream.Send(sbomChange(c)); err != nil {
+				return err
+			}
+		}
+		q.AfterSeq = page.AfterSeq
+		// A full page means more changes are waiting; fetch them right away.
+		if page.More {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// project loads a project of orgID, or returns NotFound.
+func (s *Server) project(ctx context.Context, orgID, id int) (*models.Project, error) {
+	p, err := models.Projects(
+		qm.Where("id = ?", id),
+		qm.Where("organization_id = ?", orgID),
+	).One(ctx, s.db)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "project not found")
+		}
+		return nil, internalError("project", err)
+	}
+	return p, nil
+}
+
+func sbomChange(c services.SBOMChange) *sbomv1.SBOMChange {
+	t := sbomv1.SBOMChange_TYPE_UPSERTED
+	if c.Type == services.SBOMChangeDeleted {
+		t = sbomv1.SBOMChange_TYPE_DELETED
+	}
+	return &sbomv1.SBOMChange{
+		Type:         t,
+		SbomId:       c.SBOMID,
+		ProjectId:    int64(c.ProjectID),
+		ProjectName:  c.ProjectName,
+		ManifestName: c.ManifestName,
+		ChangedAt:    timestamppb.New(c.ChangedAt),
+		EventId:      c.EventID,
+	}
+}
+
+func timestamp(t null.Time) *timestamppb.Timestamp {
+	if !t.Valid {
+		return nil
+	}
+	return timestamppb.New(t.Time)
+}
@@ -0,0 +1,540 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/lib/pq"
+)
+
+// Analytics bucket sizes.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// Analytics dimensions.
+const (
+	GroupBySource    = "source"
+	GroupByProject   = "project"
+	GroupByEcosystem = "ecosystem"
+	GroupByLabel     = "label"
+)
+
+const (
+	defaultAnalyticsDays = 14
+	maxAnalyticsDays     = 731
+	// analyticsTopLicenses caps the license series; the rest is folded
+	// into "OTHER".
+	analyticsTopLicenses = 10
+)
+
+// uploadSources are the sboms.source values of manual uploads. The upload
+// endpoint stores "manual"; "upload" is kept for older rows.
+var uploadSources = []string{"manual", "upload"}
+
+// AnalyticsQuery selects the window, bucketing and dimension of LoadAnalytics.
+// From and To are local calendar days in Location, both inclusive.
+type AnalyticsQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+	GroupBy     string
+	GroupLabel  string
+	Labels      LabelSelector
+	Location    *time.Location
+	// FromRollups reads the daily rollups instead of scanning sboms when
+	// they can answer the query. Rollup days are in the org's zone, so set
+	// it only when Location is that zone.
+	FromRollups bool
+}
+
+// rollupsCover reports whether the daily rollups hold every dimension the
+// query needs. Labels and per-ecosystem SBOM counts are not rolled up.
+func (q AnalyticsQuery) rollupsCover() bool {
+	if !q.FromRollups || len(q.Labels) > 0 {
+		return false
+	}
+	switch q.GroupBy {
+	case "", GroupBySource, GroupByProject:
+		return true
+	}
+	return false
+}
+
+// NewAnalyticsQuery validates raw query parameters. Dates are YYYY-MM-DD in
+// loc; the window defaults to the last 14 days ending today. groupBy is one of
+// source, project, ecosystem or label:<key>.
+func NewAnalyticsQuery(from, to, granularity, groupBy string, loc *time.Location, now time.Time) (AnalyticsQuery, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	q := AnalyticsQuery{Location: loc, Granularity: GranularityDay}
+
+	today := startOfDay(now.In(loc))
+	q.To = today
+	if to = strings.TrimSpace(to); to != "" {
+		d, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return q, fmt.Errorf("invalid to date %q, expected YYYY-MM-DD", to)
+		}
+		q.To = d
+	}
+	q.From = q.To.AddDate(0, 0, -(defaultAnalyticsDays - 1))
+	if from = strings.TrimSpace(from); from != "" {
+		d, err := time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			return q, fmt.Errorf("invalid from date %q, expected YYYY-MM-DD", from)
+		}
+		q.From = d
+	}
+	if q.From.After(q.To) {
+		return q, fmt.Errorf("from must not be after to")
+	}
+	if q.To.Sub(q.From) > maxAnalyticsDays*24*time.Hour {
+		return q, fmt.Errorf("range longer than %d days", maxAnalyticsDays)
+	}
+
+	switch g := strings.ToLower(strings.TrimSpace(granularity)); g {
+	case "":
+	case GranularityDay, GranularityWeek, GranularityMonth:
+		q.Granularity = g
+	default:
+		return q, fmt.Errorf("invalid granularity %q, expected day, week or month", granularity)
+	}
+
+	groupBy = strings.TrimSpace(groupBy)
+	switch {
+	case groupBy == "":
+	case strings.EqualFold(groupBy, GroupBySource), strings.EqualFold(groupBy, GroupByProject), strings.EqualFold(groupBy, GroupByEcosystem):
+		q.GroupBy = strings.ToLower(groupBy)
+	case strings.HasPrefix(strings.ToLower(groupBy), GroupByLabel+":"):
+		key := strings.TrimSpace(groupBy[len(GroupByLabel)+1:])
+		if !labelKeyPattern.MatchString(key) {
+			return q, fmt.Errorf("invalid label key %q", key)
+		}
+		q.GroupBy, q.GroupLabel = GroupByLabel, key
+	default:
+		return q, fmt.Errorf("invalid group_by %q, expected source, project, ecosystem or label:<key>", groupBy)
+	}
+	return q, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// SBOMTrendPoint counts SBOMs scanned in one bucket.
+type SBOMTrendPoint struct {
+	Bucket   string `json:"bucket"`
+	Group    string `json:"group,omitempty"`
+	Scanned  int64  `json:"scanned"`
+	Uploaded int64  `json:"uploaded"`
+}
+
+// ComponentTrendPoint counts components of the SBOMs scanned in one bucket.
+type ComponentTrendPoint struct {
+	Bucket     string `json:"bucket"`
+	Group      string `json:"group,omitempty"`
+	Components int64  `json:"components"`
+}
+
+// LicenseTrendPoint counts components under one license in one bucket.
+type LicenseTrendPoint struct {
+	Bucket     string `json:"bucket"`
+	License    string `json:"license"`
+	Components int64  `json:"components"`
+}
+
+// Analytics is the result of LoadAnalytics.
+type Analytics struct {
+	From         string                `json:"from"`
+	To           string                `json:"to"`
+	Granularity  string                `json:"granularity"`
+	GroupBy      string                `json:"group_by,omitempty"`
+	TimeZone     string                `json:"time_zone"`
+	ScannedToday int64                 `json:"scanned_today"`
+	SBOMs        []SBOMTrendPoint      `json:"sboms"`
+	Components   []ComponentTrendPoint `json:"components"`
+	Licenses     []LicenseTrendPoint   `json:"licenses"`
+	FromRollups  bool                  `json:"from_rollups"`
+}
+
+// analyticsArgs numbers query parameters as they are bound.
+type analyticsArgs []interface{}
+
+func (a *analyticsArgs) bind(v interface{}) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// base binds the org, window, zone and granularity as $1..$5 and returns the
+// bucket expression and WHERE clause over sboms s.
+func (q AnalyticsQuery) base(args *analyticsArgs, orgID int) (bucket, where string) {
+	args.bind(orgID)
+	args.bind(q.From)
+	args.bind(q.To.AddDate(0, 0, 1))
+	args.bind(q.Location.String())
+	args.bind(q.Granularity)
+	bucket = `date_trunc($5::text, s.created_at AT TIME ZONE $4::text)`
+	where = `EXISTS (SELECT 1 FROM projects p WHERE p.id = s.project_id AND p.organization_id = $1)
+		  AND s.created_at >= $2 AND s.created_at < $3`
+	if len(q.Labels) > 0 {
+		where += " AND " + q.Labels.SQL(SBOMLabelValue("s.id", "s.project_id"), args.bind)
+	}
+	return bucket, where
+}
+
+// componentEcosystemSQL derives the ecosystem of component c from its purl
+// type, using the names detectEcosystem reports.
+const componentEcosystemSQL = `(CASE LOWER(split_part(split_part(c->>'purl', ':', 2), '/', 1))
+		WHEN '' THEN 'unknown'
+		WHEN 'go' THEN 'golang'
+		ELSE LOWER(split_part(split_part(c->>'purl', ':', 2), '/', 1)) END)`
+
+const componentsJoinSQL = `CROSS JOIN LATERAL jsonb_array_elements(
+		CASE WHEN jsonb_typeof(s.sbom->'components') = 'array' THEN s.sbom->'components' ELSE '[]'::jsonb END
+	) c`
+
+// group returns the dimension expression; ecosystem needs componentsJoinSQL.
+func (q AnalyticsQuery) group(args *analyticsArgs) string {
+	switch q.GroupBy {
+	case GroupBySource:
+		return `COALESCE(NULLIF(LOWER(s.source), ''), 'unknown')`
+	case GroupByProject:
+		return `s.project_name`
+	case GroupByEcosystem:
+		return componentEcosystemSQL
+	case GroupByLabel:
+		return "COALESCE(" + SBOMLabelValue("s.id", "s.project_id")(args.bind(q.GroupLabel)) + ", '')"
+	}
+	return `''`
+}
+
+// LoadAnalytics computes SBOM, component and license series for an
+// organization, bucketed in the query's time zone.
+func LoadAnalytics(ctx context.Context, exec boil.ContextExecutor, orgID int, q AnalyticsQuery) (*Analytics, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	if q.Location == nil {
+		q.Location = time.UTC
+	}
+	out := &Analytics{
+		From:        q.From.Format("2006-01-02"),
+		To:          q.To.Format("2006-01-02"),
+		Granularity: q.Granularity,
+		GroupBy:     q.GroupBy,
+		TimeZone:    q.Location.String(),
+		SBOMs:       []SBOMTrendPoint{},
+		Components:  []ComponentTrendPoint{},
+		Licenses:    []LicenseTrendPoint{},
+	}
+	if q.GroupBy == GroupByLabel {
+		out.GroupBy = GroupByLabel + ":" + q.GroupLabel
+	}
+
+	var (
+		err      error
+		licenses []LicenseTrendPoint
+	)
+	if q.rollupsCover() {
+		if out.ScannedToday, err = rollupScannedToday(ctx, exec, orgID, q); err != nil {
+			return nil, fmt.Errorf("scanned today: %w", err)
+		}
+		if out.SBOMs, out.Components, err = rollupTrends(ctx, exec, orgID, q); err != nil {
+			return nil, fmt.Errorf("sbom trend: %w", err)
+		}
+		licenses, err = rollupLicenseTrend(ctx, exec, orgID, q)
+		out.FromRollups = true
+	} else {
+		if out.ScannedToday, err = scannedToday(ctx, exec, orgID, q); err != nil {
+			return nil, fmt.Errorf("scanned today: %w", err)
+		}
+		if out.SBOMs, err = sbomTrend(ctx, exec, orgID, q); err != nil {
+			return nil, fmt.Errorf("sbom trend: %w", err)
+		}
+		if out.Components, err = componentTrend(ctx, exec, orgID, q); err != nil {
+			return nil, fmt.Errorf("component trend: %w", err)
+		}
+		licenses, err = licenseTrend(ctx, exec, orgID, q)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("license trend: %w", err)
+	}
+	out.Licenses = foldLicenses(licenses, analyticsTopLicenses)
+	return out, nil
+}
+
+func scannedToday(ctx context.Context, exec boil.ContextExecutor, orgID int, q AnalyticsQuery) (int64, error) {
+	args := analyticsArgs{orgID, q.Location.String()}
+	where := `EXISTS (SELECT 1 FROM projects p WHERE p.id = s.project_id AND p.organization_id = $1)
+		  AND (s.created_at AT TIME ZONE $2::text)::date = (NOW() AT TIME ZONE $2::text)::date`
+	if len(q.Labels) > 0 {
+		where += " AND " + q.Labels.SQL(SBOMLabelValue("s.id", "s.project_id"), args.bind)
+	}
+	var n int64
+	err := exec.QueryRowContext(ctx, `SELECT COUNT(*) FROM sboms s WHERE `+where, args...).Scan(&n)
+	return n, err
+}
+
+func sbomTrend(ctx context.Context, exec boil.ContextExecutor, orgID int, q AnalyticsQuery) ([]SBOMTrendPoint, error) {
+	var args analyticsArgs
+	bucket, where := q.base(&args, orgID)
+	group := q.group(&args)
+	join := ""
+	if q.GroupBy == GroupByEcosystem {
+		join = componentsJoinSQL
+	}
+	query := fmt.Sprintf(`
+		SELECT %s AS bucket, %s AS grp,
+		       COUNT(DISTINCT s.id) AS scanned,
+		       COUNT(DISTINCT s.id) FILTER (WHERE LOWER(s.source) = ANY(%s)) AS uploaded
+		FROM sboms s %s
+		WHERE %s
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, bucket, group, args.bind(pq.Array(uploadSources)), join, where)
+
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []SBOMTrendPoint{}
+	for rows.Next() {
+		var (
+			b time.Time
+			p SBOMTrendPoint
+		)
+		if err := rows.Scan(&b, &p.Group, &p.Scanned, &p.Uploaded); err != nil {
+			return nil, err
+		}
+		p.Bucket = b.Format("2006-01-02")
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func componentTrend(ctx context.Context, exec boil.ContextExecutor, orgID int, q AnalyticsQuery) ([]ComponentTrendPoint, error) {
+	var args analyticsArgs
+	bucket, where := q.base(&args, orgID)
+	group := q.group(&args)
+	query := fmt.Sprintf(`
+		SELECT %s AS bucket, %s AS grp, COUNT(*) AS components
+		FROM sboms s %s
+		WHERE %s
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, bucket, group, componentsJoinSQL, where)
+
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []ComponentTrendPoint{}
+	for rows.Next() {
+		var (
+			b time.Time
+			p ComponentTrendPoint
+		)
+		if err := rows.Scan(&b, &p.Group, &p.Components); err != nil {
+			return nil, err
+		}
+		p.Bucket = b.Format("2006-01-02")
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func licenseTrend(ctx context.Context, exec boil.ContextExecutor, orgID int, q AnalyticsQuery) ([]LicenseTrendPoint, error) {
+	var args analyticsArgs
+	bucket, where := q.base(&args, orgID)
+	query := fmt.Sprintf(`
+		SELECT %s AS bucket,
+		       COALESCE(l->'license'->>'id', l->'license'->>'name', l->>'expression', 'UNKNOWN') AS license,
+		       COUNT(*) AS components
+		FROM sboms s %s
+		LEFT JOIN LATERAL jsonb_array_elements(
+			CASE WHEN jsonb_typeof(c->'licenses') = 'array' THEN c->'licenses' ELSE '[]'::jsonb END
+		) l ON TRUE
+		WHERE %s
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, bucket, componentsJoinSQL, where)
+
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []LicenseTrendPoint{}
+	for rows.Next() {
+		var (
+			b time.Time
+			p LicenseTrendPoint
+		)
+		if err := rows.Scan(&b, &p.License, &p.Components); err != nil {
+			return nil, err
+		}
+		p.Bucket = b.Format("2006-01-02")
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// foldLicenses keeps the top licenses by total count and merges the rest
+// into one "OTHER" point per bucket.
+func foldLicenses(points []LicenseTrendPoint, top int) []LicenseTrendPoint {
+	totals := map[string]int64{}
+	for _, p := range points {
+		totals[p.License] += p.Components
+	}
+	if len(totals) <= top {
+		return points
+	}
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if totals[names[i]] != totals[names[j]] {
+			return totals[names[i]] > totals[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	keep := make(map[string]bool, top)
+	for _, name := range names[:top] {
+		keep[name] = true
+	}
+
+	out := []LicenseTrendPoint{}
+	other := map[string]int{}
+	for _, p := range points {
+		if keep[p.License] {
+			out = append(out, p)
+			continue
+		}
+		if i, ok := other[p.Bucket]; ok {
+			out[i].Components += p.Components
+			continue
+		}
+		other[p.Bucket] = len(out)
+		out = append(out, LicenseTrendPoint{Bucket: p.Bucket, License: "OTHER", Components: p.Components})
+	}
+	return out
+}
+
+// rollupBase binds the org, window and granularity as $1..$4 over the
+// rollup table alias r.
+func (q AnalyticsQuery) rollupBase(args *analyticsArgs, orgID int) (bucket, group, where string) {
+	args.bind(orgID)
+	args.bind(q.From.Format("2006-01-02"))
+	args.bind(q.To.Format("2006-01-02"))
+	args.bind(q.Granularity)
+	bucket = `date_trunc($4::text, r.day::timestamp)`
+	where = `r.organization_id = $1 AND r.day BETWEEN $2::date AND $3::date`
+	switch q.GroupBy {
+	case GroupBySource:
+		group = `r.source`
+	case GroupByProject:
+		group = `COALESCE(p.name, '')`
+	default:
+		group = `''`
+	}
+	return bucket, group, where
+}
+
+func rollupScannedToday(ctx context.Context, exec boil.ContextExecutor, orgID int, q AnalyticsQuery) (int64, error) {
+	var n int64
+	err := exec.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(sboms), 0) FROM sbom_daily_rollups
+		WHERE organization_id = $1 AND day = $2::date
+	`, orgID, time.Now().In(q.Location).Format("2006-01-02")).Scan(&n)
+	return n, err
+}
+
+// rollupTrends reads the SBOM and component series in one pass over
+// sbom_daily_rollups.
+func rollupTrends(ctx context.Context, exec boil.ContextExecutor, orgID int, q AnalyticsQuery) ([]SBOMTrendPoint, []ComponentTrendPoint, error) {
+	var args analyticsArgs
+	bucket, group, where := q.rollupBase(&args, orgID)
+	query := fmt.Sprintf(`
+		SELECT %s AS bucket, %s AS grp,
+		       SUM(r.sboms) AS scanned,
+		       COALESCE(SUM(r.sboms) FILTER (WHERE r.source = ANY(%s)), 0) AS uploaded,
+		       SUM(r.components) AS components
+		FROM sbom_daily_rollups r
+		LEFT JOIN projects p ON p.id = r.project_id
+		WHERE %s
+		GROUP BY 1, 2
+		HAVING SUM(r.sboms) <> 0 OR SUM(r.components) <> 0
+		ORDER BY 1, 2
+	`, bucket, group, args.bind(pq.Array(uploadSources)), where)
+
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	sboms, components := []SBOMTrendPoint{}, []ComponentTrendPoint{}
+	for rows.Next() {
+		var (
+			b time.Time
+			p SBOMTrendPoint
+			n int64
+		)
+		if err := rows.Scan(&b, &p.Group, &p.Scanned, &p.Uploaded, &n); err != nil {
+			return nil, nil, err
+		}
+		p.Bucket = b.Format("2006-01-02")
+		if p.Scanned != 0 {
+			sboms = append(sboms, p)
+		}
+		if n != 0 {
+			components = append(components, ComponentTrendPoint{Bucket: p.Bucket, Group: p.Group, Components: n})
+		}
+	}
+	return sboms, components, rows.Err()
+}
+
+func rollupLicenseTrend(ctx context.Context, exec boil.ContextExecutor, orgID int, q AnalyticsQuery) ([]LicenseTrendPoint, error) {
+	var args analyticsArgs
+	bucket, _, where := q.rollupBase(&args, orgID)
+	query := fmt.Sprintf(`
+		SELECT %s AS bucket, r.license, SUM(r.components) AS components
+		FROM sbom_daily_license_rollups r
+		WHERE %s
+		GROUP BY 1, 2
+		HAVING SUM(r.components) <> 0
+		ORDER BY 1, 2
+	`, bucket, where)
+
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []LicenseTrendPoint{}
+	for rows.Next() {
+		var (
+			b time.Time
+			p LicenseTrendPoint
+		)
+		if err := rows.Scan(&b, &p.License, &p.Components); err != nil {
+			return nil, err
+		}
+		p.Bucket = b.Format("2006-01-02")
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAnalyticsQuery_DefaultsInOrgZone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	// 02:00 UTC on Dec 10 is still Dec 9 in New York.
+	now := time.Date(2025, 12, 10, 2, 0, 0, 0, time.UTC)
+
+	q, err := NewAnalyticsQuery("", "", "", "", loc, now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 12, 9, 0, 0, 0, 0, loc), q.To)
+	require.Equal(t, time.Date(2025, 11, 26, 0, 0, 0, 0, loc), q.From)
+	require.Equal(t, GranularityDay, q.Granularity)
+	require.Empty(t, q.GroupBy)
+}
+
+func TestNewAnalyticsQuery_Params(t *testing.T) {
+	now := time.Date(2025, 12, 10, 12, 0, 0, 0, time.UTC)
+
+	q, err := NewAnalyticsQuery("2025-01-01", "2025-06-30", "Month", "label:team", time.UTC, now)
+	require.NoError(t, err)
+	require.Equal(t, GranularityMonth, q.Granularity)
+	require.Equal(t, GroupByLabel, q.GroupBy)
+	require.Equal(t, "team", q.GroupLabel)
+
+	q, err = NewAnalyticsQuery("", "", "week", "Ecosystem", time.UTC, now)
+	require.NoError(t, err)
+	require.Equal(t, GroupByEcosystem, q.GroupBy)
+
+	for _, bad := range [][4]string{
+		{"2025-13-01", "", "", ""},
+		{"2025-02-01", "2025-01-01", "", ""},
+		{"2020-01-01", "2025-01-01", "", ""},
+		{"", "", "hour", ""},
+		{"", "", "", "team"},
+		{"", "", "", "label:"},
+	} {
+		_, err := NewAnalyticsQuery(bad[0], bad[1], bad[2], bad[3], time.UTC, now)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestFoldLicenses(t *testing.T) {
+	points := []LicenseTrendPoint{
+		{Bucket: "2025-12-01", License: "MIT", Components: 10},
+		{Bucket: "2025-12-01", License: "BSD-3-Clause", Components: 2},
+		{Bucket: "2025-12-01", License: "ISC", Components: 1},
+		{Bucket: "2025-12-02", License: "Apache-2.0", Components: 5},
+		{Bucket: "2025-12-02", License: "ISC", Components: 3},
+	}
+	require.Equal(t, []LicenseTrendPoint{
+		{Bucket: "2025-12-01", License: "MIT", Components: 10},
+		{Bucket: "2025-12-01", License: "OTHER", Components: 2},
+		{Bucket: "2025-12-01", License: "ISC", Components: 1},
+		{Bucket: "2025-12-02", License: "Apache-2.0", Components: 5},
+		{Bucket: "2025-12-02", License: "ISC", Components: 3},
+	}, foldLicenses(points, 3))
+	require.Equal(t, points, foldLicenses(points, 4))
+}
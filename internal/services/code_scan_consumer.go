@@ -395,6 +395,14 @@ func handleCodeScanDone(ctx context.Context, evt CodeScanEvent) error {
 		toCreate = 1
 	}
 
+	// Malicious-package blocking must not be skipped on a database hiccup, so
+	// a failed load retries the whole message.
+	settings, err := LoadOrgSettings(ctx, db.Conn, orgID)
+	if err != nil {
+		log.Printf("[SBOM][ERR] load settings for org %d: %v", orgID, err)
+		return fmt.Errorf("load org settings: %w", err)
+	}
+
 	// -----------------------------------------------------
 	// 3) Check quota before creation
 	// -----------------------------------------------------
@@ -421,7 +429,7 @@ func handleCodeScanDone(ctx context.Context, evt CodeScanEvent) error {
 	// -----------------------------------------------------
 	var createdSBOMs []map[string]interface{}
 	manifestName := ""
-
+	blockedManifests := 0
 	for _, m := range evt.Manifests {
 		name, _ := m["name"].(string)
 		contentStr, _ := m["content"].(string)
@@ -436,17 +444,38 @@ func handleCodeScanDone(ctx context.Context, evt CodeScanEvent) error {
 			continue
 		}
 
-		id, _, err := UpsertSBOM(ctx, db.Conn, projectID, project, manifestName, sbomRes.Data, "auto-code-scan", "")
+		comps := ExtractComponents(sbomRes.Data)
+		malicious := CheckMaliciousPackages(comps)
+		if len(malicious) > 0 && settings.BlockMaliciousPackages {
+			blockedManifests++
+			if err := RecordMaliciousMatches(ctx, db.Conn, "", orgID, projectID, project, name, malicious, true); err != nil {
+				log.Printf("[MAL][ERR] record failed for %s: %v", name, err)
+			}
+			log.Printf("[MAL] blocked SBOM for %s (manifest=%s): %d malicious package(s)", project, name, len(malicious))
+			continue
+		}
+
+		id, _, err := UpsertSBOMInTx(ctx, db.Conn, projectID, project, manifestName, sbomRes.Data, "auto-code-scan", "")
 		if err != nil {
 			log.Printf("[SBOM][ERR] UpsertSBOM failed for %s: %v", name, err)
 			continue
 		}
 		successful++
 
-		comps := ExtractComponents(sbomRes.Data)
+		if err := RecordMaliciousMatches(ctx, db.Conn, id, orgID, projectID, project, name, malicious, false); err != nil {
+			log.Printf("[MAL][ERR] record failed for %s: %v", name, err)
+		}
+		vulns, err := MatchAndStoreVulnerabilities(ctx, db.Conn, id, project, comps)
+		if err != nil {
+			log.Printf("[OSV][ERR] match failed for %s: %v", name, err)
+		}
+		if _, err := CheckSupplyChain(ctx, db.Conn, id, project, projectID, orgID, comps); err != nil {
+			log.Printf("[SUPPLY][ERR] check failed for %s: %v", name, err)
+		}
 		createdSBOMs = append(createdSBOMs, map[string]interface{}{
 			"id":         id,
 			"components": comps,
+			"vulns":      vulns,
 		})
 		log.Printf("[SBOM] Created SBOM %s for project %s (manifest=%s)", id, project, name)
 	}
@@ -454,13 +483,13 @@ func handleCodeScanDone(ctx context.Context, evt CodeScanEvent) error {
 	// -----------------------------------------------------
 	// 5) Fallback: build SBOM from findings
 	// -----------------------------------------------------
-	if len(createdSBOMs) == 0 && len(evt.Findings) > 0 {
+	if len(createdSBOMs) == 0 && blockedManifests == 0 && len(evt.Findings) > 0 {
 		sbomMap := BuildSBOMFromFindings(evt.Findings)
 		sbomData, _ := json.Marshal(sbomMap)
 
 		url, _ := UploadSBOMJSON(ctx, orgID, projectID, project, manifestName, sbomData)
 
-		id, _, err := UpsertSBOM(ctx, db.Conn, projectID, project, manifestName, sbomData, "auto-code-scan", url)
+		id, _, err := UpsertSBOMInTx(ctx, db.Conn, projectID, project, manifestName, sbomData, "auto-code-scan", url)
 		if err != nil {
 			log.Printf("[SBOM][ERR] fallback upsert failed: %v", err)
 			return err
@@ -475,6 +504,10 @@ func handleCodeScanDone(ctx context.Context, evt CodeScanEvent) error {
 		log.Printf("[SBOM] Fallback SBOM created for project %s", project)
 	}
 
+	if _, err := RecomputeProjectRisk(ctx, db.Conn, projectID); err != nil {
+		log.Printf("[RISK][ERR] recompute failed for project %d: %v", projectID, err)
+	}
+
 	// -----------------------------------------------------
 	// 6) Queue batch event
 	// -----------------------------------------------------
@@ -517,6 +550,7 @@ func queueSBOMBatchEvent(ctx context.Context, evt CodeScanEvent, orgID int, code
 		"project_scan_quota_consumed": true,
 		"timestamp":                   time.Now().UTC(),
 		"sbom_records":                records,
+		"labels":                      eventLabels(ctx, nil, evt.ProjectID, ""),
 	}
 
 	dedupKey := fmt.Sprintf("sbom-batch:%d:%s", evt.ProjectID, evt.Timestamp.UTC().Format(time.RFC3339Nano))
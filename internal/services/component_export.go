@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// Component export formats.
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// componentExportFlushEvery bounds how many rows sit in the encoder before
+// they are pushed to the response.
+const componentExportFlushEvery = 500
+
+// ComponentExportColumns is the CSV header, in column order.
+var ComponentExportColumns = []string{
+	"project", "manifest", "sbom_id", "name", "version", "ecosystem",
+	"purl", "licenses", "relationship", "vulnerabilities",
+}
+
+// ComponentExportScope selects the SBOMs to export: one SBOM, a set of
+// SBOMs, one project or the whole organization. OrganizationID is always
+// enforced.
+type ComponentExportScope struct {
+	OrganizationID int
+	ProjectID      int
+	SbomID         string
+	SbomIDs        []string
+	Labels         LabelSelector
+}
+
+// ComponentExportRow is one component of one SBOM.
+type ComponentExportRow struct {
+	Project         string   `json:"project"`
+	Manifest        string   `json:"manifest"`
+	SbomID          string   `json:"sbom_id"`
+	Name            string   `json:"name"`
+	Version         string   `json:"version"`
+	Ecosystem       string   `json:"ecosystem"`
+	Purl            string   `json:"purl"`
+	Licenses        []string `json:"licenses"`
+	Relationship    string   `json:"relationship"`
+	Vulnerabilities int      `json:"vulnerabilities"`
+}
+
+// ParseExportFormat normalizes the format query value; csv is the default.
+func ParseExportFormat(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", ExportFormatCSV:
+		return ExportFormatCSV, nil
+	case ExportFormatNDJSON, "jsonl":
+		return ExportFormatNDJSON, nil
+	}
+	return "", fmt.Errorf("unsupported format %q (csv|ndjson)", raw)
+}
+
+// ExportContentType is the response media type of an export format.
+func ExportContentType(format string) string {
+	if format == ExportFormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ComponentExport is an open export cursor. Rows are read from the database
+// as they are written out, so memory stays flat however large the scope is.
+type ComponentExport struct {
+	rows   *sql.Rows
+	format string
+}
+
+// OpenComponentExport runs the export query. The caller must Close it.
+func OpenComponentExport(ctx context.Context, exec boil.ContextExecutor, scope ComponentExportScope, format string) (*ComponentExport, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	args := []interface{}{scope.OrganizationID}
+	bind := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where := "EXISTS (SELECT 1 FROM projects p WHERE p.id = s.project_id AND p.organization_id = $1)"
+	if scope.SbomID != "" {
+		where += " AND s.id = " + bind(scope.SbomID)
+	}
+	if len(scope.SbomIDs) > 0 {
+		params := make([]string, len(scope.SbomIDs))
+		for i, id := range scope.SbomIDs {
+			params[i] = bind(id)
+		}
+		where += " AND s.id IN (" + strings.Join(params, ", ") + ")"
+	}
+	if scope.ProjectID > 0 {
+		where += " AND s.project_id = " + bind(scope.ProjectID)
+	}
+	if len(scope.Labels) > 0 {
+		where += " AND " + scope.Labels.SQL(SBOMLabelValue("s.id", "s.project_id"), bind)
+	}
+
+	rows, err := exec.QueryContext(ctx, `
+		SELECT s.project_name, COALESCE(s.manifest_name, ''), s.id, c,
+		       root.deps, COALESCE(jsonb_typeof(s.sbom->'dependencies') = 'array', FALSE),
+		       (SELECT COUNT(DISTINCT v.vuln_id)
+		        FROM vulnerabilities v
+		        WHERE v.sbom_id = s.id
+		          AND `+vulnComponentMatchSQL+`
+		          AND v.component_version = COALESCE(c->>'version', ''))
+		FROM sboms s
+		`+componentsJoinSQL+`
+		LEFT JOIN LATERAL (
+			SELECT d->'dependsOn' AS deps
+			FROM jsonb_array_elements(
+				CASE WHEN jsonb_typeof(s.sbom->'dependencies') = 'array' THEN s.sbom->'dependencies' ELSE '[]'::jsonb END
+			) d
+			WHERE d->>'ref' = s.sbom->'metadata'->'component'->>'bom-ref'
+			LIMIT 1
+		) root ON TRUE
+		WHERE `+where+`
+		ORDER BY s.project_name, s.manifest_name, s.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &ComponentExport{rows: rows, format: format}, nil
+}
+
+// Close releases the database cursor.
+func (e *ComponentExport) Close() error {
+	return e.rows.Close()
+}
+
+// Each calls fn for every exported component in cursor order and returns
+// how many were visited.
+func (e *ComponentExport) Each(fn func(ComponentExportRow) error) (int, error) {
+	n := 0
+	for e.rows.Next() {
+		var (
+			row              ComponentExportRow
+			rawComp, rawDeps []byte
+			hasGraph         bool
+		)
+		if err := e.rows.Scan(&row.Project, &row.Manifest, &row.SbomID, &rawComp, &rawDeps, &hasGraph, &row.Vulnerabilities); err != nil {
+			return n, err
+		}
+		var comp map[string]interface{}
+		if err := json.Unmarshal(rawComp, &comp); err != nil {
+			continue
+		}
+		var rootDeps []string
+		if len(rawDeps) > 0 {
+			_ = json.Unmarshal(rawDeps, &rootDeps)
+		}
+		row.Name, _ = comp["name"].(string)
+		row.Version, _ = comp["version"].(string)
+		row.Purl, _ = comp["purl"].(string)
+		row.Ecosystem = detectEcosystem(comp)
+		row.Licenses = componentLicenses(comp)
+		row.Relationship = ComponentRelationship(comp, rootDeps, hasGraph)
+
+		if err := fn(row); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, e.rows.Err()
+}
+
+// Stream writes every row to w in the export format and returns how many
+// components were written.
+func (e *ComponentExport) Stream(w io.Writer) (int, error) {
+	enc := newComponentExportEncoder(e.format, w)
+	if err := enc.header(); err != nil {
+		return 0, err
+	}
+	written := 0
+	n, err := e.Each(func(row ComponentExportRow) error {
+		if err := enc.write(row); err != nil {
+			return err
+		}
+		if written++; written%componentExportFlushEvery == 0 {
+			return flushExport(enc, w)
+		}
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, flushExport(enc, w)
+}
+
+// flushExport drains the encoder and, when w buffers too (the response
+// writer does), pushes the bytes on to the client.
+func flushExport(enc componentExportEncoder, w io.Writer) error {
+	if err := enc.flush(); err != nil {
+		return err
+	}
+	if f, ok := w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// componentLicenses lists the SPDX ids, names or expressions of a component.
+func componentLicenses(comp map[string]interface{}) []string {
+	out := []string{}
+	list, _ := comp["licenses"].([]interface{})
+	for _, l := range list {
+		entry, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if expr, ok := entry["expression"].(string); ok && expr != "" {
+			out = append(out, expr)
+			continue
+		}
+		lic, _ := entry["license"].(map[string]interface{})
+		if id, ok := lic["id"].(string); ok && id != "" {
+			out = append(out, id)
+		} else if name, ok := lic["name"].(string); ok && name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+type componentExportEncoder interface {
+	header() error
+	write(ComponentExportRow) error
+	flush() error
+}
+
+func newComponentExportEncoder(format string, w io.Writer) componentExportEncoder {
+	if format == ExportFormatNDJSON {
+		return &ndjsonExportEncoder{enc: json.NewEncoder(w)}
+	}
+	return &csvExportEncoder{w: csv.NewWriter(w)}
+}
+
+type csvExportEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvExportEncoder) header() error {
+	return e.w.Write(ComponentExportColumns)
+}
+
+func (e *csvExportEncoder) write(r ComponentExportRow) error {
+	return e.w.Write([]string{
+		csvCell(r.Project), csvCell(r.Manifest), csvCell(r.SbomID), csvCell(r.Name), csvCell(r.Version),
+		csvCell(r.Ecosystem), csvCell(r.Purl), csvCell(strings.Join(r.Licenses, ";")), csvCell(r.Relationship),
+		strconv.Itoa(r.Vulnerabilities),
+	})
+}
+
+// csvCell quotes SBOM-supplied text that a spreadsheet would run as a formula.
+func csvCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+func (e *csvExportEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonExportEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonExportEncoder) header() error { return nil }
+
+func (e *ndjsonExportEncoder) write(r ComponentExportRow) error {
+	return e.enc.Encode(r)
+}
+
+func (e *ndjsonExportEncoder) flush() error { return nil }
+
+// ComponentExportFilename names the download for a scope label such as a
+// project or manifest name.
+func ComponentExportFilename(scope, format string) string {
+	ext := "csv"
+	if format == ExportFormatNDJSON {
+		ext = "ndjson"
+	}
+	return sanitizePathSegment(scope) + "-components." + ext
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVExportEncoder_QuotesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	enc := newComponentExportEncoder(ExportFormatCSV, &buf)
+	require.NoError(t, enc.write(ComponentExportRow{
+		Project:         "=HYPERLINK(\"http://x\")",
+		Manifest:        "package.json",
+		SbomID:          "sb-1",
+		Name:            "@babel/core",
+		Version:         "-1+cmd",
+		Ecosystem:       "npm",
+		Purl:            "pkg:npm/%40babel/core@7.0.0",
+		Licenses:        []string{"+MIT", "Apache-2.0"},
+		Relationship:    "\tdirect",
+		Vulnerabilities: 2,
+	}))
+	require.NoError(t, enc.flush())
+
+	require.Equal(t,
+		"\"'=HYPERLINK(\"\"http://x\"\")\",package.json,sb-1,'@babel/core,'-1+cmd,npm,pkg:npm/%40babel/core@7.0.0,'+MIT;Apache-2.0,'\tdirect,2\n",
+		buf.String())
+}
@@ -34,11 +34,15 @@ func ExtractComponents(sbomJSON []byte) []map[string]string {
 			// ============================
 			eco := detectEcosystem(comp)
 
-			comps = append(comps, map[string]string{
+			entry := map[string]string{
 				"name":    name,
 				"version": version,
 				"type":    eco,
-			})
+			}
+			if purl, _ := comp["purl"].(string); purl != "" {
+				entry["purl"] = purl
+			}
+			comps = append(comps, entry)
 		}
 	}
 
@@ -75,11 +79,15 @@ func ExtractComponents(sbomJSON []byte) []map[string]string {
 				continue
 			}
 			eco := detectEcosystem(art)
-			comps = append(comps, map[string]string{
+			entry := map[string]string{
 				"name":    name,
 				"version": version,
 				"type":    eco,
-			})
+			}
+			if purl, _ := art["purl"].(string); purl != "" {
+				entry["purl"] = purl
+			}
+			comps = append(comps, entry)
 		}
 	}
 
@@ -107,6 +115,14 @@ func detectEcosystem(comp map[string]interface{}) string {
 			return "composer"
 		case strings.HasPrefix(p, "pkg:nuget/"):
 			return "nuget"
+		case strings.HasPrefix(p, "pkg:cargo/"):
+			return "cargo"
+		case strings.HasPrefix(p, "pkg:gem/"):
+			return "gem"
+		case strings.HasPrefix(p, "pkg:deb/"):
+			return "deb"
+		case strings.HasPrefix(p, "pkg:rpm/"):
+			return "rpm"
 		}
 	}
 
@@ -125,6 +141,10 @@ func detectEcosystem(comp map[string]interface{}) string {
 					return "golang"
 				case "java":
 					return "maven"
+				case "rust":
+					return "cargo"
+				case "ruby":
+					return "gem"
 				}
 			}
 		}
@@ -145,6 +165,10 @@ func detectEcosystem(comp map[string]interface{}) string {
 					return "golang"
 				case "maven":
 					return "maven"
+				case "rust-crate":
+					return "cargo"
+				case "gem":
+					return "gem"
 				}
 			}
 		}
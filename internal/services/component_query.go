@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"myesi-sbom-service-golang/internal/versioning"
+)
+
+// Dependency relationships reported for a component occurrence.
+const (
+	RelationshipDirect     = "direct"
+	RelationshipTransitive = "transitive"
+	RelationshipUnknown    = "unknown"
+)
+
+// ComponentQuery filters components across an organization's SBOMs.
+type ComponentQuery struct {
+	Name         string
+	Ecosystem    string
+	VersionRange string
+	Project      string
+	Labels       LabelSelector
+	Limit        int
+}
+
+// ComponentOccurrence is one component found in one SBOM.
+type ComponentOccurrence struct {
+	SbomID       string `json:"sbom_id"`
+	ProjectID    int    `json:"project_id,omitempty"`
+	ProjectName  string `json:"project_name"`
+	ManifestName string `json:"manifest_name"`
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	Ecosystem    string `json:"ecosystem"`
+	Purl         string `json:"purl,omitempty"`
+	Relationship string `json:"relationship,omitempty"`
+}
+
+// ComponentFilter decides whether an SBOM component matches a search by name
+// or purl, ecosystem and version range.
+type ComponentFilter struct {
+	name      string
+	ecosystem string
+	purl      *PackageURL
+	rangeExpr string
+	ranges    map[string]versioning.Range
+}
+
+// NewComponentFilter validates the search inputs. Either name or purl must be
+// set; a purl carrying a version narrows the match to that exact version when
+// no explicit range is given.
+func NewComponentFilter(name, purl, ecosystem, versionRange string) (*ComponentFilter, error) {
+	f := &ComponentFilter{
+		name:      strings.TrimSpace(name),
+		ecosystem: strings.ToLower(strings.TrimSpace(ecosystem)),
+		rangeExpr: strings.TrimSpace(versionRange),
+		ranges:    map[string]versioning.Range{},
+	}
+
+	if purl = strings.TrimSpace(purl); purl != "" {
+		p, ok := ParsePackageURL(purl)
+		if !ok {
+			return nil, fmt.Errorf("invalid purl %q", purl)
+		}
+		f.purl = &p
+		f.name = purlComponentName(p)
+		if f.ecosystem == "" {
+			f.ecosystem = detectEcosystem(map[string]interface{}{"purl": purl})
+			if f.ecosystem == "unknown" {
+				f.ecosystem = ""
+			}
+		}
+		if f.rangeExpr == "" && p.Version != "" {
+			f.rangeExpr = "=" + p.Version
+		}
+	}
+	if f.name == "" {
+		return nil, fmt.Errorf("component name or purl required")
+	}
+	if _, err := versioning.ParseRange(f.ecosystem, f.rangeExpr); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// SearchName is the bare package name to pre-filter on in SQL.
+func (f *ComponentFilter) SearchName() string {
+	return f.name
+}
+
+// Match converts a raw CycloneDX/Syft component into an occurrence when it
+// satisfies the filter.
+func (f *ComponentFilter) Match(comp map[string]interface{}) (ComponentOccurrence, bool) {
+	var occ ComponentOccurrence
+	occ.Name, _ = comp["name"].(string)
+	occ.Version, _ = comp["version"].(string)
+	occ.Purl, _ = comp["purl"].(string)
+	occ.Ecosystem = detectEcosystem(comp)
+
+	if occ.Version == "" || !strings.EqualFold(occ.Name, f.name) {
+		return occ, false
+	}
+	if f.ecosystem != "" && !strings.EqualFold(f.ecosystem, occ.Ecosystem) {
+		return occ, false
+	}
+	if f.purl != nil {
+		p, ok := ParsePackageURL(occ.Purl)
+		if !ok || !samePurlPackage(*f.purl, p) {
+			return occ, false
+		}
+	}
+
+	eco := f.ecosystem
+	if eco == "" {
+		eco = occ.Ecosystem
+	}
+	r, ok := f.ranges[eco]
+	if !ok {
+		r, _ = versioning.ParseRange(eco, f.rangeExpr)
+		f.ranges[eco] = r
+	}
+	return occ, r.Contains(occ.Version)
+}
+
+// componentNamespaceSQL is the namespace of component c: its CycloneDX group,
+// else the namespace of its purl (Maven group, npm scope, ...).
+const componentNamespaceSQL = `COALESCE(NULLIF(c->>'group', ''),
+	replace(substring(split_part(split_part(c->>'purl', '#', 1), '?', 1) from '^pkg:[^/]+/(.+)/[^/]+$'), '%40', '@'))`
+
+// ComponentFullNameSQL names component c the way PackageURL.FullName does:
+// "group:artifact" for Maven, "namespace/name" otherwise. Names that already
+// carry their namespace are kept as they are.
+const ComponentFullNameSQL = `(CASE
+	WHEN ` + componentNamespaceSQL + ` IS NULL OR c->>'name' LIKE '%/%' OR c->>'name' LIKE '%:%' THEN c->>'name'
+	WHEN c->>'purl' LIKE 'pkg:maven/%' THEN ` + componentNamespaceSQL + ` || ':' || (c->>'name')
+	ELSE ` + componentNamespaceSQL + ` || '/' || (c->>'name') END)`
+
+// purlComponentName is the name SBOM generators give the component for p:
+// the scoped or namespaced name for npm, Go or Composer, the bare artifact
+// for Maven and OS packages.
+func purlComponentName(p PackageURL) string {
+	switch p.Type {
+	case "maven", "deb", "rpm", "apk":
+		return p.Name
+	}
+	return p.FullName()
+}
+
+func samePurlPackage(a, b PackageURL) bool {
+	normType := func(t string) string {
+		if t == "go" {
+			return "golang"
+		}
+		return t
+	}
+	return normType(a.Type) == normType(b.Type) && strings.EqualFold(a.FullName(), b.FullName())
+}
+
+// ComponentRelationship classifies a component against the dependsOn list of
+// the SBOM's root component. Without a dependency graph the answer is unknown.
+func ComponentRelationship(comp map[string]interface{}, rootDeps []string, hasGraph bool) string {
+	if !hasGraph {
+		return RelationshipUnknown
+	}
+	ref, _ := comp["bom-ref"].(string)
+	if ref == "" {
+		return RelationshipUnknown
+	}
+	for _, d := range rootDeps {
+		if d == ref {
+			return RelationshipDirect
+		}
+	}
+	return RelationshipTransitive
+}
+
+// OrgSBOMFilterSQL keeps sboms s to the organization bound as $1.
+const OrgSBOMFilterSQL = `
+        EXISTS (
+            SELECT 1 FROM projects p
+            WHERE p.id = s.project_id
+              AND p.organization_id = $1
+        )
+    `
+
+// orgSBOMFilterWhere is OrgSBOMFilterSQL for sqlboiler queries on sboms,
+// with the organization bound as ?.
+var orgSBOMFilterWhere = strings.NewReplacer("s.project_id", "sboms.project_id", "$1", "?").Replace(OrgSBOMFilterSQL)
+
+// FindComponents returns the components named q.Name in the organization's
+// SBOMs whose version lies in q.VersionRange, compared with the ordering of
+// each component's ecosystem.
+func FindComponents(ctx context.Context, db *sql.DB, orgID int, q ComponentQuery) ([]ComponentOccurrence, error) {
+	filter, err := NewComponentFilter(q.Name, "", q.Ecosystem, q.VersionRange)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT s.id, s.project_name, COALESCE(s.manifest_name, ''), c
+		FROM sboms s
+		CROSS JOIN LATERAL jsonb_array_elements(COALESCE(s.sbom->'components', '[]'::jsonb)) c
+		WHERE ` + OrgSBOMFilterSQL + `
+		  AND LOWER(c->>'name') = LOWER($2)
+	`
+	args := []interface{}{orgID, filter.SearchName()}
+	bind := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if q.Project != "" {
+		query += ` AND s.project_name = ` + bind(q.Project)
+	}
+	if len(q.Labels) > 0 {
+		query += ` AND ` + q.Labels.SQL(SBOMLabelValue("s.id", "s.project_id"), bind)
+	}
+	query += ` ORDER BY s.project_name, s.manifest_name`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []ComponentOccurrence
+	for rows.Next() {
+		var sbomID, projectName, manifestName string
+		var raw []byte
+		if err := rows.Scan(&sbomID, &projectName, &manifestName, &raw); err != nil {
+			return nil, err
+		}
+		var comp map[string]interface{}
+		if err := json.Unmarshal(raw, &comp); err != nil {
+			continue
+		}
+		occ, ok := filter.Match(comp)
+		if !ok {
+			continue
+		}
+		occ.SbomID, occ.ProjectName, occ.ManifestName = sbomID, projectName, manifestName
+
+		result = append(result, occ)
+		if q.Limit > 0 && len(result) >= q.Limit {
+			break
+		}
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentFilter_PurlNames(t *testing.T) {
+	f, err := NewComponentFilter("", "pkg:npm/%40babel/core@7.22.0", "", "")
+	require.NoError(t, err)
+	require.Equal(t, "@babel/core", f.SearchName())
+	_, ok := f.Match(map[string]interface{}{
+		"name": "@babel/core", "version": "7.22.0", "purl": "pkg:npm/%40babel/core@7.22.0",
+	})
+	require.True(t, ok)
+
+	f, err = NewComponentFilter("", "pkg:maven/org.apache.logging.log4j/log4j-core", "", "<2.15.0")
+	require.NoError(t, err)
+	require.Equal(t, "log4j-core", f.SearchName())
+	_, ok = f.Match(map[string]interface{}{
+		"name": "log4j-core", "version": "2.14.1", "purl": "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1",
+	})
+	require.True(t, ok)
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"math"
+	"strings"
+)
+
+// cvssV3BaseScore computes the CVSS v3.x base score of a vector string such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H". The boolean is false when the
+// vector is not a complete v3 base vector.
+func cvssV3BaseScore(vector string) (float64, bool) {
+	vector = strings.TrimSpace(vector)
+	if !strings.HasPrefix(vector, "CVSS:3.") {
+		return 0, false
+	}
+
+	metrics := map[string]string{}
+	for _, part := range strings.Split(vector, "/")[1:] {
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) == 2 {
+			metrics[kv[0]] = kv[1]
+		}
+	}
+
+	scopeChanged := metrics["S"] == "C"
+	av, ok1 := map[string]float64{"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2}[metrics["AV"]]
+	ac, ok2 := map[string]float64{"L": 0.77, "H": 0.44}[metrics["AC"]]
+	ui, ok3 := map[string]float64{"N": 0.85, "R": 0.62}[metrics["UI"]]
+	prWeights := map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}
+	if scopeChanged {
+		prWeights = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+	}
+	pr, ok4 := prWeights[metrics["PR"]]
+	cia := map[string]float64{"H": 0.56, "L": 0.22, "N": 0}
+	c, ok5 := cia[metrics["C"]]
+	i, ok6 := cia[metrics["I"]]
+	a, ok7 := cia[metrics["A"]]
+	if !(ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7) || (metrics["S"] != "U" && !scopeChanged) {
+		return 0, false
+	}
+
+	iss := 1 - (1-c)*(1-i)*(1-a)
+	var impact float64
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	exploitability := 8.22 * av * ac * pr * ui
+
+	if impact <= 0 {
+		return 0, true
+	}
+	if scopeChanged {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// cvssRoundUp implements the Roundup function from the CVSS v3.1 specification.
+func cvssRoundUp(v float64) float64 {
+	intInput := int(math.Round(v * 100000))
+	if intInput%10000 == 0 {
+		return float64(intInput) / 100000
+	}
+	return (math.Floor(float64(intInput)/10000) + 1) / 10
+}
+
+// severityFromCVSS maps a CVSS base score onto the qualitative severity scale.
+func severityFromCVSS(score float64) string {
+	switch {
+	case score >= 9:
+		return "critical"
+	case score >= 7:
+		return "high"
+	case score >= 4:
+		return "medium"
+	case score > 0:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
+// normalizeSeverity folds vendor severity labels (GHSA "MODERATE", etc.) into
+// the lowercase scale stored on vulnerabilities rows.
+func normalizeSeverity(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "critical":
+		return "critical"
+	case "high", "important":
+		return "high"
+	case "moderate", "medium":
+		return "medium"
+	case "low", "negligible":
+		return "low"
+	default:
+		return "unknown"
+	}
+}
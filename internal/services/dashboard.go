@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// Dashboard defaults and bounds.
+const (
+	DefaultDashboardStaleDays = 30
+	maxDashboardStaleDays     = 365
+	DefaultDashboardLimit     = 5
+	maxDashboardLimit         = 20
+)
+
+// DashboardProjectCounts counts the organization's projects by status.
+// Projects being deleted are archived too.
+type DashboardProjectCounts struct {
+	Total               int64 `json:"total"`
+	Active              int64 `json:"active"`
+	Archived            int64 `json:"archived"`
+	Deleting            int64 `json:"deleting"`
+	WithVulnerabilities int64 `json:"with_vulnerabilities"`
+}
+
+// DashboardProject is a project row in dashboard lists.
+type DashboardProject struct {
+	ID                   int        `json:"id"`
+	Name                 string     `json:"name"`
+	RiskScore            float64    `json:"risk_score"`
+	TotalVulnerabilities int        `json:"total_vulnerabilities"`
+	LastSBOMAt           *time.Time `json:"last_sbom_at"`
+}
+
+// DashboardFreshness splits active projects by the age of their newest SBOM.
+type DashboardFreshness struct {
+	StaleDays     int                `json:"stale_days"`
+	Fresh         int64              `json:"fresh"`
+	Stale         int64              `json:"stale"`
+	NeverScanned  int64              `json:"never_scanned"`
+	StaleProjects []DashboardProject `json:"stale_projects"`
+}
+
+// DashboardComponents totals components across current SBOMs. Unique counts
+// each purl (or name@version without one) once.
+type DashboardComponents struct {
+	Total  int64 `json:"total"`
+	Unique int64 `json:"unique"`
+}
+
+// NamedCount is one entry of a ranked breakdown.
+type NamedCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// OrgDashboard is the home page summary of an organization.
+type OrgDashboard struct {
+	OrganizationID   int                    `json:"organization_id"`
+	Projects         DashboardProjectCounts `json:"projects"`
+	Freshness        DashboardFreshness     `json:"freshness"`
+	Components       DashboardComponents    `json:"components"`
+	TopEcosystems    []NamedCount           `json:"top_ecosystems"`
+	LicenseRisk      map[string]int64       `json:"license_risk"`
+	Vulnerabilities  map[string]int64       `json:"vulnerabilities"`
+	RiskiestProjects []DashboardProject     `json:"riskiest_projects"`
+	GeneratedAt      time.Time              `json:"generated_at"`
+}
+
+// DashboardQuery tunes LoadOrgDashboard; zero values take the defaults.
+type DashboardQuery struct {
+	StaleDays int
+	Limit     int
+}
+
+func (q DashboardQuery) normalized() DashboardQuery {
+	if q.StaleDays <= 0 {
+		q.StaleDays = DefaultDashboardStaleDays
+	}
+	if q.StaleDays > maxDashboardStaleDays {
+		q.StaleDays = maxDashboardStaleDays
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultDashboardLimit
+	}
+	if q.Limit > maxDashboardLimit {
+		q.Limit = maxDashboardLimit
+	}
+	return q
+}
+
+// LoadOrgDashboard assembles the organization summary. Ecosystem and license
+// breakdowns read the daily rollups; the rest comes from live tables.
+func LoadOrgDashboard(ctx context.Context, exec boil.ContextExecutor, orgID int, q DashboardQuery) (*OrgDashboard, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	q = q.normalized()
+	out := &OrgDashboard{
+		OrganizationID:   orgID,
+		TopEcosystems:    []NamedCount{},
+		LicenseRisk:      map[string]int64{},
+		Vulnerabilities:  map[string]int64{"critical": 0, "high": 0, "medium": 0, "low": 0, "unknown": 0},
+		RiskiestProjects: []DashboardProject{},
+		GeneratedAt:      time.Now().UTC(),
+	}
+	for _, bucket := range LicenseRiskBuckets {
+		out.LicenseRisk[bucket] = 0
+	}
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"project counts", func() error { return dashboardProjectCounts(ctx, exec, orgID, &out.Projects) }},
+		{"freshness", func() error { return dashboardFreshness(ctx, exec, orgID, q, &out.Freshness) }},
+		{"components", func() error { return dashboardComponents(ctx, exec, orgID, &out.Components) }},
+		{"ecosystems", func() error { return dashboardEcosystems(ctx, exec, orgID, q.Limit, out) }},
+		{"licenses", func() error { return dashboardLicenseRisk(ctx, exec, orgID, out.LicenseRisk) }},
+		{"vulnerabilities", func() error { return dashboardVulnerabilities(ctx, exec, orgID, out.Vulnerabilities) }},
+		{"riskiest projects", func() error { return dashboardRiskiest(ctx, exec, orgID, q.Limit, out) }},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			return nil, fmt.Errorf("dashboard %s: %w", step.name, err)
+		}
+	}
+	return out, nil
+}
+
+func dashboardProjectCounts(ctx context.Context, exec boil.ContextExecutor, orgID int, out *DashboardProjectCounts) error {
+	return exec.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE COALESCE(p.is_archived, FALSE) = FALSE),
+		       COUNT(*) FILTER (WHERE p.is_archived),
+		       COUNT(*) FILTER (WHERE d.status IS NOT NULL AND d.status <> $2),
+		       COUNT(*) FILTER (WHERE COALESCE(p.total_vulnerabilities, 0) > 0)
+		FROM projects p
+		LEFT JOIN project_deletions d ON d.project_id = p.id
+		WHERE p.organization_id = $1
+	`, orgID, ProjectDeletionCompleted).Scan(&out.Total, &out.Active, &out.Archived, &out.Deleting, &out.WithVulnerabilities)
+}
+
+func dashboardFreshness(ctx context.Context, exec boil.ContextExecutor, orgID int, q DashboardQuery, out *DashboardFreshness) error {
+	rows, err := exec.QueryContext(ctx, `
+		SELECT p.id, p.name, COALESCE(p.avg_risk_score, 0)::float8, COALESCE(p.total_vulnerabilities, 0),
+		       MAX(COALESCE(s.updated_at, s.created_at))
+		FROM projects p
+		LEFT JOIN sboms s ON s.project_id = p.id
+		WHERE p.organization_id = $1
+		  AND COALESCE(p.is_archived, FALSE) = FALSE
+		GROUP BY p.id, p.name
+	`, orgID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cutoff := time.Now().Add(-time.Duration(q.StaleDays) * 24 * time.Hour)
+	out.StaleDays = q.StaleDays
+	out.StaleProjects = []DashboardProject{}
+	for rows.Next() {
+		var (
+			p    DashboardProject
+			last sql.NullTime
+		)
+		if err := rows.Scan(&p.ID, &p.Name, &p.RiskScore, &p.TotalVulnerabilities, &last); err != nil {
+			return err
+		}
+		switch {
+		case !last.Valid:
+			out.NeverScanned++
+		case last.Time.Before(cutoff):
+			out.Stale++
+			t := last.Time
+			p.LastSBOMAt = &t
+		default:
+			out.Fresh++
+			continue
+		}
+		out.StaleProjects = append(out.StaleProjects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Never scanned first, then oldest SBOM first.
+	sort.SliceStable(out.StaleProjects, func(i, j int) bool {
+		a, b := out.StaleProjects[i].LastSBOMAt, out.StaleProjects[j].LastSBOMAt
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+	if len(out.StaleProjects) > q.Limit {
+		out.StaleProjects = out.StaleProjects[:q.Limit]
+	}
+	return nil
+}
+
+func dashboardComponents(ctx context.Context, exec boil.ContextExecutor, orgID int, out *DashboardComponents) error {
+	return exec.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+		       COUNT(DISTINCT COALESCE(NULLIF(split_part(c->>'purl', '?', 1), ''),
+		                               LOWER(c->>'name') || '@' || COALESCE(c->>'version', '')))
+		FROM sboms s
+		`+componentsJoinSQL+`
+		WHERE EXISTS (SELECT 1 FROM projects p WHERE p.id = s.project_id AND p.organization_id = $1)
+	`, orgID).Scan(&out.Total, &out.Unique)
+}
+
+func dashboardEcosystems(ctx context.Context, exec boil.ContextExecutor, orgID, limit int, out *OrgDashboard) error {
+	rows, err := exec.QueryContext(ctx, `
+		SELECT ecosystem, SUM(components) AS components
+		FROM sbom_daily_ecosystem_rollups
+		WHERE organization_id = $1
+		GROUP BY ecosystem
+		HAVING SUM(components) > 0
+		ORDER BY components DESC, ecosystem
+		LIMIT $2
+	`, orgID, limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var nc NamedCount
+		if err := rows.Scan(&nc.Name, &nc.Count); err != nil {
+			return err
+		}
+		out.TopEcosystems = append(out.TopEcosystems, nc)
+	}
+	return rows.Err()
+}
+
+func dashboardLicenseRisk(ctx context.Context, exec boil.ContextExecutor, orgID int, buckets map[string]int64) error {
+	rows, err := exec.QueryContext(ctx, `
+		SELECT license, SUM(components)
+		FROM sbom_daily_license_rollups
+		WHERE organization_id = $1
+		GROUP BY license
+		HAVING SUM(components) > 0
+	`, orgID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			license string
+			n       int64
+		)
+		if err := rows.Scan(&license, &n); err != nil {
+			return err
+		}
+		buckets[LicenseRisk(license)] += n
+	}
+	return rows.Err()
+}
+
+// dashboardVulnerabilities counts findings of active projects once per
+// project, component version and advisory, like ScoreFindings does.
+func dashboardVulnerabilities(ctx context.Context, exec boil.ContextExecutor, orgID int, totals map[string]int64) error {
+	rows, err := exec.QueryContext(ctx, `
+		SELECT COALESCE(v.severity, ''),
+		       COUNT(DISTINCT (s.project_id, v.component_name, v.component_version, COALESCE(v.vuln_id, '')))
+		FROM vulnerabilities v
+		JOIN sboms s ON s.id = v.sbom_id
+		JOIN projects p ON p.id = s.project_id
+		WHERE p.organization_id = $1
+		  AND COALESCE(p.is_archived, FALSE) = FALSE
+		GROUP BY 1
+	`, orgID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			severity string
+			n        int64
+		)
+		if err := rows.Scan(&severity, &n); err != nil {
+			return err
+		}
+		totals[normalizeSeverity(severity)] += n
+	}
+	return rows.Err()
+}
+
+func dashboardRiskiest(ctx context.Context, exec boil.ContextExecutor, orgID, limit int, out *OrgDashboard) error {
+	rows, err := exec.QueryContext(ctx, `
+		SELECT p.id, p.name, COALESCE(p.avg_risk_score, 0)::float8, COALESCE(p.total_vulnerabilities, 0),
+		       p.last_sbom_upload
+		FROM projects p
+		WHERE p.organization_id = $1
+		  AND COALESCE(p.is_archived, FALSE) = FALSE
+		  AND (COALESCE(p.avg_risk_score, 0) > 0 OR COALESCE(p.total_vulnerabilities, 0) > 0)
+		ORDER BY COALESCE(p.avg_risk_score, 0) DESC, COALESCE(p.total_vulnerabilities, 0) DESC, p.id
+		LIMIT $2
+	`, orgID, limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			p    DashboardProject
+			last sql.NullTime
+		)
+		if err := rows.Scan(&p.ID, &p.Name, &p.RiskScore, &p.TotalVulnerabilities, &last); err != nil {
+			return err
+		}
+		if last.Valid {
+			t := last.Time
+			p.LastSBOMAt = &t
+		}
+		out.RiskiestProjects = append(out.RiskiestProjects, p)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+const (
+	idempotencyInProgress = "in_progress"
+	idempotencyCompleted  = "completed"
+
+	// IdempotencyKeyTTL is how long a completed response is replayed.
+	IdempotencyKeyTTL = 24 * time.Hour
+	// idempotencyLockTTL frees keys whose request died without finishing.
+	idempotencyLockTTL = 10 * time.Minute
+)
+
+var (
+	// ErrIdempotencyKeyReused means the key was first used with another request body.
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+	// ErrIdempotencyInProgress means the first request with the key has not finished yet.
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is still in progress")
+)
+
+// IdempotentResponse is the response cached for a completed request.
+type IdempotentResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// BeginIdempotentRequest claims key for one organization and scope. It
+// returns nil when the caller should run the request and CompleteIdempotentRequest
+// (or ReleaseIdempotencyKey) afterwards, or the cached response when the
+// request already completed with the same fingerprint. Expired keys and
+// abandoned claims are taken over.
+func BeginIdempotentRequest(ctx context.Context, exec boil.ContextExecutor, orgID int, scope, key, fingerprint string) (*IdempotentResponse, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+
+	var claimed string
+	err := exec.QueryRowContext(ctx, `
+		INSERT INTO idempotency_keys (organization_id, scope, idempotency_key, fingerprint, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (organization_id, scope, idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    status = EXCLUDED.status,
+		    response_status = NULL,
+		    content_type = NULL,
+		    response_body = NULL,
+		    created_at = NOW(),
+		    completed_at = NULL
+		WHERE idempotency_keys.created_at < NOW() - make_interval(secs => $6)
+		   OR (idempotency_keys.status = $5 AND idempotency_keys.created_at < NOW() - make_interval(secs => $7))
+		RETURNING idempotency_key
+	`, orgID, scope, key, fingerprint, idempotencyInProgress,
+		IdempotencyKeyTTL.Seconds(), idempotencyLockTTL.Seconds()).Scan(&claimed)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	var (
+		stored, status string
+		resp           IdempotentResponse
+		contentType    sql.NullString
+		code           sql.NullInt64
+	)
+	err = exec.QueryRowContext(ctx, `
+		SELECT fingerprint, status, response_status, content_type, response_body
+		FROM idempotency_keys
+		WHERE organization_id = $1 AND scope = $2 AND idempotency_key = $3
+	`, orgID, scope, key).Scan(&stored, &status, &code, &contentType, &resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if stored != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if status != idempotencyCompleted {
+		return nil, ErrIdempotencyInProgress
+	}
+	resp.Status = int(code.Int64)
+	resp.ContentType = contentType.String
+	return &resp, nil
+}
+
+// CompleteIdempotentRequest stores the response replayed for key.
+func CompleteIdempotentRequest(ctx context.Context, exec boil.ContextExecutor, orgID int, scope, key string, resp IdempotentResponse) error {
+	if exec == nil {
+		exec = db.Conn
+	}
+	_, err := exec.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status = $4, response_status = $5, content_type = $6, response_body = $7, completed_at = NOW()
+		WHERE organization_id = $1 AND scope = $2 AND idempotency_key = $3
+	`, orgID, scope, key, idempotencyCompleted, resp.Status, resp.ContentType, resp.Body)
+	return err
+}
+
+// ReleaseIdempotencyKey forgets key so a retry runs the request again. Used
+// when the request failed in a way worth retrying.
+func ReleaseIdempotencyKey(ctx context.Context, exec boil.ContextExecutor, orgID int, scope, key string) error {
+	if exec == nil {
+		exec = db.Conn
+	}
+	_, err := exec.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE organization_id = $1 AND scope = $2 AND idempotency_key = $3 AND status = $4
+	`, orgID, scope, key, idempotencyInProgress)
+	return err
+}
+
+// PurgeExpiredIdempotencyKeys deletes keys older than IdempotencyKeyTTL.
+func PurgeExpiredIdempotencyKeys(ctx context.Context, exec boil.ContextExecutor) (int64, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	res, err := exec.ExecContext(ctx, `
+		DELETE FROM idempotency_keys WHERE created_at < NOW() - make_interval(secs => $1)
+	`, IdempotencyKeyTTL.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// StartIdempotencyKeyPurger deletes expired idempotency keys every interval.
+func StartIdempotencyKeyPurger(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Println("[IDEMPOTENCY] purger stopping")
+				return
+			case <-ticker.C:
+			}
+			n, err := PurgeExpiredIdempotencyKeys(ctx, nil)
+			if err != nil {
+				log.Printf("[IDEMPOTENCY][ERR] purge expired keys: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("[IDEMPOTENCY] purged %d expired keys", n)
+			}
+		}
+	}()
+}
@@ -22,6 +22,7 @@ type SBOMEvent struct {
 	OrganizationID int                 `json:"organization_id,omitempty"`
 	Source         string              `json:"source,omitempty"`
 	Components     []map[string]string `json:"components"`
+	Labels         Labels              `json:"labels,omitempty"`
 }
 
 // QueueSBOMEvent persists an event for async publishing via the outbox.
@@ -41,6 +42,7 @@ func QueueSBOMEvent(ctx context.Context, exec boil.ContextExecutor, sbomID strin
 		OrganizationID: orgID,
 		Source:         source,
 		Components:     comps,
+		Labels:         eventLabels(ctx, exec, projectID, sbomID),
 	}
 
 	err := EnqueueOutboxEvent(ctx, exec, OutboxMessage{
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/lib/pq"
+)
+
+// Labels are free-form key/value pairs attached to projects and SBOMs.
+type Labels map[string]string
+
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+
+const maxLabelValueLen = 255
+
+// ValidateLabels checks keys against the key pattern and rejects values that
+// could not be expressed in a selector.
+func ValidateLabels(labels Labels) error {
+	for k, v := range labels {
+		if !labelKeyPattern.MatchString(k) {
+			return fmt.Errorf("invalid label key %q", k)
+		}
+		if err := validateLabelValue(v); err != nil {
+			return fmt.Errorf("label %q: %w", k, err)
+		}
+	}
+	return nil
+}
+
+func validateLabelValue(v string) error {
+	if len(v) > maxLabelValueLen {
+		return fmt.Errorf("value longer than %d characters", maxLabelValueLen)
+	}
+	if v != strings.TrimSpace(v) || strings.ContainsAny(v, ",=!()") {
+		return fmt.Errorf("value %q contains reserved characters", v)
+	}
+	return nil
+}
+
+// Label selector operators.
+const (
+	LabelOpEquals    = "="
+	LabelOpNotEquals = "!="
+	LabelOpExists    = "exists"
+	LabelOpNotExists = "!exists"
+	LabelOpIn        = "in"
+	LabelOpNotIn     = "notin"
+)
+
+// LabelRequirement is one comma-separated term of a selector.
+type LabelRequirement struct {
+	Key    string
+	Op     string
+	Values []string
+}
+
+// LabelSelector matches when every requirement holds. A missing label
+// satisfies != and notin.
+type LabelSelector []LabelRequirement
+
+// ParseLabelSelector parses selectors such as
+// "team=payments,tier!=internal,env in (prod,staging),!deprecated".
+func ParseLabelSelector(raw string) (LabelSelector, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var sel LabelSelector
+	for _, term := range splitSelector(raw) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return nil, fmt.Errorf("empty selector term in %q", raw)
+		}
+		req, err := parseLabelRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, req)
+	}
+	return sel, nil
+}
+
+// splitSelector splits on commas outside parentheses.
+func splitSelector(raw string) []string {
+	var (
+		terms []string
+		depth int
+		start int
+	)
+	for i, r := range raw {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, raw[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, raw[start:])
+}
+
+func parseLabelRequirement(term string) (LabelRequirement, error) {
+	if strings.HasPrefix(term, "!") {
+		key := strings.TrimSpace(term[1:])
+		if !labelKeyPattern.MatchString(key) {
+			return LabelRequirement{}, fmt.Errorf("invalid label key %q", key)
+		}
+		return LabelRequirement{Key: key, Op: LabelOpNotExists}, nil
+	}
+
+	if i := strings.Index(term, "!="); i >= 0 {
+		return newEqualityRequirement(term[:i], LabelOpNotEquals, term[i+2:])
+	}
+	if i := strings.Index(term, "=="); i >= 0 {
+		return newEqualityRequirement(term[:i], LabelOpEquals, term[i+2:])
+	}
+	if i := strings.Index(term, "="); i >= 0 {
+		return newEqualityRequirement(term[:i], LabelOpEquals, term[i+1:])
+	}
+
+	fields := strings.Fields(term)
+	if len(fields) >= 2 && (fields[1] == LabelOpIn || fields[1] == LabelOpNotIn) {
+		key := fields[0]
+		rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(term[len(key):]), fields[1]))
+		if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+			return LabelRequirement{}, fmt.Errorf("expected a parenthesised value list in %q", term)
+		}
+		if !labelKeyPattern.MatchString(key) {
+			return LabelRequirement{}, fmt.Errorf("invalid label key %q", key)
+		}
+		var values []string
+		for _, v := range strings.Split(rest[1:len(rest)-1], ",") {
+			v = strings.TrimSpace(v)
+			if err := validateLabelValue(v); err != nil {
+				return LabelRequirement{}, err
+			}
+			values = append(values, v)
+		}
+		return LabelRequirement{Key: key, Op: fields[1], Values: values}, nil
+	}
+
+	if !labelKeyPattern.MatchString(term) {
+		return LabelRequirement{}, fmt.Errorf("invalid selector term %q", term)
+	}
+	return LabelRequirement{Key: term, Op: LabelOpExists}, nil
+}
+
+func newEqualityRequirement(key, op, value string) (LabelRequirement, error) {
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !labelKeyPattern.MatchString(key) {
+		return LabelRequirement{}, fmt.Errorf("invalid label key %q", key)
+	}
+	if err := validateLabelValue(value); err != nil {
+		return LabelRequirement{}, err
+	}
+	return LabelRequirement{Key: key, Op: op, Values: []string{value}}, nil
+}
+
+// ProjectLabelValue returns the SQL expression of a project's label value.
+func ProjectLabelValue(projectIDExpr string) func(keyParam string) string {
+	return func(keyParam string) string {
+		return fmt.Sprintf("(SELECT pl.value FROM project_labels pl WHERE pl.project_id = %s AND pl.key = %s)", projectIDExpr, keyParam)
+	}
+}
+
+// SBOMLabelValue returns the SQL expression of an SBOM's effective label
+// value: its own label, else the one inherited from its project.
+func SBOMLabelValue(sbomIDExpr, projectIDExpr string) func(keyParam string) string {
+	return func(keyParam string) string {
+		return fmt.Sprintf(`COALESCE(
+			(SELECT sl.value FROM sbom_labels sl WHERE sl.sbom_id = %s AND sl.key = %s),
+			(SELECT pl.value FROM project_labels pl WHERE pl.project_id = %s AND pl.key = %s))`,
+			sbomIDExpr, keyParam, projectIDExpr, keyParam)
+	}
+}
+
+// SQL renders the selector as a WHERE fragment. valueOf builds the label
+// value expression for a key placeholder and bind registers an argument and
+// returns its placeholder, so callers keep their own parameter style.
+func (s LabelSelector) SQL(valueOf func(keyParam string) string, bind func(v interface{}) string) string {
+	if len(s) == 0 {
+		return "TRUE"
+	}
+	parts := make([]string, 0, len(s))
+	for _, req := range s {
+		expr := valueOf(bind(req.Key))
+		switch req.Op {
+		case LabelOpEquals:
+			parts = append(parts, fmt.Sprintf("%s = %s", expr, bind(req.Values[0])))
+		case LabelOpNotEquals:
+			parts = append(parts, fmt.Sprintf("%s IS DISTINCT FROM %s", expr, bind(req.Values[0])))
+		case LabelOpExists:
+			parts = append(parts, expr+" IS NOT NULL")
+		case LabelOpNotExists:
+			parts = append(parts, expr+" IS NULL")
+		case LabelOpIn:
+			parts = append(parts, fmt.Sprintf("%s = ANY(%s)", expr, bind(pq.Array(req.Values))))
+		case LabelOpNotIn:
+			parts = append(parts, fmt.Sprintf("NOT (COALESCE(%s, '') = ANY(%s))", expr, bind(pq.Array(req.Values))))
+		}
+	}
+	return "(" + strings.Join(parts, " AND ") + ")"
+}
+
+// Label tables, keyed by owner column.
+var (
+	projectLabelTable = labelTable{name: "project_labels", owner: "project_id"}
+	sbomLabelTable    = labelTable{name: "sbom_labels", owner: "sbom_id"}
+)
+
+type labelTable struct {
+	name  string
+	owner string
+}
+
+func (t labelTable) load(ctx context.Context, exec boil.ContextExecutor, owner interface{}) (Labels, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	rows, err := exec.QueryContext(ctx,
+		fmt.Sprintf("SELECT key, value FROM %s WHERE %s = $1 ORDER BY key", t.name, t.owner), owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	labels := Labels{}
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, err
+		}
+		labels[k] = v
+	}
+	return labels, rows.Err()
+}
+
+func (t labelTable) replace(ctx context.Context, exec boil.ContextExecutor, owner interface{}, labels Labels) error {
+	if exec == nil {
+		exec = db.Conn
+	}
+	if _, err := exec.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = $1", t.name, t.owner), owner); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	insert := fmt.Sprintf("INSERT INTO %s (%s, key, value, created_at) VALUES ($1, $2, $3, NOW())", t.name, t.owner)
+	for _, k := range keys {
+		if _, err := exec.ExecContext(ctx, insert, owner, k, labels[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t labelTable) remove(ctx context.Context, exec boil.ContextExecutor, owner interface{}, key string) (bool, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	res, err := exec.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE %s = $1 AND key = $2", t.name, t.owner), owner, key)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// GetProjectLabels returns the labels of a project.
+func GetProjectLabels(ctx context.Context, exec boil.ContextExecutor, projectID int) (Labels, error) {
+	return projectLabelTable.load(ctx, exec, projectID)
+}
+
+// SetProjectLabels replaces the labels of a project.
+func SetProjectLabels(ctx context.Context, exec boil.ContextExecutor, projectID int, labels Labels) error {
+	if err := ValidateLabels(labels); err != nil {
+		return err
+	}
+	return projectLabelTable.replace(ctx, exec, projectID, labels)
+}
+
+// DeleteProjectLabel removes one label of a project.
+func DeleteProjectLabel(ctx context.Context, exec boil.ContextExecutor, projectID int, key string) (bool, error) {
+	return projectLabelTable.remove(ctx, exec, projectID, key)
+}
+
+// GetSBOMLabels returns the labels set on an SBOM itself.
+func GetSBOMLabels(ctx context.Context, exec boil.ContextExecutor, sbomID string) (Labels, error) {
+	return sbomLabelTable.load(ctx, exec, sbomID)
+}
+
+// SetSBOMLabels replaces the labels of an SBOM.
+func SetSBOMLabels(ctx context.Context, exec boil.ContextExecutor, sbomID string, labels Labels) error {
+	if err := ValidateLabels(labels); err != nil {
+		return err
+	}
+	return sbomLabelTable.replace(ctx, exec, sbomID, labels)
+}
+
+// DeleteSBOMLabel removes one label of an SBOM.
+func DeleteSBOMLabel(ctx context.Context, exec boil.ContextExecutor, sbomID, key string) (bool, error) {
+	return sbomLabelTable.remove(ctx, exec, sbomID, key)
+}
+
+// LabelsForProjects loads the labels of several projects at once.
+func LabelsForProjects(ctx context.Context, exec boil.ContextExecutor, projectIDs []int) (map[int]Labels, error) {
+	out := make(map[int]Labels, len(projectIDs))
+	if len(projectIDs) == 0 {
+		return out, nil
+	}
+	if exec == nil {
+		exec = db.Conn
+	}
+	ids := make([]int64, len(projectIDs))
+	for i, id := range projectIDs {
+		ids[i] = int64(id)
+	}
+	rows, err := exec.QueryContext(ctx,
+		`SELECT project_id, key, value FROM project_labels WHERE project_id = ANY($1) ORDER BY project_id, key`,
+		pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id   int
+			k, v string
+		)
+		if err := rows.Scan(&id, &k, &v); err != nil {
+			return nil, err
+		}
+		if out[id] == nil {
+			out[id] = Labels{}
+		}
+		out[id][k] = v
+	}
+	return out, rows.Err()
+}
+
+// EventLabels returns the effective labels for an outbox payload: the
+// project's labels overlaid with the SBOM's own. Lookup failures are logged
+// by callers and must not block the event. Project-level events pass an
+// empty sbomID, which skips sbom_labels: comparing its uuid column with ''
+// would fail and abort the caller's transaction.
+func EventLabels(ctx context.Context, exec boil.ContextExecutor, projectID int, sbomID string) (Labels, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	query := `
+		SELECT key, value, 0 AS precedence FROM project_labels WHERE project_id = $1
+		ORDER BY precedence, key
+	`
+	args := []interface{}{projectID}
+	if sbomID != "" {
+		query = `
+			SELECT key, value, 0 AS precedence FROM project_labels WHERE project_id = $1
+			UNION ALL
+			SELECT key, value, 1 AS precedence FROM sbom_labels WHERE sbom_id = $2
+			ORDER BY precedence, key
+		`
+		args = append(args, sbomID)
+	}
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	labels := Labels{}
+	for rows.Next() {
+		var (
+			k, v       string
+			precedence int
+		)
+		if err := rows.Scan(&k, &v, &precedence); err != nil {
+			return nil, err
+		}
+		labels[k] = v
+	}
+	return labels, rows.Err()
+}
+
+// eventLabels is EventLabels for payload builders: a failed lookup is logged
+// and the event goes out without labels.
+func eventLabels(ctx context.Context, exec boil.ContextExecutor, projectID int, sbomID string) Labels {
+	labels, err := EventLabels(ctx, exec, projectID, sbomID)
+	if err != nil {
+		log.Printf("[LABELS][WARN] load event labels project=%d sbom=%s: %v", projectID, sbomID, err)
+		return nil
+	}
+	return labels
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	sel, err := ParseLabelSelector("team=payments, tier!=internal,env in (prod, staging),owner,!deprecated,zone notin (eu)")
+	require.NoError(t, err)
+	require.Equal(t, LabelSelector{
+		{Key: "team", Op: LabelOpEquals, Values: []string{"payments"}},
+		{Key: "tier", Op: LabelOpNotEquals, Values: []string{"internal"}},
+		{Key: "env", Op: LabelOpIn, Values: []string{"prod", "staging"}},
+		{Key: "owner", Op: LabelOpExists},
+		{Key: "deprecated", Op: LabelOpNotExists},
+		{Key: "zone", Op: LabelOpNotIn, Values: []string{"eu"}},
+	}, sel)
+
+	sel, err = ParseLabelSelector("  ")
+	require.NoError(t, err)
+	require.Empty(t, sel)
+
+	for _, bad := range []string{"=x", "team==a=b", "env in prod", "a,,b", "bad key=x"} {
+		_, err := ParseLabelSelector(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestLabelSelectorSQL(t *testing.T) {
+	sel, err := ParseLabelSelector("team=payments,tier!=internal,!deprecated")
+	require.NoError(t, err)
+
+	var args []interface{}
+	sql := sel.SQL(ProjectLabelValue("p.id"), func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	})
+	require.Equal(t, "("+
+		"(SELECT pl.value FROM project_labels pl WHERE pl.project_id = p.id AND pl.key = $1) = $2 AND "+
+		"(SELECT pl.value FROM project_labels pl WHERE pl.project_id = p.id AND pl.key = $3) IS DISTINCT FROM $4 AND "+
+		"(SELECT pl.value FROM project_labels pl WHERE pl.project_id = p.id AND pl.key = $5) IS NULL)", sql)
+	require.Equal(t, []interface{}{"team", "payments", "tier", "internal", "deprecated"}, args)
+
+	require.Equal(t, "TRUE", LabelSelector(nil).SQL(ProjectLabelValue("p.id"), nil))
+}
+
+func TestValidateLabels(t *testing.T) {
+	require.NoError(t, ValidateLabels(Labels{"team": "payments", "app.kubernetes.io/name": "shop"}))
+	require.Error(t, ValidateLabels(Labels{"-team": "x"}))
+	require.Error(t, ValidateLabels(Labels{"team": "a,b"}))
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"strings"
+)
+
+// License risk buckets, from least to most restrictive. "other" covers
+// recognised-but-unclassified and proprietary licenses that need review.
+const (
+	LicenseRiskPermissive     = "permissive"
+	LicenseRiskWeakCopyleft   = "weak_copyleft"
+	LicenseRiskStrongCopyleft = "strong_copyleft"
+	LicenseRiskOther          = "other"
+	LicenseRiskUnknown        = "unknown"
+)
+
+// LicenseRiskBuckets lists every bucket in display order.
+var LicenseRiskBuckets = []string{
+	LicenseRiskPermissive,
+	LicenseRiskWeakCopyleft,
+	LicenseRiskStrongCopyleft,
+	LicenseRiskOther,
+	LicenseRiskUnknown,
+}
+
+var licenseRiskRank = map[string]int{
+	LicenseRiskPermissive:     0,
+	LicenseRiskWeakCopyleft:   1,
+	LicenseRiskStrongCopyleft: 2,
+	LicenseRiskOther:          3,
+	LicenseRiskUnknown:        4,
+}
+
+// Prefixes are matched against the upper-cased SPDX id or license name;
+// the first match wins, so longer prefixes come first.
+var licenseRiskPrefixes = []struct {
+	prefix string
+	bucket string
+}{
+	{"LGPL", LicenseRiskWeakCopyleft},
+	{"GNU LESSER", LicenseRiskWeakCopyleft},
+	{"GNU LIBRARY", LicenseRiskWeakCopyleft},
+	{"MPL", LicenseRiskWeakCopyleft},
+	{"MOZILLA", LicenseRiskWeakCopyleft},
+	{"EPL", LicenseRiskWeakCopyleft},
+	{"ECLIPSE", LicenseRiskWeakCopyleft},
+	{"CDDL", LicenseRiskWeakCopyleft},
+	{"CPL", LicenseRiskWeakCopyleft},
+	{"CC-BY-SA", LicenseRiskWeakCopyleft},
+	{"AGPL", LicenseRiskStrongCopyleft},
+	{"GNU AFFERO", LicenseRiskStrongCopyleft},
+	{"GPL", LicenseRiskStrongCopyleft},
+	{"GNU GENERAL", LicenseRiskStrongCopyleft},
+	{"SSPL", LicenseRiskStrongCopyleft},
+	{"OSL", LicenseRiskStrongCopyleft},
+	{"EUPL", LicenseRiskStrongCopyleft},
+	{"MIT", LicenseRiskPermissive},
+	{"APACHE", LicenseRiskPermissive},
+	{"BSD", LicenseRiskPermissive},
+	{"0BSD", LicenseRiskPermissive},
+	{"ISC", LicenseRiskPermissive},
+	{"UNLICENSE", LicenseRiskPermissive},
+	{"THE UNLICENSE", LicenseRiskPermissive},
+	{"ZLIB", LicenseRiskPermissive},
+	{"CC0", LicenseRiskPermissive},
+	{"CC-BY-", LicenseRiskPermissive},
+	{"BSL-1.0", LicenseRiskPermissive},
+	{"BOOST", LicenseRiskPermissive},
+	{"PYTHON", LicenseRiskPermissive},
+	{"PSF", LicenseRiskPermissive},
+	{"POSTGRESQL", LicenseRiskPermissive},
+	{"X11", LicenseRiskPermissive},
+	{"WTFPL", LicenseRiskPermissive},
+	{"BLUEOAK", LicenseRiskPermissive},
+	{"ARTISTIC-2.0", LicenseRiskPermissive},
+}
+
+// LicenseRisk classifies an SPDX id, license name or simple SPDX expression.
+// For "A OR B" the least restrictive choice counts, for "A AND B" the most
+// restrictive one.
+func LicenseRisk(license string) string {
+	expr := strings.ToUpper(strings.TrimSpace(strings.NewReplacer("(", " ", ")", " ").Replace(license)))
+	if expr == "" || expr == "UNKNOWN" || expr == "NOASSERTION" || expr == "NONE" {
+		return LicenseRiskUnknown
+	}
+
+	best := ""
+	for _, alt := range strings.Split(expr, " OR ") {
+		worst := ""
+		for _, term := range strings.Split(alt, " AND ") {
+			bucket := classifyLicenseTerm(term)
+			if worst == "" || licenseRiskRank[bucket] > licenseRiskRank[worst] {
+				worst = bucket
+			}
+		}
+		if best == "" || licenseRiskRank[worst] < licenseRiskRank[best] {
+			best = worst
+		}
+	}
+	return best
+}
+
+func classifyLicenseTerm(term string) string {
+	if i := strings.Index(term, " WITH "); i >= 0 {
+		term = term[:i]
+	}
+	term = strings.TrimSpace(term)
+	if term == "" || term == "UNKNOWN" || term == "NOASSERTION" {
+		return LicenseRiskUnknown
+	}
+	for _, p := range licenseRiskPrefixes {
+		if strings.HasPrefix(term, p.prefix) {
+			return p.bucket
+		}
+	}
+	return LicenseRiskOther
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicenseRisk(t *testing.T) {
+	cases := map[string]string{
+		"MIT":                         LicenseRiskPermissive,
+		"Apache License, Version 2.0": LicenseRiskPermissive,
+		"BSD-3-Clause":                LicenseRiskPermissive,
+		"LGPL-2.1-only":               LicenseRiskWeakCopyleft,
+		"MPL-2.0":                     LicenseRiskWeakCopyleft,
+		"GPL-3.0-or-later":            LicenseRiskStrongCopyleft,
+		"AGPL-3.0":                    LicenseRiskStrongCopyleft,
+		"GPL-2.0-only WITH Classpath-exception-2.0": LicenseRiskStrongCopyleft,
+		"MIT OR GPL-3.0":     LicenseRiskPermissive,
+		"(MIT AND LGPL-2.1)": LicenseRiskWeakCopyleft,
+		"Commercial":         LicenseRiskOther,
+		"UNKNOWN":            LicenseRiskUnknown,
+		"":                   LicenseRiskUnknown,
+	}
+	for license, want := range cases {
+		require.Equal(t, want, LicenseRisk(license), license)
+	}
+}
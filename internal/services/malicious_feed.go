@@ -0,0 +1,247 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// MaliciousFeed indexes known-malicious packages from OSV MAL-* advisories and
+// JSON blocklists.
+type MaliciousFeed struct {
+	matcher *OSVMatcher
+	modTime time.Time
+}
+
+// BlocklistEntry is one package of a JSON blocklist file. Without versions
+// every version of the package is considered malicious.
+type BlocklistEntry struct {
+	ID        string   `json:"id"`
+	Ecosystem string   `json:"ecosystem"`
+	Name      string   `json:"name"`
+	Versions  []string `json:"versions"`
+	Reason    string   `json:"reason"`
+}
+
+// MaliciousMatch is a component that appears in the malicious-package feed.
+type MaliciousMatch struct {
+	AdvisoryID       string `json:"advisory_id"`
+	Summary          string `json:"summary,omitempty"`
+	ComponentName    string `json:"component_name"`
+	ComponentVersion string `json:"component_version"`
+	Ecosystem        string `json:"ecosystem"`
+}
+
+var (
+	maliciousFeedMu sync.RWMutex
+	maliciousFeed   *MaliciousFeed
+)
+
+// NewMaliciousFeed returns an empty feed.
+func NewMaliciousFeed() *MaliciousFeed {
+	return &MaliciousFeed{matcher: NewOSVMatcher()}
+}
+
+// LoadMaliciousFeed reads the feed at path (an OSV dump, a JSON blocklist, or a
+// directory/zip of either) and installs it as the process-wide feed. Calling it
+// again re-imports the feed.
+func LoadMaliciousFeed(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("malicious feed path: %w", err)
+	}
+	f := NewMaliciousFeed()
+	if err := walkOSVPath(path, f.Add); err != nil {
+		return err
+	}
+	f.modTime = latestModTime(path, info)
+
+	maliciousFeedMu.Lock()
+	maliciousFeed = f
+	maliciousFeedMu.Unlock()
+	log.Printf("[MAL] loaded %d malicious package entries from %s", f.Entries(), path)
+	return nil
+}
+
+// CurrentMaliciousFeed returns the loaded feed, or nil when checks are disabled.
+func CurrentMaliciousFeed() *MaliciousFeed {
+	maliciousFeedMu.RLock()
+	defer maliciousFeedMu.RUnlock()
+	return maliciousFeed
+}
+
+// Entries reports how many advisories and blocklist entries are indexed.
+func (f *MaliciousFeed) Entries() int {
+	return f.matcher.Advisories()
+}
+
+// Add indexes one JSON document: an OSV advisory (only MAL-* ids are kept), a
+// blocklist array, or an object with a "packages" blocklist array.
+func (f *MaliciousFeed) Add(raw []byte) error {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var entries []BlocklistEntry
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return err
+		}
+		f.addBlocklist(entries)
+		return nil
+	}
+
+	var doc struct {
+		OSVAdvisory
+		Packages []BlocklistEntry `json:"packages"`
+	}
+	if err := json.Unmarshal(trimmed, &doc); err != nil {
+		return err
+	}
+	if len(doc.Packages) > 0 {
+		f.addBlocklist(doc.Packages)
+		return nil
+	}
+	if !strings.HasPrefix(strings.ToUpper(doc.ID), "MAL-") {
+		return nil
+	}
+	adv := doc.OSVAdvisory
+	f.matcher.AddAdvisory(&adv)
+	return nil
+}
+
+func (f *MaliciousFeed) addBlocklist(entries []BlocklistEntry) {
+	for _, e := range entries {
+		name := strings.TrimSpace(e.Name)
+		if name == "" {
+			continue
+		}
+		eco := strings.TrimSpace(e.Ecosystem)
+		if osvEco, ok := osvEcosystems[strings.ToLower(eco)]; ok {
+			eco = osvEco
+		}
+		id := e.ID
+		if id == "" {
+			id = fmt.Sprintf("BLOCK-%s-%s", strings.ToLower(eco), name)
+		}
+
+		var aff OSVAffected
+		aff.Package.Ecosystem = eco
+		aff.Package.Name = name
+		aff.Versions = e.Versions
+		if len(e.Versions) == 0 {
+			aff.Ranges = []OSVRange{{Type: "ECOSYSTEM", Events: []OSVEvent{{Introduced: "0"}}}}
+		}
+		f.matcher.AddAdvisory(&OSVAdvisory{ID: id, Summary: e.Reason, Affected: []OSVAffected{aff}})
+	}
+}
+
+// Check returns the components that appear in the feed.
+func (f *MaliciousFeed) Check(components []map[string]string) []MaliciousMatch {
+	var matches []MaliciousMatch
+	for _, m := range f.matcher.Match(components) {
+		matches = append(matches, MaliciousMatch{
+			AdvisoryID:       m.VulnID,
+			Summary:          m.Summary,
+			ComponentName:    m.ComponentName,
+			ComponentVersion: m.ComponentVersion,
+			Ecosystem:        m.Ecosystem,
+		})
+	}
+	return matches
+}
+
+// CheckMaliciousPackages checks components against the loaded feed. It returns
+// nil when no feed is loaded.
+func CheckMaliciousPackages(components []map[string]string) []MaliciousMatch {
+	f := CurrentMaliciousFeed()
+	if f == nil {
+		return nil
+	}
+	return f.Check(components)
+}
+
+// StartMaliciousFeedRefresher re-imports the feed whenever the files at path
+// change, so operators refresh it by replacing the file.
+func StartMaliciousFeedRefresher(ctx context.Context, path string, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				log.Printf("[MAL][WARN] feed unavailable: %v", err)
+				continue
+			}
+			current := CurrentMaliciousFeed()
+			if current != nil && !latestModTime(path, info).After(current.modTime) {
+				continue
+			}
+			if err := LoadMaliciousFeed(path); err != nil {
+				log.Printf("[MAL][ERR] re-import failed: %v", err)
+			}
+		}
+	}()
+}
+
+func latestModTime(path string, info os.FileInfo) time.Time {
+	latest := info.ModTime()
+	if !info.IsDir() {
+		return latest
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return latest
+	}
+	for _, e := range entries {
+		if fi, err := os.Stat(filepath.Join(path, e.Name())); err == nil && fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest
+}
+
+// RecordMaliciousMatches stores the matches of an upload and raises a
+// high-severity notification. sbomID is empty when the upload was blocked;
+// otherwise earlier matches of the SBOM are replaced.
+func RecordMaliciousMatches(ctx context.Context, exec boil.ContextExecutor, sbomID string, orgID, projectID int, projectName, manifestName string, matches []MaliciousMatch, blocked bool) error {
+	if exec == nil {
+		exec = db.Conn
+	}
+	if sbomID != "" {
+		if _, err := exec.ExecContext(ctx, `DELETE FROM sbom_malicious_matches WHERE sbom_id = $1`, sbomID); err != nil {
+			return fmt.Errorf("clear malicious matches: %w", err)
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	const insert = `
+		INSERT INTO sbom_malicious_matches
+			(sbom_id, organization_id, project_id, manifest_name, advisory_id, component_name,
+			 component_version, ecosystem, summary, blocked, created_at)
+		VALUES (NULLIF($1,'')::uuid,$2,$3,$4,$5,$6,$7,$8,NULLIF($9,''),$10,NOW())
+	`
+	for _, m := range matches {
+		if _, err := exec.ExecContext(ctx, insert, sbomID, orgID, projectID, manifestName, m.AdvisoryID,
+			m.ComponentName, m.ComponentVersion, m.Ecosystem, m.Summary, blocked); err != nil {
+			return fmt.Errorf("store malicious match: %w", err)
+		}
+	}
+
+	return QueueMaliciousPackageAlert(ctx, exec, orgID, projectName, manifestName, matches, blocked)
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+const malAdvisory = `{
+  "id": "MAL-2022-1234",
+  "summary": "Malicious code in event-stream-evil (npm)",
+  "affected": [{
+    "package": {"ecosystem": "npm", "name": "event-stream-evil"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]
+  }]
+}`
+
+const blocklist = `{"packages": [
+  {"ecosystem": "pypi", "name": "colourama", "reason": "credential stealer"},
+  {"ecosystem": "npm", "name": "ua-parser-js", "versions": ["0.7.29"], "reason": "hijacked release"}
+]}`
+
+func TestMaliciousFeed_LoadAndCheck(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "MAL-2022-1234.json"), []byte(malAdvisory), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "GHSA-35jh-r3h4-6jhm.json"), []byte(lodashAdvisory), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "blocklist.json"), []byte(blocklist), 0o644))
+
+	require.NoError(t, LoadMaliciousFeed(dir))
+	t.Cleanup(func() { maliciousFeed = nil })
+	require.Equal(t, 3, CurrentMaliciousFeed().Entries())
+
+	matches := CheckMaliciousPackages([]map[string]string{
+		{"name": "event-stream-evil", "version": "1.0.0", "type": "npm"},
+		{"name": "colourama", "version": "0.1.0", "type": "pypi"},
+		{"name": "ua-parser-js", "version": "0.7.29", "type": "npm"},
+		{"name": "ua-parser-js", "version": "0.7.30", "type": "npm"},
+		{"name": "lodash", "version": "4.17.20", "type": "npm"},
+	})
+	ids := map[string]string{}
+	for _, m := range matches {
+		ids[m.ComponentName+"@"+m.ComponentVersion] = m.AdvisoryID
+	}
+	require.Equal(t, map[string]string{
+		"event-stream-evil@1.0.0": "MAL-2022-1234",
+		"colourama@0.1.0":         "BLOCK-pypi-colourama",
+		"ua-parser-js@0.7.29":     "BLOCK-npm-ua-parser-js",
+	}, ids)
+}
+
+func TestRecordMaliciousMatches_BlockedUploadNotifies(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	mock.ExpectExec(`INSERT INTO sbom_malicious_matches`).
+		WithArgs("", 4, 9, "package.json", "MAL-1", "evil", "1.0.0", "npm", "", true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO outbox_events`).
+		WithArgs(sqlmock.AnyArg(), notificationTopic, "org-4-malicious-shop", "sbom.malicious_package",
+			sqlmock.AnyArg(), sqlmock.AnyArg(), "pending", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = RecordMaliciousMatches(context.Background(), sqlDB, "", 4, 9, "shop", "package.json",
+		[]MaliciousMatch{{AdvisoryID: "MAL-1", ComponentName: "evil", ComponentVersion: "1.0.0", Ecosystem: "npm"}}, true)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -63,3 +63,36 @@ func QueueManualSBOMSummary(ctx context.Context, exec boil.ContextExecutor, orgI
 		Payload:   evt,
 	})
 }
+
+// QueueMaliciousPackageAlert raises a high-severity notification when an SBOM
+// contains packages from the malicious-package feed.
+func QueueMaliciousPackageAlert(ctx context.Context, exec boil.ContextExecutor, orgID int, project, manifest string, matches []MaliciousMatch, blocked bool) error {
+	status := "recorded"
+	if blocked {
+		status = "blocked"
+	}
+	payload := map[string]interface{}{
+		"project":     project,
+		"manifest":    manifest,
+		"packages":    matches,
+		"count":       len(matches),
+		"status":      status,
+		"action_url":  "/developer/sboms",
+		"target_role": "developer",
+	}
+
+	evt := map[string]interface{}{
+		"type":            "sbom.malicious_package",
+		"organization_id": orgID,
+		"severity":        "high",
+		"payload":         payload,
+		"occurred_at":     time.Now().UTC(),
+	}
+
+	return EnqueueOutboxEvent(ctx, exec, OutboxMessage{
+		Topic:     notificationTopic,
+		EventType: "sbom.malicious_package",
+		Key:       fmt.Sprintf("org-%d-malicious-%s", orgID, project),
+		Payload:   evt,
+	})
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// OrgSettings holds per-organization SBOM policy switches.
+type OrgSettings struct {
+	BlockMaliciousPackages bool `json:"block_malicious_packages"`
+	// TimeZone is the IANA zone analytics buckets days in.
+	TimeZone string `json:"time_zone"`
+}
+
+// DefaultOrgTimeZone applies to organizations that never chose a zone.
+const DefaultOrgTimeZone = "UTC"
+
+// Location returns the organization's time zone, falling back to UTC when
+// the stored name is unknown to this host.
+func (s OrgSettings) Location() *time.Location {
+	if loc, err := LoadTimeZone(s.TimeZone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// LoadTimeZone resolves an IANA zone name; empty means UTC.
+func LoadTimeZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	if strings.EqualFold(name, "local") {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+// LoadOrgSettings returns the organization's settings, or the defaults when
+// none were saved.
+func LoadOrgSettings(ctx context.Context, exec boil.ContextExecutor, orgID int) (OrgSettings, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	s := OrgSettings{TimeZone: DefaultOrgTimeZone}
+	err := exec.QueryRowContext(ctx, `
+		SELECT COALESCE(block_malicious_packages, FALSE), COALESCE(NULLIF(time_zone, ''), $2)
+		FROM organization_settings
+		WHERE organization_id = $1
+	`, orgID, DefaultOrgTimeZone).Scan(&s.BlockMaliciousPackages, &s.TimeZone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return OrgSettings{TimeZone: DefaultOrgTimeZone}, nil
+	}
+	return s, err
+}
+
+// SaveOrgSettings upserts the organization's settings.
+func SaveOrgSettings(ctx context.Context, exec boil.ContextExecutor, orgID int, s OrgSettings) error {
+	if exec == nil {
+		exec = db.Conn
+	}
+	_, err := exec.ExecContext(ctx, `
+		INSERT INTO organization_settings (organization_id, block_malicious_packages, time_zone, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (organization_id) DO UPDATE
+		SET block_malicious_packages = EXCLUDED.block_malicious_packages,
+		    time_zone = EXCLUDED.time_zone,
+		    updated_at = NOW()
+	`, orgID, s.BlockMaliciousPackages, s.TimeZone)
+	return err
+}
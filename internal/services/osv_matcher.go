@@ -0,0 +1,483 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"myesi-sbom-service-golang/internal/versioning"
+	"myesi-sbom-service-golang/models"
+
+	null "github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+)
+
+// osvMatcherSource tags vulnerabilities rows written by the offline matcher so
+// a re-match only replaces its own rows, never those of the vuln service.
+const osvMatcherSource = "offline-osv"
+
+// OSVAdvisory is the subset of the OSV schema (https://ossf.github.io/osv-schema/)
+// the offline matcher relies on.
+type OSVAdvisory struct {
+	ID        string   `json:"id"`
+	Aliases   []string `json:"aliases"`
+	Summary   string   `json:"summary"`
+	Published string   `json:"published"`
+	Modified  string   `json:"modified"`
+	Withdrawn string   `json:"withdrawn"`
+	Severity  []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected         []OSVAffected          `json:"affected"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific"`
+}
+
+// OSVAffected describes one affected package of an advisory.
+type OSVAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+		Purl      string `json:"purl"`
+	} `json:"package"`
+	Ranges   []OSVRange `json:"ranges"`
+	Versions []string   `json:"versions"`
+}
+
+// OSVRange is a list of introduced/fixed events over one versioning scheme.
+type OSVRange struct {
+	Type   string     `json:"type"`
+	Events []OSVEvent `json:"events"`
+}
+
+// OSVEvent is a single boundary of an OSVRange.
+type OSVEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// VulnerabilityMatch is one advisory affecting one extracted component.
+type VulnerabilityMatch struct {
+	VulnID           string
+	Aliases          []string
+	Summary          string
+	ComponentName    string
+	ComponentVersion string
+	ComponentPurl    string
+	Ecosystem        string
+	Severity         string
+	CVSSVector       string
+	CVSSScore        float64
+	FixedVersion     string
+}
+
+// OSVMatcher is an in-memory index of OSV advisories keyed by ecosystem and
+// package name.
+type OSVMatcher struct {
+	index map[string][]*OSVAdvisory
+	total int
+}
+
+var (
+	osvMatcherMu sync.RWMutex
+	osvMatcher   *OSVMatcher
+)
+
+// osvEcosystems maps the ecosystem labels produced by ExtractComponents onto
+// OSV ecosystem names.
+var osvEcosystems = map[string]string{
+	"npm":      "npm",
+	"pypi":     "PyPI",
+	"maven":    "Maven",
+	"golang":   "Go",
+	"composer": "Packagist",
+	"nuget":    "NuGet",
+	"cargo":    "crates.io",
+	"gem":      "RubyGems",
+}
+
+// NewOSVMatcher returns an empty matcher.
+func NewOSVMatcher() *OSVMatcher {
+	return &OSVMatcher{index: map[string][]*OSVAdvisory{}}
+}
+
+// LoadOSVDatabase reads the OSV dump at path (a zip, a JSON file or a directory
+// of either) and installs it as the process-wide matcher.
+func LoadOSVDatabase(path string) error {
+	m := NewOSVMatcher()
+	if err := m.LoadPath(path); err != nil {
+		return err
+	}
+	osvMatcherMu.Lock()
+	osvMatcher = m
+	osvMatcherMu.Unlock()
+	log.Printf("[OSV] loaded %d advisories from %s", m.total, path)
+	return nil
+}
+
+// CurrentOSVMatcher returns the loaded matcher, or nil when matching is disabled.
+func CurrentOSVMatcher() *OSVMatcher {
+	osvMatcherMu.RLock()
+	defer osvMatcherMu.RUnlock()
+	return osvMatcher
+}
+
+// Advisories reports how many advisories are indexed.
+func (m *OSVMatcher) Advisories() int {
+	return m.total
+}
+
+// LoadPath indexes every advisory found at path.
+func (m *OSVMatcher) LoadPath(path string) error {
+	return walkOSVPath(path, m.Add)
+}
+
+// walkOSVPath calls add with every JSON document found at path: a JSON file, a
+// zip of JSON files, or a directory holding either.
+func walkOSVPath(path string, add func([]byte) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("osv data path: %w", err)
+	}
+	if !info.IsDir() {
+		return loadOSVFile(path, add)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("read osv dir: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if err := loadOSVFile(filepath.Join(path, e.Name()), add); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadOSVFile(path string, add func([]byte) error) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		return loadOSVZip(path, add)
+	case ".json":
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		// A bad advisory is skipped, as it is inside a zip.
+		if err := add(raw); err != nil {
+			log.Printf("[OSV][WARN] skip %s: %v", path, err)
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func loadOSVZip(path string, add func([]byte) error) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("open osv zip %s: %w", path, err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if !strings.HasSuffix(strings.ToLower(f.Name), ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("open %s in %s: %w", f.Name, path, err)
+		}
+		raw, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("read %s in %s: %w", f.Name, path, err)
+		}
+		if err := add(raw); err != nil {
+			log.Printf("[OSV][WARN] skip %s: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// Add indexes a single OSV JSON advisory. Withdrawn advisories are ignored.
+func (m *OSVMatcher) Add(raw []byte) error {
+	var adv OSVAdvisory
+	if err := json.Unmarshal(raw, &adv); err != nil {
+		return err
+	}
+	m.AddAdvisory(&adv)
+	return nil
+}
+
+// AddAdvisory indexes an already decoded advisory.
+func (m *OSVMatcher) AddAdvisory(adv *OSVAdvisory) {
+	if adv.ID == "" || adv.Withdrawn != "" {
+		return
+	}
+	seen := map[string]struct{}{}
+	for _, aff := range adv.Affected {
+		key := osvIndexKey(aff.Package.Ecosystem, aff.Package.Name)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		m.index[key] = append(m.index[key], adv)
+	}
+	m.total++
+}
+
+// Match returns every advisory affecting the given components (as produced by
+// ExtractComponents). Components of unknown ecosystems are skipped.
+func (m *OSVMatcher) Match(components []map[string]string) []VulnerabilityMatch {
+	var matches []VulnerabilityMatch
+	for _, comp := range components {
+		ecosystem, name := osvPackageFor(comp)
+		version := comp["version"]
+		if ecosystem == "" || name == "" || version == "" {
+			continue
+		}
+
+		seen := map[string]struct{}{}
+		for _, adv := range m.index[osvIndexKey(ecosystem, name)] {
+			if _, dup := seen[adv.ID]; dup {
+				continue
+			}
+			fixed, affected := advisoryAffects(adv, ecosystem, name, version)
+			if !affected {
+				continue
+			}
+			seen[adv.ID] = struct{}{}
+
+			match := VulnerabilityMatch{
+				VulnID:           adv.ID,
+				Aliases:          adv.Aliases,
+				Summary:          adv.Summary,
+				ComponentName:    name,
+				ComponentVersion: version,
+				ComponentPurl:    comp["purl"],
+				Ecosystem:        ecosystem,
+				FixedVersion:     fixed,
+			}
+			match.Severity, match.CVSSVector, match.CVSSScore = advisorySeverity(adv)
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// MatchAndStoreVulnerabilities matches components against the loaded OSV data
+// and replaces the matcher-owned vulnerabilities rows of the SBOM. It returns
+// the number of matches, or 0 when no OSV data is loaded.
+func MatchAndStoreVulnerabilities(ctx context.Context, exec boil.ContextExecutor, sbomID, projectName string, components []map[string]string) (int, error) {
+	m := CurrentOSVMatcher()
+	if m == nil {
+		return 0, nil
+	}
+	matches := m.Match(components)
+	if err := StoreVulnerabilityMatches(ctx, exec, sbomID, projectName, len(components), matches); err != nil {
+		return 0, err
+	}
+	return len(matches), nil
+}
+
+// StoreVulnerabilityMatches replaces the offline matcher's rows for sbomID.
+func StoreVulnerabilityMatches(ctx context.Context, exec boil.ContextExecutor, sbomID, projectName string, componentCount int, matches []VulnerabilityMatch) error {
+	if _, err := models.Vulnerabilities(
+		qm.Where("sbom_id = ?", sbomID),
+		qm.Where("osv_metadata->>'source' = ?", osvMatcherSource),
+	).DeleteAll(ctx, exec); err != nil {
+		return fmt.Errorf("clear previous matches: %w", err)
+	}
+
+	for _, match := range matches {
+		meta := map[string]interface{}{
+			"source":     osvMatcherSource,
+			"id":         match.VulnID,
+			"aliases":    match.Aliases,
+			"summary":    match.Summary,
+			"ecosystem":  match.Ecosystem,
+			"cvss_score": match.CVSSScore,
+		}
+		if match.ComponentPurl != "" {
+			meta["purl"] = match.ComponentPurl
+		}
+		metaJSON, _ := json.Marshal(meta)
+		v := &models.Vulnerability{
+			SbomID:             sbomID,
+			ProjectName:        null.StringFrom(projectName),
+			ComponentName:      match.ComponentName,
+			ComponentVersion:   match.ComponentVersion,
+			FixAvailable:       null.BoolFrom(match.FixedVersion != ""),
+			FixedVersion:       null.NewString(match.FixedVersion, match.FixedVersion != ""),
+			VulnID:             null.StringFrom(match.VulnID),
+			Severity:           null.StringFrom(match.Severity),
+			OsvMetadata:        null.JSONFrom(metaJSON),
+			CVSSVector:         null.NewString(match.CVSSVector, match.CVSSVector != ""),
+			SbomComponentCount: null.IntFrom(componentCount),
+		}
+		if err := v.Insert(ctx, exec, boil.Infer()); err != nil {
+			return fmt.Errorf("insert vulnerability %s: %w", match.VulnID, err)
+		}
+	}
+	return nil
+}
+
+// vulnComponentMatchSQL matches a vulnerabilities row v to SBOM component c.
+// Matcher rows record the purl of the component they were found on and match
+// it exactly; other rows match on the component name.
+const vulnComponentMatchSQL = `(CASE WHEN v.osv_metadata->>'purl' IS NOT NULL
+	THEN v.osv_metadata->>'purl' = c->>'purl'
+	ELSE v.component_name = c->>'name' END)`
+
+// VulnerabilityOfComponent is vulnComponentMatchSQL for rows already loaded.
+func VulnerabilityOfComponent(v *models.Vulnerability, name, purl string) bool {
+	var meta struct {
+		Purl string `json:"purl"`
+	}
+	if v.OsvMetadata.Valid && v.OsvMetadata.Unmarshal(&meta) == nil && meta.Purl != "" {
+		return meta.Purl == purl
+	}
+	return v.ComponentName == name
+}
+
+// osvPackageFor resolves the OSV ecosystem and package name of a component,
+// preferring its purl since CycloneDX names drop Maven groups and npm scopes.
+func osvPackageFor(comp map[string]string) (string, string) {
+	if p, ok := ParsePackageURL(comp["purl"]); ok {
+		eco := p.Type
+		if eco == "golang" || eco == "go" {
+			eco = "golang"
+		}
+		if osvEco, ok := osvEcosystems[eco]; ok {
+			return osvEco, p.FullName()
+		}
+	}
+	return osvEcosystems[comp["type"]], comp["name"]
+}
+
+func osvIndexKey(ecosystem, name string) string {
+	eco := strings.ToLower(ecosystem)
+	if i := strings.Index(eco, ":"); i >= 0 {
+		// "Debian:12" style ecosystems share one index bucket.
+		eco = eco[:i]
+	}
+	if eco == "pypi" {
+		name = pep503Name(name)
+	}
+	return eco + "|" + name
+}
+
+var pep503Separators = regexp.MustCompile(`[-_.]+`)
+
+// pep503Name normalises a PyPI project name per PEP 503: runs of "-", "_"
+// and "." are one separator and case does not matter.
+func pep503Name(name string) string {
+	return pep503Separators.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// advisoryAffects reports whether version is affected, returning the first
+// fixed version of the matching range when known.
+func advisoryAffects(adv *OSVAdvisory, ecosystem, name, version string) (string, bool) {
+	key := osvIndexKey(ecosystem, name)
+	for _, aff := range adv.Affected {
+		if osvIndexKey(aff.Package.Ecosystem, aff.Package.Name) != key {
+			continue
+		}
+		for _, v := range aff.Versions {
+			if v == version {
+				return firstFixed(aff), true
+			}
+		}
+		for _, r := range aff.Ranges {
+			if r.Type == "GIT" {
+				continue
+			}
+			if fixed, ok := rangeAffects(ecosystem, r, version); ok {
+				return fixed, true
+			}
+		}
+	}
+	return "", false
+}
+
+// rangeAffects walks OSV events in order: each "introduced" opens an interval
+// that the next "fixed" (exclusive) or "last_affected" (inclusive) closes.
+// ECOSYSTEM ranges use the ecosystem's own version ordering.
+func rangeAffects(ecosystem string, r OSVRange, version string) (string, bool) {
+	scheme := versioning.SchemeFor(ecosystem)
+	if r.Type == "SEMVER" {
+		scheme = versioning.SchemeSemver
+	}
+	introduced := ""
+	open := false
+	for _, ev := range r.Events {
+		switch {
+		case ev.Introduced != "":
+			introduced = ev.Introduced
+			open = true
+		case ev.Fixed != "" && open:
+			if introducedBy(scheme, introduced, version) && scheme.Compare(version, ev.Fixed) < 0 {
+				return ev.Fixed, true
+			}
+			open = false
+		case ev.LastAffected != "" && open:
+			if introducedBy(scheme, introduced, version) && scheme.Compare(version, ev.LastAffected) <= 0 {
+				return "", true
+			}
+			open = false
+		}
+	}
+	if open && introducedBy(scheme, introduced, version) {
+		return "", true
+	}
+	return "", false
+}
+
+func introducedBy(scheme versioning.Scheme, introduced, version string) bool {
+	return introduced == "0" || scheme.Compare(version, introduced) >= 0
+}
+
+func firstFixed(aff OSVAffected) string {
+	for _, r := range aff.Ranges {
+		for _, ev := range r.Events {
+			if ev.Fixed != "" {
+				return ev.Fixed
+			}
+		}
+	}
+	return ""
+}
+
+// advisorySeverity prefers a computable CVSS v3 score and falls back to the
+// database-specific label (GHSA "severity").
+func advisorySeverity(adv *OSVAdvisory) (string, string, float64) {
+	vector := ""
+	for _, s := range adv.Severity {
+		if strings.HasPrefix(s.Type, "CVSS_V3") {
+			if score, ok := cvssV3BaseScore(s.Score); ok {
+				return severityFromCVSS(score), s.Score, score
+			}
+		}
+		if vector == "" && strings.HasPrefix(s.Type, "CVSS") {
+			vector = s.Score
+		}
+	}
+	if label, ok := adv.DatabaseSpecific["severity"].(string); ok {
+		return normalizeSeverity(label), vector, 0
+	}
+	return "unknown", vector, 0
+}
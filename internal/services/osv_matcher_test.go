@@ -0,0 +1,147 @@
+package services
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"myesi-sbom-service-golang/models"
+
+	null "github.com/aarondl/null/v8"
+	"github.com/stretchr/testify/require"
+)
+
+const lodashAdvisory = `{
+  "id": "GHSA-35jh-r3h4-6jhm",
+  "aliases": ["CVE-2021-23337"],
+  "summary": "Command Injection in lodash",
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}],
+  "affected": [{
+    "package": {"ecosystem": "npm", "name": "lodash"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+  }],
+  "database_specific": {"severity": "HIGH"}
+}`
+
+const log4jAdvisory = `{
+  "id": "GHSA-jfh8-c2jp-5v3q",
+  "aliases": ["CVE-2021-44228"],
+  "affected": [{
+    "package": {"ecosystem": "Maven", "name": "org.apache.logging.log4j:log4j-core"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "2.0-beta9"}, {"fixed": "2.15.0"}]}]
+  }],
+  "database_specific": {"severity": "CRITICAL"}
+}`
+
+func writeOSVZip(t *testing.T, advisories map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "all.zip")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for name, body := range advisories {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+	return path
+}
+
+func TestOSVMatcher_LoadZipAndMatch(t *testing.T) {
+	path := writeOSVZip(t, map[string]string{
+		"GHSA-35jh-r3h4-6jhm.json": lodashAdvisory,
+		"GHSA-jfh8-c2jp-5v3q.json": log4jAdvisory,
+	})
+
+	m := NewOSVMatcher()
+	require.NoError(t, m.LoadPath(path))
+	require.Equal(t, 2, m.Advisories())
+
+	matches := m.Match([]map[string]string{
+		{"name": "lodash", "version": "4.17.20", "type": "npm"},
+		{"name": "lodash", "version": "4.17.21", "type": "npm"},
+		{"name": "log4j-core", "version": "2.14.1", "type": "maven", "purl": "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+		{"name": "log4j-core", "version": "2.14.1", "type": "maven"},
+		{"name": "left-pad", "version": "1.0.0", "type": "unknown"},
+	})
+
+	require.Len(t, matches, 2)
+	require.Equal(t, "GHSA-35jh-r3h4-6jhm", matches[0].VulnID)
+	require.Equal(t, "4.17.21", matches[0].FixedVersion)
+	require.Equal(t, "high", matches[0].Severity)
+	require.InDelta(t, 7.2, matches[0].CVSSScore, 0.001)
+
+	require.Equal(t, "org.apache.logging.log4j:log4j-core", matches[1].ComponentName)
+	require.Equal(t, "critical", matches[1].Severity)
+	require.Equal(t, "2.15.0", matches[1].FixedVersion)
+}
+
+func TestOSVMatcher_LoadDirSkipsBadAdvisory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "GHSA-35jh-r3h4-6jhm.json"), []byte(lodashAdvisory), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"id": `), 0o644))
+
+	m := NewOSVMatcher()
+	require.NoError(t, m.LoadPath(dir))
+	require.Equal(t, 1, m.Advisories())
+}
+
+func TestRangeAffects_LastAffected(t *testing.T) {
+	r := OSVRange{Type: "ECOSYSTEM", Events: []OSVEvent{
+		{Introduced: "1.2.0"},
+		{LastAffected: "1.4.0"},
+	}}
+
+	_, ok := rangeAffects("PyPI", r, "1.4.0")
+	require.True(t, ok)
+	_, ok = rangeAffects("PyPI", r, "1.4.1")
+	require.False(t, ok)
+	_, ok = rangeAffects("PyPI", r, "1.1.9")
+	require.False(t, ok)
+}
+
+func TestCVSSV3BaseScore(t *testing.T) {
+	score, ok := cvssV3BaseScore("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H")
+	require.True(t, ok)
+	require.Equal(t, 10.0, score)
+
+	score, ok = cvssV3BaseScore("CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:L/I:L/A:N")
+	require.True(t, ok)
+	require.Equal(t, 5.4, score)
+
+	_, ok = cvssV3BaseScore("CVSS:4.0/AV:N")
+	require.False(t, ok)
+}
+
+func TestParsePackageURL(t *testing.T) {
+	p, ok := ParsePackageURL("pkg:npm/%40babel/core@7.22.0?arch=x86")
+	require.True(t, ok)
+	require.Equal(t, "npm", p.Type)
+	require.Equal(t, "@babel/core", p.FullName())
+	require.Equal(t, "7.22.0", p.Version)
+	require.Equal(t, "x86", p.Qualifiers["arch"])
+
+	p, ok = ParsePackageURL("pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1")
+	require.True(t, ok)
+	require.Equal(t, "org.apache.logging.log4j:log4j-core", p.FullName())
+
+	_, ok = ParsePackageURL("lodash@4.17.21")
+	require.False(t, ok)
+}
+
+func TestVulnerabilityOfComponent(t *testing.T) {
+	matched := &models.Vulnerability{
+		ComponentName: "@babel/core",
+		OsvMetadata:   null.JSONFrom([]byte(`{"source":"osv","purl":"pkg:npm/%40babel/core@7.0.0"}`)),
+	}
+	require.True(t, VulnerabilityOfComponent(matched, "core", "pkg:npm/%40babel/core@7.0.0"))
+	require.False(t, VulnerabilityOfComponent(matched, "core", "pkg:npm/%40angular/core@7.0.0"))
+
+	reported := &models.Vulnerability{ComponentName: "log4j-core"}
+	require.True(t, VulnerabilityOfComponent(reported, "log4j-core", "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"))
+	require.False(t, VulnerabilityOfComponent(reported, "core", ""))
+}
@@ -148,6 +148,9 @@ func (d *outboxDispatcher) run(ctx context.Context) {
 	defer ticker.Stop()
 
 	for {
+		if _, err := SequenceSBOMChanges(ctx, d.db); err != nil {
+			log.Printf("[OUTBOX][ERR] sequence sbom changes: %v", err)
+		}
 		if err := d.dispatchBatch(ctx); err != nil {
 			log.Printf("[OUTBOX][ERR] dispatch batch failed: %v", err)
 		}
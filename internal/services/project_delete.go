@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	null "github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+const (
+	ProjectDeletionPending   = "pending"
+	ProjectDeletionRunning   = "running"
+	ProjectDeletionFailed    = "failed"
+	ProjectDeletionCompleted = "completed"
+)
+
+var (
+	// ErrProjectOnLegalHold blocks deleting a project that is under legal hold.
+	ErrProjectOnLegalHold = errors.New("project is under legal hold")
+	// ErrProjectDeletionNotFound means no deletion was requested for the project.
+	ErrProjectDeletionNotFound = errors.New("project deletion not found")
+	// ErrProjectDeletionPending blocks changes to a project that is being deleted.
+	ErrProjectDeletionPending = errors.New("project deletion in progress")
+)
+
+// ProjectDeletion tracks a project deletion in project_deletions. Every step
+// is idempotent, so a failed deletion is resumed by running it again.
+type ProjectDeletion struct {
+	ProjectID      int         `json:"project_id"`
+	OrganizationID int         `json:"organization_id"`
+	ProjectName    string      `json:"project_name"`
+	Status         string      `json:"status"`
+	SBOMsDeleted   int         `json:"sboms_deleted"`
+	ObjectsDeleted int         `json:"objects_deleted"`
+	Attempts       int         `json:"attempts"`
+	LastError      null.String `json:"last_error,omitempty"`
+	RequestedAt    null.Time   `json:"requested_at"`
+	CompletedAt    null.Time   `json:"completed_at,omitempty"`
+}
+
+// ProjectDeletionImpact previews what deleting a project removes.
+type ProjectDeletionImpact struct {
+	SBOMs           int64 `json:"sboms"`
+	Vulnerabilities int64 `json:"vulnerabilities"`
+	ScanJobs        int64 `json:"scan_jobs"`
+}
+
+// projectSBOMsClause matches SBOMs linked by id, plus legacy rows linked only
+// by name when no other project could claim that name.
+const projectSBOMsClause = `(
+	s.project_id = $1
+	OR (s.project_id IS NULL AND s.project_name = $2
+	    AND NOT EXISTS (SELECT 1 FROM projects o WHERE o.name = $2 AND o.id <> $1))
+)`
+
+// PreviewProjectDeletion counts the rows a deletion would remove.
+func PreviewProjectDeletion(ctx context.Context, exec boil.ContextExecutor, projectID int, projectName string) (ProjectDeletionImpact, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	var out ProjectDeletionImpact
+	err := exec.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM sboms s WHERE `+projectSBOMsClause+`),
+			(SELECT COUNT(*) FROM vulnerabilities v JOIN sboms s ON s.id = v.sbom_id WHERE `+projectSBOMsClause+`),
+			(SELECT COUNT(*) FROM scan_jobs j WHERE j.project_id = $1)
+	`, projectID, projectName).Scan(&out.SBOMs, &out.Vulnerabilities, &out.ScanJobs)
+	return out, err
+}
+
+// StartProjectDeletion records the deletion request and archives the project
+// so it drops out of listings while cleanup runs. Requesting it again resets
+// a failed deletion to pending.
+func StartProjectDeletion(ctx context.Context, conn *sql.DB, projectID, orgID int, projectName string) (*ProjectDeletion, error) {
+	if conn == nil {
+		conn = db.Conn
+	}
+	var held bool
+	if err := conn.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM sbom_legal_holds WHERE organization_id = $1 AND project_id = $2)
+	`, orgID, projectID).Scan(&held); err != nil {
+		return nil, fmt.Errorf("check legal hold: %w", err)
+	}
+	if held {
+		return nil, ErrProjectOnLegalHold
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO project_deletions (project_id, organization_id, project_name, status, requested_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (project_id) DO UPDATE
+		SET status = CASE WHEN project_deletions.status = 'completed' THEN project_deletions.status ELSE EXCLUDED.status END,
+		    last_error = NULL,
+		    updated_at = NOW()
+	`, projectID, orgID, projectName, ProjectDeletionPending); err != nil {
+		return nil, fmt.Errorf("record project deletion: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE projects SET is_archived = TRUE, updated_at = NOW() WHERE id = $1`, projectID); err != nil {
+		return nil, fmt.Errorf("archive project: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return LoadProjectDeletion(ctx, conn, projectID, orgID)
+}
+
+// LoadProjectDeletion returns the deletion state of a project.
+func LoadProjectDeletion(ctx context.Context, exec boil.ContextExecutor, projectID, orgID int) (*ProjectDeletion, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	var d ProjectDeletion
+	err := exec.QueryRowContext(ctx, `
+		SELECT project_id, organization_id, project_name, status, sboms_deleted, objects_deleted,
+		       attempts, last_error, requested_at, completed_at
+		FROM project_deletions
+		WHERE project_id = $1 AND organization_id = $2
+	`, projectID, orgID).Scan(&d.ProjectID, &d.OrganizationID, &d.ProjectName, &d.Status, &d.SBOMsDeleted,
+		&d.ObjectsDeleted, &d.Attempts, &d.LastError, &d.RequestedAt, &d.CompletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrProjectDeletionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// IsProjectDeletionActive reports whether a deletion of the project is under way.
+func IsProjectDeletionActive(ctx context.Context, exec boil.ContextExecutor, projectID int) (bool, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	var active bool
+	err := exec.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM project_deletions WHERE project_id = $1 AND status <> 'completed')
+	`, projectID).Scan(&active)
+	return active, err
+}
+
+// RunProjectDeletion deletes the project's SBOMs (with vulnerabilities, scan
+// jobs and objects), sweeps its storage prefix, removes the project row and
+// emits project.deleted. On failure the state is kept for a later resume.
+func RunProjectDeletion(ctx context.Context, conn *sql.DB, projectID, orgID int) (*ProjectDeletion, error) {
+	if conn == nil {
+		conn = db.Conn
+	}
+	d, err := LoadProjectDeletion(ctx, conn, projectID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == ProjectDeletionCompleted {
+		return d, nil
+	}
+	if _, err := conn.ExecContext(ctx, `
+		UPDATE project_deletions SET status = $2, attempts = attempts + 1, updated_at = NOW() WHERE project_id = $1
+	`, projectID, ProjectDeletionRunning); err != nil {
+		return d, err
+	}
+	d.Status = ProjectDeletionRunning
+	d.Attempts++
+
+	if err := runProjectDeletionSteps(ctx, conn, d); err != nil {
+		d.Status = ProjectDeletionFailed
+		d.LastError = null.StringFrom(err.Error())
+		if _, uerr := conn.ExecContext(ctx, `
+			UPDATE project_deletions
+			SET status = $2, last_error = $3, sboms_deleted = $4, objects_deleted = $5, updated_at = NOW()
+			WHERE project_id = $1
+		`, projectID, ProjectDeletionFailed, err.Error(), d.SBOMsDeleted, d.ObjectsDeleted); uerr != nil {
+			log.Printf("[PROJECT][ERR] project=%d record failure: %v", projectID, uerr)
+		}
+		return d, err
+	}
+	return d, nil
+}
+
+func runProjectDeletionSteps(ctx context.Context, conn *sql.DB, d *ProjectDeletion) error {
+	// DeleteSBOM only accepts SBOMs linked to the organization by project id,
+	// so link the legacy rows this project owns by name first.
+	if _, err := conn.ExecContext(ctx, `UPDATE sboms s SET project_id = $1 WHERE s.project_id IS NULL AND `+projectSBOMsClause,
+		d.ProjectID, d.ProjectName); err != nil {
+		return fmt.Errorf("link project sboms: %w", err)
+	}
+	for {
+		ids, err := queryStrings(ctx, conn, `SELECT s.id FROM sboms s WHERE `+projectSBOMsClause+` LIMIT $3`,
+			d.ProjectID, d.ProjectName, retentionBatchSize)
+		if err != nil {
+			return fmt.Errorf("select project sboms: %w", err)
+		}
+		for _, id := range ids {
+			deleted, err := deleteSBOMInTx(ctx, conn, id, d.OrganizationID)
+			if err != nil {
+				return err
+			}
+			d.SBOMsDeleted++
+			// A failed object delete is retried by the prefix sweep below.
+			if deleted.ObjectURL != "" && deleteSBOMObject(ctx, deleted.ObjectURL) == nil {
+				d.ObjectsDeleted++
+			}
+		}
+		if _, err := conn.ExecContext(ctx, `
+			UPDATE project_deletions SET sboms_deleted = $2, objects_deleted = $3, updated_at = NOW() WHERE project_id = $1
+		`, d.ProjectID, d.SBOMsDeleted, d.ObjectsDeleted); err != nil {
+			return err
+		}
+		if len(ids) < retentionBatchSize {
+			break
+		}
+	}
+
+	// Superseded revisions are no longer referenced by any row.
+	objects, err := listSBOMObjects(ctx, fmt.Sprintf("sbom/org-%d/project-%d/", d.OrganizationID, d.ProjectID))
+	if err != nil {
+		return fmt.Errorf("list project objects: %w", err)
+	}
+	if len(objects) > 0 {
+		keys := make([]string, 0, len(objects))
+		for _, obj := range objects {
+			keys = append(keys, obj.Key)
+		}
+		n, err := deleteSBOMObjectKeys(ctx, keys)
+		d.ObjectsDeleted += n
+		if err != nil {
+			return fmt.Errorf("delete project objects: %w", err)
+		}
+	}
+
+	// Read outside the transaction so a failed lookup cannot abort it.
+	labels := eventLabels(ctx, conn, d.ProjectID, "")
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range []string{
+		`DELETE FROM scan_jobs WHERE project_id = $1`,
+		`DELETE FROM project_labels WHERE project_id = $1`,
+		`DELETE FROM sbom_supply_chain_findings WHERE project_id = $1`,
+		`DELETE FROM sbom_malicious_matches WHERE project_id = $1`,
+		`DELETE FROM projects WHERE id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, d.ProjectID); err != nil {
+			return fmt.Errorf("%s: %w", stmt, err)
+		}
+	}
+	if err := DeleteProjectRollups(ctx, tx, d.ProjectID); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if err := EnqueueOutboxEvent(ctx, tx, OutboxMessage{
+		Topic:     KafkaTopic,
+		EventType: "project.deleted",
+		Key:       fmt.Sprintf("project-%d", d.ProjectID),
+		Payload: map[string]interface{}{
+			"project_id":      d.ProjectID,
+			"project_name":    d.ProjectName,
+			"organization_id": d.OrganizationID,
+			"sboms_deleted":   d.SBOMsDeleted,
+			"labels":          labels,
+			"deleted_at":      now,
+		},
+		DedupKey: fmt.Sprintf("project.deleted:%d", d.ProjectID),
+	}); err != nil {
+		return fmt.Errorf("queue project.deleted: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE project_deletions
+		SET status = $2, sboms_deleted = $3, objects_deleted = $4, last_error = NULL, completed_at = $5, updated_at = NOW()
+		WHERE project_id = $1
+	`, d.ProjectID, ProjectDeletionCompleted, d.SBOMsDeleted, d.ObjectsDeleted, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	d.Status = ProjectDeletionCompleted
+	d.LastError = null.String{}
+	d.CompletedAt = null.TimeFrom(now)
+	return nil
+}
+
+// StartProjectDeletionWorker resumes deletions that failed or were
+// interrupted, once per interval.
+func StartProjectDeletionWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Println("[PROJECT] deletion worker stopping")
+				return
+			case <-ticker.C:
+			}
+			if err := resumeProjectDeletions(ctx, interval); err != nil {
+				log.Printf("[PROJECT][ERR] resume deletions: %v", err)
+			}
+		}
+	}()
+}
+
+func resumeProjectDeletions(ctx context.Context, idle time.Duration) error {
+	rows, err := db.Conn.QueryContext(ctx, `
+		SELECT project_id, organization_id
+		FROM project_deletions
+		WHERE status <> 'completed' AND updated_at < NOW() - make_interval(secs => $1)
+		ORDER BY updated_at
+		LIMIT 20
+	`, idle.Seconds())
+	if err != nil {
+		return err
+	}
+	type pending struct{ projectID, orgID int }
+	var jobs []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.projectID, &p.orgID); err != nil {
+			rows.Close()
+			return err
+		}
+		jobs = append(jobs, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		d, err := RunProjectDeletion(ctx, db.Conn, job.projectID, job.orgID)
+		if err != nil {
+			log.Printf("[PROJECT][ERR] project=%d deletion attempt failed: %v", job.projectID, err)
+			continue
+		}
+		log.Printf("[PROJECT] project=%d deleted sboms=%d objects=%d", job.projectID, d.SBOMsDeleted, d.ObjectsDeleted)
+	}
+	return nil
+}
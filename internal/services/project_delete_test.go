@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func stubProjectStorage(t *testing.T, objects []StoredObject) *[]string {
+	t.Helper()
+	origList, origDeleteKeys, origDelete := listSBOMObjects, deleteSBOMObjectKeys, deleteSBOMObject
+	t.Cleanup(func() {
+		listSBOMObjects, deleteSBOMObjectKeys, deleteSBOMObject = origList, origDeleteKeys, origDelete
+	})
+	var deleted []string
+	listSBOMObjects = func(ctx context.Context, prefix string) ([]StoredObject, error) { return objects, nil }
+	deleteSBOMObjectKeys = func(ctx context.Context, keys []string) (int, error) {
+		deleted = append(deleted, keys...)
+		return len(keys), nil
+	}
+	deleteSBOMObject = func(ctx context.Context, objectURL string) error {
+		deleted = append(deleted, objectKeyFromURL("", objectURL))
+		return nil
+	}
+	return &deleted
+}
+
+func expectProjectDeletionRow(mock sqlmock.Sqlmock, status string) {
+	mock.ExpectQuery(`FROM project_deletions`).
+		WithArgs(9, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"project_id", "organization_id", "project_name", "status", "sboms_deleted",
+			"objects_deleted", "attempts", "last_error", "requested_at", "completed_at"}).
+			AddRow(9, 4, "shop", status, 0, 0, 0, nil, time.Now(), nil))
+}
+
+func TestRunProjectDeletion_Cascades(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	deleted := stubProjectStorage(t, []StoredObject{{Key: "sbom/org-4/project-9/package.json/0-old.json"}})
+
+	expectProjectDeletionRow(mock, ProjectDeletionPending)
+	mock.ExpectExec(`UPDATE project_deletions SET status = \$2, attempts = attempts \+ 1`).
+		WithArgs(9, ProjectDeletionRunning).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE sboms s SET project_id = \$1 WHERE s.project_id IS NULL`).
+		WithArgs(9, "shop").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT s.id FROM sboms s WHERE`).
+		WithArgs(9, "shop", retentionBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("sb-1"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM "sboms"`).WithArgs("sb-1", 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "project_id", "project_name", "manifest_name", "object_url"}).
+			AddRow("sb-1", nil, "shop", "package.json", "s3://bom/sbom/org-4/project-9/package.json/1-x.json"))
+	mock.ExpectQuery(`FROM project_labels`).WillReturnRows(sqlmock.NewRows([]string{"key", "value", "precedence"}))
+	expectSBOMRollup(mock, -1, "sb-1")
+	for range sbomDependentTables {
+		mock.ExpectExec(`DELETE FROM \w+ WHERE sbom_id = \$1`).WithArgs("sb-1").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectExec(`DELETE FROM sboms WHERE id = \$1`).WithArgs("sb-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO outbox_events`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectExec(`UPDATE project_deletions SET sboms_deleted`).
+		WithArgs(9, 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery(`FROM project_labels WHERE project_id = \$1\s+ORDER BY`).WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value", "precedence"}).AddRow("team", "payments", 0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM scan_jobs WHERE project_id`).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM project_labels WHERE project_id`).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM sbom_supply_chain_findings WHERE project_id`).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM sbom_malicious_matches WHERE project_id`).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM projects WHERE id`).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 1))
+	for range rollupTables {
+		mock.ExpectExec(`DELETE FROM sbom_daily_\w*rollups WHERE project_id = \$1`).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectExec(`INSERT INTO outbox_events`).
+		WithArgs(sqlmock.AnyArg(), KafkaTopic, "project-9", "project.deleted", sqlmock.AnyArg(), sqlmock.AnyArg(), "pending", "project.deleted:9").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE project_deletions`).
+		WithArgs(9, ProjectDeletionCompleted, 1, 2, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	d, err := RunProjectDeletion(context.Background(), sqlDB, 9, 4)
+	require.NoError(t, err)
+	require.Equal(t, ProjectDeletionCompleted, d.Status)
+	require.Equal(t, 1, d.SBOMsDeleted)
+	require.Equal(t, 2, d.ObjectsDeleted)
+	require.Equal(t, []string{
+		"sbom/org-4/project-9/package.json/1-x.json",
+		"sbom/org-4/project-9/package.json/0-old.json",
+	}, *deleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunProjectDeletion_RecordsFailure(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	stubProjectStorage(t, nil)
+
+	expectProjectDeletionRow(mock, ProjectDeletionFailed)
+	mock.ExpectExec(`UPDATE project_deletions SET status = \$2, attempts`).
+		WithArgs(9, ProjectDeletionRunning).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE sboms s SET project_id = \$1 WHERE s.project_id IS NULL`).
+		WithArgs(9, "shop").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT s.id FROM sboms s WHERE`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectExec(`UPDATE project_deletions\s+SET status = \$2, last_error = \$3`).
+		WithArgs(9, ProjectDeletionFailed, sqlmock.AnyArg(), 0, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	d, err := RunProjectDeletion(context.Background(), sqlDB, 9, 4)
+	require.Error(t, err)
+	require.Equal(t, ProjectDeletionFailed, d.Status)
+	require.Equal(t, 1, d.Attempts)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// ErrProjectNameTaken is returned when a rename collides with another project
+// of the organization.
+var ErrProjectNameTaken = errors.New("project name already in use")
+
+// ProjectRename reports the rows a rename touched.
+type ProjectRename struct {
+	ProjectID       int    `json:"project_id"`
+	OldName         string `json:"old_name"`
+	NewName         string `json:"new_name"`
+	SBOMs           int64  `json:"sboms"`
+	Vulnerabilities int64  `json:"vulnerabilities"`
+}
+
+// RenameProject renames a project and carries the new name over to its SBOMs
+// and vulnerabilities, then enqueues project.renamed. Legacy SBOMs linked only
+// by name are attached to the project id on the way. Run it in a transaction.
+func RenameProject(ctx context.Context, exec boil.ContextExecutor, projectID, orgID int, oldName, newName string) (*ProjectRename, error) {
+	var taken bool
+	if err := exec.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM projects WHERE organization_id = $1 AND name = $2 AND id <> $3)
+	`, orgID, newName, projectID).Scan(&taken); err != nil {
+		return nil, fmt.Errorf("check project name: %w", err)
+	}
+	if taken {
+		return nil, ErrProjectNameTaken
+	}
+
+	out := &ProjectRename{ProjectID: projectID, OldName: oldName, NewName: newName}
+	if _, err := exec.ExecContext(ctx, `
+		UPDATE projects SET name = $2, updated_at = NOW() WHERE id = $1
+	`, projectID, newName); err != nil {
+		return nil, fmt.Errorf("rename project: %w", err)
+	}
+
+	// sboms.updated_at dates the SBOM content (retention ages rows by it),
+	// so a rename leaves it alone.
+	res, err := exec.ExecContext(ctx, `
+		UPDATE sboms s
+		SET project_name = $3, project_id = $1
+		WHERE `+projectSBOMsClause+`
+	`, projectID, oldName, newName)
+	if err != nil {
+		return nil, fmt.Errorf("rename project sboms: %w", err)
+	}
+	out.SBOMs, _ = res.RowsAffected()
+
+	res, err = exec.ExecContext(ctx, `
+		UPDATE vulnerabilities v
+		SET project_name = $2
+		FROM sboms s
+		WHERE s.id = v.sbom_id AND s.project_id = $1
+		  AND v.project_name IS DISTINCT FROM $2
+	`, projectID, newName)
+	if err != nil {
+		return nil, fmt.Errorf("rename project vulnerabilities: %w", err)
+	}
+	out.Vulnerabilities, _ = res.RowsAffected()
+
+	err = EnqueueOutboxEvent(ctx, exec, OutboxMessage{
+		Topic:     KafkaTopic,
+		EventType: "project.renamed",
+		Key:       fmt.Sprintf("project-%d", projectID),
+		Payload: map[string]interface{}{
+			"project_id":      projectID,
+			"organization_id": orgID,
+			"old_name":        oldName,
+			"new_name":        newName,
+			"labels":          eventLabels(ctx, exec, projectID, ""),
+			"renamed_at":      time.Now().UTC(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("queue project.renamed: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameProject_PropagatesName(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM projects WHERE organization_id = \$1 AND name = \$2 AND id <> \$3\)`).
+		WithArgs(4, "storefront", 9).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(`UPDATE projects SET name = \$2`).WithArgs(9, "storefront").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE sboms s\s+SET project_name = \$3, project_id = \$1`).
+		WithArgs(9, "shop", "storefront").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`UPDATE vulnerabilities v`).WithArgs(9, "storefront").WillReturnResult(sqlmock.NewResult(0, 7))
+	mock.ExpectQuery(`FROM project_labels WHERE project_id = \$1\s+ORDER BY`).WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value", "precedence"}).AddRow("team", "payments", 0))
+	mock.ExpectExec(`INSERT INTO outbox_events`).
+		WithArgs(sqlmock.AnyArg(), KafkaTopic, "project-9", "project.renamed", sqlmock.AnyArg(), sqlmock.AnyArg(), "pending", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	out, err := RenameProject(context.Background(), sqlDB, 9, 4, "shop", "storefront")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, out.SBOMs)
+	require.EqualValues(t, 7, out.Vulnerabilities)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRenameProject_NameTaken(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(4, "billing", 9).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	_, err = RenameProject(context.Background(), sqlDB, 9, 4, "shop", "billing")
+	require.ErrorIs(t, err, ErrProjectNameTaken)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+var copySBOMObject = CopySBOMObject
+
+var (
+	// ErrNotOrgMember is returned when the caller does not belong to one of
+	// the organizations involved.
+	ErrNotOrgMember = errors.New("caller is not a member of both organizations")
+	// ErrNotOrgAdmin is returned when the caller may not move the project out
+	// of its organization.
+	ErrNotOrgAdmin = errors.New("caller is not an admin of the source organization")
+	// ErrSBOMQuotaExceeded is returned when the target organization cannot
+	// take the project's SBOMs.
+	ErrSBOMQuotaExceeded = errors.New("target organization SBOM quota exceeded")
+	// ErrProjectArchived is returned for archived projects, which cannot be
+	// transferred.
+	ErrProjectArchived = errors.New("project is archived")
+)
+
+// Name collision strategies of TransferProject.
+const (
+	TransferConflictFail   = "fail"
+	TransferConflictRename = "rename"
+)
+
+// ProjectTransferRequest moves ProjectID from SourceOrgID to TargetOrgID on
+// behalf of UserID. NewName optionally renames the project on arrival.
+type ProjectTransferRequest struct {
+	ProjectID   int
+	SourceOrgID int
+	TargetOrgID int
+	UserID      int
+	NewName     string
+	OnConflict  string
+}
+
+// ProjectTransfer reports the outcome of TransferProject.
+type ProjectTransfer struct {
+	ProjectID     int    `json:"project_id"`
+	FromOrgID     int    `json:"from_organization_id"`
+	ToOrgID       int    `json:"to_organization_id"`
+	OldName       string `json:"old_name"`
+	NewName       string `json:"new_name"`
+	SBOMs         int64  `json:"sboms"`
+	ObjectsMoved  int    `json:"objects_moved"`
+	RenamedOnMove bool   `json:"renamed_on_move"`
+}
+
+// OrgMemberRole returns the caller's role in an organization and whether the
+// caller is a member at all.
+func OrgMemberRole(ctx context.Context, exec boil.ContextExecutor, userID, orgID int) (string, bool, error) {
+	var role sql.NullString
+	err := exec.QueryRowContext(ctx, `
+		SELECT role FROM organization_members WHERE user_id = $1 AND organization_id = $2 LIMIT 1
+	`, userID, orgID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return role.String, true, nil
+}
+
+func isOrgAdminRole(role string) bool {
+	switch strings.ToLower(strings.TrimSpace(role)) {
+	case "owner", "admin":
+		return true
+	}
+	return false
+}
+
+// TransferProject moves a project with its SBOMs, vulnerabilities, scan jobs
+// and stored objects to another organization. Objects are copied under the
+// target org-<id> prefix before the database switch and the originals are
+// removed once it has committed. Archived projects and projects being
+// deleted are refused.
+//
+// req.UserID is trusted as given: it comes from the X-User-ID header that the
+// API gateway sets after authenticating the caller, and this service must
+// only be reachable through the gateway.
+func TransferProject(ctx context.Context, conn *sql.DB, req ProjectTransferRequest) (*ProjectTransfer, error) {
+	if req.SourceOrgID == req.TargetOrgID {
+		return nil, fmt.Errorf("project already belongs to organization %d", req.TargetOrgID)
+	}
+	srcRole, srcMember, err := OrgMemberRole(ctx, conn, req.UserID, req.SourceOrgID)
+	if err != nil {
+		return nil, err
+	}
+	_, dstMember, err := OrgMemberRole(ctx, conn, req.UserID, req.TargetOrgID)
+	if err != nil {
+		return nil, err
+	}
+	if !srcMember || !dstMember {
+		return nil, ErrNotOrgMember
+	}
+	if !isOrgAdminRole(srcRole) {
+		return nil, ErrNotOrgAdmin
+	}
+
+	var (
+		oldName                  string
+		held, archived, deleting bool
+	)
+	err = conn.QueryRowContext(ctx, `
+		SELECT p.name,
+		       EXISTS (SELECT 1 FROM sbom_legal_holds h WHERE h.project_id = p.id),
+		       COALESCE(p.is_archived, FALSE),
+		       EXISTS (SELECT 1 FROM project_deletions d WHERE d.project_id = p.id AND d.status <> 'completed')
+		FROM projects p
+		WHERE p.id = $1 AND p.organization_id = $2
+	`, req.ProjectID, req.SourceOrgID).Scan(&oldName, &held, &archived, &deleting)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case held:
+		return nil, ErrProjectOnLegalHold
+	case deleting:
+		return nil, ErrProjectDeletionPending
+	case archived:
+		return nil, ErrProjectArchived
+	}
+
+	out := &ProjectTransfer{
+		ProjectID: req.ProjectID,
+		FromOrgID: req.SourceOrgID,
+		ToOrgID:   req.TargetOrgID,
+		OldName:   oldName,
+	}
+	out.NewName, out.RenamedOnMove, err = resolveTransferName(ctx, conn, req, oldName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransferQuota(ctx, conn, req); err != nil {
+		return nil, err
+	}
+
+	srcPrefix := fmt.Sprintf("sbom/org-%d/project-%d/", req.SourceOrgID, req.ProjectID)
+	dstPrefix := fmt.Sprintf("sbom/org-%d/project-%d/", req.TargetOrgID, req.ProjectID)
+	objects, err := listSBOMObjects(ctx, srcPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("list project objects: %w", err)
+	}
+	var copied []string
+	for _, obj := range objects {
+		dst := dstPrefix + strings.TrimPrefix(obj.Key, srcPrefix)
+		if err := copySBOMObject(ctx, obj.Key, dst); err != nil {
+			rollbackCopiedObjects(ctx, copied)
+			return nil, fmt.Errorf("copy %s: %w", obj.Key, err)
+		}
+		copied = append(copied, dst)
+	}
+
+	labels := eventLabels(ctx, conn, req.ProjectID, "")
+	if err := moveProjectRows(ctx, conn, req, out, labels, srcPrefix, dstPrefix); err != nil {
+		rollbackCopiedObjects(ctx, copied)
+		return nil, err
+	}
+
+	if len(objects) > 0 {
+		keys := make([]string, 0, len(objects))
+		for _, obj := range objects {
+			keys = append(keys, obj.Key)
+		}
+		if _, err := deleteSBOMObjectKeys(ctx, keys); err != nil {
+			// The rows already point at the copies; leftovers are only waste.
+			log.Printf("[PROJECT][ERR] project=%d remove source objects: %v", req.ProjectID, err)
+		}
+	}
+	out.ObjectsMoved = len(copied)
+	return out, nil
+}
+
+// resolveTransferName picks the project name in the target organization,
+// suffixing it when OnConflict is "rename" and the name is taken.
+func resolveTransferName(ctx context.Context, exec boil.ContextExecutor, req ProjectTransferRequest, oldName string) (string, bool, error) {
+	name := strings.TrimSpace(req.NewName)
+	if name == "" {
+		name = oldName
+	}
+	candidate := name
+	for i := 2; ; i++ {
+		var taken bool
+		if err := exec.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM projects WHERE organization_id = $1 AND name = $2)
+		`, req.TargetOrgID, candidate).Scan(&taken); err != nil {
+			return "", false, fmt.Errorf("check project name: %w", err)
+		}
+		if !taken {
+			return candidate, candidate != oldName, nil
+		}
+		if req.OnConflict != TransferConflictRename || i > 50 {
+			return "", false, ErrProjectNameTaken
+		}
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+// checkTransferQuota verifies the target plan's SBOM limit can absorb the
+// project's SBOMs. Plans without a limit accept anything.
+func checkTransferQuota(ctx context.Context, exec boil.ContextExecutor, req ProjectTransferRequest) error {
+	var limit sql.NullInt64
+	err := exec.QueryRowContext(ctx, `
+		SELECT sp.sbom_limit
+		FROM organizations o
+		JOIN subscriptions s ON s.id = o.subscription_id
+		JOIN subscription_plans sp ON sp.id = s.plan_id
+		WHERE o.id = $1
+	`, req.TargetOrgID).Scan(&limit)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (!limit.Valid || limit.Int64 < 0)) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load target sbom limit: %w", err)
+	}
+
+	var current, incoming int64
+	if err := exec.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM sboms s JOIN projects p ON p.id = s.project_id WHERE p.organization_id = $1),
+			(SELECT COUNT(*) FROM sboms WHERE project_id = $2)
+	`, req.TargetOrgID, req.ProjectID).Scan(&current, &incoming); err != nil {
+		return fmt.Errorf("count sboms: %w", err)
+	}
+	if current+incoming > limit.Int64 {
+		return ErrSBOMQuotaExceeded
+	}
+	return nil
+}
+
+func moveProjectRows(ctx context.Context, conn *sql.DB, req ProjectTransferRequest, out *ProjectTransfer, labels Labels, srcPrefix, dstPrefix string) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE projects SET organization_id = $2, name = $3, updated_at = NOW() WHERE id = $1
+	`, req.ProjectID, req.TargetOrgID, out.NewName); err != nil {
+		return fmt.Errorf("move project: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE sboms
+		SET project_name = $2,
+		    object_url = replace(object_url, $3, $4)
+		WHERE project_id = $1
+	`, req.ProjectID, out.NewName, "/"+srcPrefix, "/"+dstPrefix)
+	if err != nil {
+		return fmt.Errorf("move sboms: %w", err)
+	}
+	out.SBOMs, _ = res.RowsAffected()
+
+	for _, stmt := range []string{
+		`UPDATE vulnerabilities v SET project_name = $2 FROM sboms s WHERE s.id = v.sbom_id AND s.project_id = $1`,
+		`UPDATE sbom_supply_chain_findings SET organization_id = $3 WHERE project_id = $1`,
+		`UPDATE sbom_malicious_matches SET organization_id = $3 WHERE project_id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, req.ProjectID, out.NewName, req.TargetOrgID); err != nil {
+			return fmt.Errorf("move project rows: %w", err)
+		}
+	}
+	// Days stay bucketed in the source org's zone until the next rebuild.
+	if err := MoveProjectRollups(ctx, tx, req.ProjectID, req.TargetOrgID); err != nil {
+		return err
+	}
+
+	if err := EnqueueOutboxEvent(ctx, tx, OutboxMessage{
+		Topic:     KafkaTopic,
+		EventType: "project.transferred",
+		Key:       fmt.Sprintf("project-%d", req.ProjectID),
+		Payload: map[string]interface{}{
+			"project_id":           req.ProjectID,
+			"from_organization_id": req.SourceOrgID,
+			"to_organization_id":   req.TargetOrgID,
+			"old_name":             out.OldName,
+			"new_name":             out.NewName,
+			"transferred_by":       req.UserID,
+			"labels":               labels,
+			"transferred_at":       time.Now().UTC(),
+		},
+	}); err != nil {
+		return fmt.Errorf("queue project.transferred: %w", err)
+	}
+	return tx.Commit()
+}
+
+func rollbackCopiedObjects(ctx context.Context, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	if _, err := deleteSBOMObjectKeys(ctx, keys); err != nil {
+		log.Printf("[PROJECT][ERR] remove copied objects: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func expectMembership(mock sqlmock.Sqlmock, userID, orgID int, role interface{}) {
+	q := mock.ExpectQuery(`SELECT role FROM organization_members`).WithArgs(userID, orgID)
+	if role == nil {
+		q.WillReturnRows(sqlmock.NewRows([]string{"role"}))
+		return
+	}
+	q.WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(role))
+}
+
+func TestTransferProject_RequiresBothMemberships(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	expectMembership(mock, 11, 3, "admin")
+	expectMembership(mock, 11, 8, nil)
+
+	_, err = TransferProject(context.Background(), sqlDB, ProjectTransferRequest{ProjectID: 9, SourceOrgID: 3, TargetOrgID: 8, UserID: 11})
+	require.ErrorIs(t, err, ErrNotOrgMember)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransferProject_RefusesArchivedAndDeletingProjects(t *testing.T) {
+	for _, tc := range []struct {
+		archived, deleting bool
+		want               error
+	}{
+		{archived: true, want: ErrProjectArchived},
+		{archived: true, deleting: true, want: ErrProjectDeletionPending},
+	} {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+
+		expectMembership(mock, 11, 3, "admin")
+		expectMembership(mock, 11, 8, "member")
+		mock.ExpectQuery(`FROM projects p\s+WHERE p.id = \$1 AND p.organization_id = \$2`).
+			WithArgs(9, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "held", "archived", "deleting"}).AddRow("shop", false, tc.archived, tc.deleting))
+
+		_, err = TransferProject(context.Background(), sqlDB, ProjectTransferRequest{ProjectID: 9, SourceOrgID: 3, TargetOrgID: 8, UserID: 11})
+		require.ErrorIs(t, err, tc.want)
+		require.NoError(t, mock.ExpectationsWereMet())
+		sqlDB.Close()
+	}
+}
+
+func TestTransferProject_RenamesAndRekeysObjects(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	deleted := stubProjectStorage(t, []StoredObject{{Key: "sbom/org-3/project-9/package.json/1-x.json"}})
+	origCopy := copySBOMObject
+	t.Cleanup(func() { copySBOMObject = origCopy })
+	copies := map[string]string{}
+	copySBOMObject = func(ctx context.Context, src, dst string) error {
+		copies[src] = dst
+		return nil
+	}
+
+	expectMembership(mock, 11, 3, "owner")
+	expectMembership(mock, 11, 8, "member")
+	mock.ExpectQuery(`FROM projects p\s+WHERE p.id = \$1 AND p.organization_id = \$2`).
+		WithArgs(9, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "held", "archived", "deleting"}).AddRow("shop", false, false, false))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM projects WHERE organization_id = \$1 AND name = \$2\)`).
+		WithArgs(8, "shop").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(8, "shop-2").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT sp.sbom_limit`).
+		WithArgs(8).WillReturnRows(sqlmock.NewRows([]string{"sbom_limit"}).AddRow(10))
+	mock.ExpectQuery(`SELECT\s+\(SELECT COUNT\(\*\)`).
+		WithArgs(8, 9).WillReturnRows(sqlmock.NewRows([]string{"current", "incoming"}).AddRow(4, 2))
+	mock.ExpectQuery(`FROM project_labels WHERE project_id = \$1\s+ORDER BY`).WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value", "precedence"}))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE projects SET organization_id = \$2, name = \$3`).
+		WithArgs(9, 8, "shop-2").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE sboms`).
+		WithArgs(9, "shop-2", "/sbom/org-3/project-9/", "/sbom/org-8/project-9/").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`UPDATE vulnerabilities`).WithArgs(9, "shop-2", 8).WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectExec(`UPDATE sbom_supply_chain_findings`).WithArgs(9, "shop-2", 8).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE sbom_malicious_matches`).WithArgs(9, "shop-2", 8).WillReturnResult(sqlmock.NewResult(0, 0))
+	for range rollupTables {
+		mock.ExpectExec(`UPDATE sbom_daily_\w*rollups SET organization_id = \$2`).WithArgs(9, 8).WillReturnResult(sqlmock.NewResult(0, 3))
+	}
+	mock.ExpectExec(`INSERT INTO outbox_events`).
+		WithArgs(sqlmock.AnyArg(), KafkaTopic, "project-9", "project.transferred", sqlmock.AnyArg(), sqlmock.AnyArg(), "pending", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	out, err := TransferProject(context.Background(), sqlDB, ProjectTransferRequest{
+		ProjectID: 9, SourceOrgID: 3, TargetOrgID: 8, UserID: 11, OnConflict: TransferConflictRename,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "shop-2", out.NewName)
+	require.True(t, out.RenamedOnMove)
+	require.EqualValues(t, 2, out.SBOMs)
+	require.Equal(t, 1, out.ObjectsMoved)
+	require.Equal(t, map[string]string{
+		"sbom/org-3/project-9/package.json/1-x.json": "sbom/org-8/project-9/package.json/1-x.json",
+	}, copies)
+	require.Equal(t, []string{"sbom/org-3/project-9/package.json/1-x.json"}, *deleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
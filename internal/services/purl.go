@@ -0,0 +1,77 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+)
+
+// PackageURL is a parsed package-url (https://github.com/package-url/purl-spec).
+type PackageURL struct {
+	Type       string
+	Namespace  string
+	Name       string
+	Version    string
+	Qualifiers map[string]string
+}
+
+// ParsePackageURL splits a purl such as "pkg:npm/%40babel/core@7.0.0" into its
+// parts. The subpath is dropped; ok is false for malformed input.
+func ParsePackageURL(raw string) (PackageURL, bool) {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(strings.ToLower(raw), "pkg:") {
+		return PackageURL{}, false
+	}
+	rest := strings.TrimLeft(raw[len("pkg:"):], "/")
+
+	if i := strings.Index(rest, "#"); i >= 0 {
+		rest = rest[:i]
+	}
+	var p PackageURL
+	if i := strings.Index(rest, "?"); i >= 0 {
+		p.Qualifiers = map[string]string{}
+		for _, kv := range strings.Split(rest[i+1:], "&") {
+			k, v, _ := strings.Cut(kv, "=")
+			if k == "" {
+				continue
+			}
+			v, _ = url.QueryUnescape(v)
+			p.Qualifiers[strings.ToLower(k)] = v
+		}
+		rest = rest[:i]
+	}
+	if i := strings.LastIndex(rest, "@"); i >= 0 && i > strings.LastIndex(rest, "/") {
+		p.Version, _ = url.PathUnescape(rest[i+1:])
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(parts) < 2 {
+		return PackageURL{}, false
+	}
+	p.Type = strings.ToLower(parts[0])
+	p.Name, _ = url.PathUnescape(parts[len(parts)-1])
+	if len(parts) > 2 {
+		ns := make([]string, 0, len(parts)-2)
+		for _, seg := range parts[1 : len(parts)-1] {
+			dec, _ := url.PathUnescape(seg)
+			ns = append(ns, dec)
+		}
+		p.Namespace = strings.Join(ns, "/")
+	}
+	if p.Name == "" {
+		return PackageURL{}, false
+	}
+	return p, true
+}
+
+// FullName returns the package name as the ecosystem's registry knows it, e.g.
+// "org.apache.logging.log4j:log4j-core" for Maven or "@babel/core" for npm.
+func (p PackageURL) FullName() string {
+	if p.Namespace == "" {
+		return p.Name
+	}
+	if p.Type == "maven" {
+		return p.Namespace + ":" + p.Name
+	}
+	return p.Namespace + "/" + p.Name
+}
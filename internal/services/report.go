@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// ErrReportNotFound means the project or SBOM is not in the organization.
+var ErrReportNotFound = errors.New("report subject not found")
+
+//go:embed data/report.html.tmpl
+var reportTemplateHTML string
+
+//go:embed data/report.css
+var reportStylesheet string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date": func(v interface{}) string {
+		switch t := v.(type) {
+		case time.Time:
+			return t.UTC().Format("2006-01-02 15:04 UTC")
+		case *time.Time:
+			if t != nil {
+				return t.UTC().Format("2006-01-02 15:04 UTC")
+			}
+		}
+		return "—"
+	},
+	"score": func(f *float64) string { return fmt.Sprintf("%.1f", *f) },
+	"join":  strings.Join,
+}).Parse(reportTemplateHTML))
+
+// ReportQuery selects the subject of a report: a project, or a single SBOM
+// when SbomID is set.
+type ReportQuery struct {
+	OrganizationID int
+	ProjectID      int
+	SbomID         string
+}
+
+// ReportProject is the project metadata shown in the report header.
+type ReportProject struct {
+	ID              int
+	Name            string
+	Description     string
+	SourceType      string
+	RepoURL         string
+	DefaultBranch   string
+	Archived        bool
+	CreatedAt       *time.Time
+	LastSBOMUpload  *time.Time
+	LastVulnScan    *time.Time
+	AvgRiskScore    *float64
+	Labels          Labels
+	Vulnerabilities int
+}
+
+// ReportSBOM is one manifest SBOM with its stored summary.
+type ReportSBOM struct {
+	ID        string
+	Manifest  string
+	Source    string
+	CreatedAt *time.Time
+	Summary   SbomSummary
+}
+
+// ReportLicense is one row of the license breakdown.
+type ReportLicense struct {
+	License    string
+	Risk       string
+	Components int
+}
+
+// ReportVulnerability is one vulnerability finding on a component.
+type ReportVulnerability struct {
+	Manifest     string
+	VulnID       string
+	Severity     string
+	Component    string
+	Version      string
+	FixedVersion string
+}
+
+// Report is everything rendered into the HTML report.
+type Report struct {
+	GeneratedAt     time.Time
+	Project         ReportProject
+	SBOMs           []ReportSBOM
+	Components      []ComponentExportRow
+	Licenses        []ReportLicense
+	Vulnerabilities []ReportVulnerability
+	Severities      []NamedCount
+}
+
+var severityOrder = []string{"critical", "high", "medium", "low", "unknown"}
+
+// BuildReport loads the report data for a project or SBOM of the organization.
+func BuildReport(ctx context.Context, exec boil.ContextExecutor, q ReportQuery) (*Report, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	r := &Report{GeneratedAt: time.Now().UTC()}
+
+	sbomFilter, filterArg := "s.project_id = $2", interface{}(q.ProjectID)
+	if q.SbomID != "" {
+		sbomFilter, filterArg = "s.id = $2", q.SbomID
+	}
+	orgFilter := "EXISTS (SELECT 1 FROM projects p WHERE p.id = s.project_id AND p.organization_id = $1)"
+
+	if err := loadReportSBOMs(ctx, exec, r, orgFilter+" AND "+sbomFilter, q.OrganizationID, filterArg); err != nil {
+		return nil, err
+	}
+	if q.SbomID != "" {
+		if len(r.SBOMs) == 0 {
+			return nil, ErrReportNotFound
+		}
+		if err := exec.QueryRowContext(ctx, `SELECT COALESCE(project_id, 0) FROM sboms WHERE id = $1`,
+			q.SbomID).Scan(&q.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	if err := loadReportProject(ctx, exec, &r.Project, q.ProjectID, q.OrganizationID); err != nil {
+		return nil, err
+	}
+
+	export, err := OpenComponentExport(ctx, exec, ComponentExportScope{
+		OrganizationID: q.OrganizationID, ProjectID: q.ProjectID, SbomID: q.SbomID,
+	}, ExportFormatCSV)
+	if err != nil {
+		return nil, err
+	}
+	licenses := map[string]int{}
+	_, err = export.Each(func(row ComponentExportRow) error {
+		r.Components = append(r.Components, row)
+		if len(row.Licenses) == 0 {
+			licenses["UNKNOWN"]++
+		}
+		for _, l := range row.Licenses {
+			licenses[l]++
+		}
+		return nil
+	})
+	export.Close()
+	if err != nil {
+		return nil, err
+	}
+	for l, n := range licenses {
+		r.Licenses = append(r.Licenses, ReportLicense{License: l, Risk: LicenseRisk(l), Components: n})
+	}
+	sort.Slice(r.Licenses, func(i, j int) bool {
+		if r.Licenses[i].Components != r.Licenses[j].Components {
+			return r.Licenses[i].Components > r.Licenses[j].Components
+		}
+		return r.Licenses[i].License < r.Licenses[j].License
+	})
+
+	if err := loadReportVulnerabilities(ctx, exec, r, orgFilter+" AND "+sbomFilter, q.OrganizationID, filterArg); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func loadReportProject(ctx context.Context, exec boil.ContextExecutor, p *ReportProject, projectID, orgID int) error {
+	var (
+		description, sourceType, repoURL, branch sql.NullString
+		archived                                 sql.NullBool
+		createdAt, lastUpload, lastScan          sql.NullTime
+		risk                                     sql.NullFloat64
+		vulns                                    sql.NullInt64
+	)
+	err := exec.QueryRowContext(ctx, `
+		SELECT id, name, description, source_type, repo_url, github_default_branch, is_archived,
+		       created_at, last_sbom_upload, last_vuln_scan, avg_risk_score, total_vulnerabilities
+		FROM projects
+		WHERE id = $1 AND organization_id = $2
+	`, projectID, orgID).Scan(&p.ID, &p.Name, &description, &sourceType, &repoURL, &branch, &archived,
+		&createdAt, &lastUpload, &lastScan, &risk, &vulns)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrReportNotFound
+	}
+	if err != nil {
+		return err
+	}
+	p.Description, p.SourceType, p.RepoURL, p.DefaultBranch = description.String, sourceType.String, repoURL.String, branch.String
+	p.Archived = archived.Bool
+	p.CreatedAt, p.LastSBOMUpload, p.LastVulnScan = nullTimePtr(createdAt), nullTimePtr(lastUpload), nullTimePtr(lastScan)
+	if risk.Valid {
+		p.AvgRiskScore = &risk.Float64
+	}
+	p.Vulnerabilities = int(vulns.Int64)
+
+	p.Labels, err = GetProjectLabels(ctx, exec, projectID)
+	return err
+}
+
+func loadReportSBOMs(ctx context.Context, exec boil.ContextExecutor, r *Report, where string, args ...interface{}) error {
+	rows, err := exec.QueryContext(ctx, `
+		SELECT s.id, COALESCE(s.manifest_name, ''), s.source, s.summary, s.created_at
+		FROM sboms s
+		WHERE `+where+`
+		ORDER BY s.manifest_name, s.id
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			sb        ReportSBOM
+			summary   []byte
+			createdAt sql.NullTime
+		)
+		if err := rows.Scan(&sb.ID, &sb.Manifest, &sb.Source, &summary, &createdAt); err != nil {
+			return err
+		}
+		if len(summary) > 0 {
+			_ = json.Unmarshal(summary, &sb.Summary)
+		}
+		sort.Strings(sb.Summary.Languages)
+		sort.Strings(sb.Summary.Licenses)
+		sb.CreatedAt = nullTimePtr(createdAt)
+		r.SBOMs = append(r.SBOMs, sb)
+	}
+	return rows.Err()
+}
+
+func loadReportVulnerabilities(ctx context.Context, exec boil.ContextExecutor, r *Report, where string, args ...interface{}) error {
+	rows, err := exec.QueryContext(ctx, `
+		SELECT DISTINCT COALESCE(s.manifest_name, ''), COALESCE(v.vuln_id, ''), COALESCE(v.severity, ''),
+		       v.component_name, v.component_version, COALESCE(v.fixed_version, '')
+		FROM vulnerabilities v
+		JOIN sboms s ON s.id = v.sbom_id
+		WHERE `+where+`
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	counts := map[string]int64{}
+	for rows.Next() {
+		var v ReportVulnerability
+		if err := rows.Scan(&v.Manifest, &v.VulnID, &v.Severity, &v.Component, &v.Version, &v.FixedVersion); err != nil {
+			return err
+		}
+		v.Severity = normalizeSeverity(v.Severity)
+		counts[v.Severity]++
+		r.Vulnerabilities = append(r.Vulnerabilities, v)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rank := map[string]int{}
+	for i, s := range severityOrder {
+		rank[s] = i
+		r.Severities = append(r.Severities, NamedCount{Name: s, Count: counts[s]})
+	}
+	sort.SliceStable(r.Vulnerabilities, func(i, j int) bool {
+		a, b := r.Vulnerabilities[i], r.Vulnerabilities[j]
+		if rank[a.Severity] != rank[b.Severity] {
+			return rank[a.Severity] < rank[b.Severity]
+		}
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		return a.VulnID < b.VulnID
+	})
+	return nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// RenderReport writes the report as a single HTML document with its
+// stylesheet inlined, so it opens offline.
+func RenderReport(w io.Writer, r *Report) error {
+	return reportTemplate.Execute(w, struct {
+		*Report
+		Stylesheet template.CSS
+	}{r, template.CSS(reportStylesheet)})
+}
+
+// ReportFilename names the downloaded report.
+func ReportFilename(name string, generatedAt time.Time) string {
+	return fmt.Sprintf("%s-report-%s.html", sanitizePathSegment(name), generatedAt.UTC().Format("20060102"))
+}
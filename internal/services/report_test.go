@@ -0,0 +1,59 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderReport(t *testing.T) {
+	uploaded := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	score := 6.44
+	r := &Report{
+		GeneratedAt: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+		Project: ReportProject{
+			ID:             3,
+			Name:           "Shop <API>",
+			RepoURL:        "https://github.com/acme/shop",
+			LastSBOMUpload: &uploaded,
+			AvgRiskScore:   &score,
+			Labels:         Labels{"team": "payments"},
+		},
+		SBOMs: []ReportSBOM{{
+			ID: "sb1", Manifest: "package.json", Source: "manual", CreatedAt: &uploaded,
+			Summary: SbomSummary{TotalComponents: 2, Tools: []string{"syft@1.0.0"}, Licenses: []string{"MIT"}},
+		}},
+		Components: []ComponentExportRow{
+			{Manifest: "package.json", Name: "express", Version: "4.18.2", Ecosystem: "npm", Licenses: []string{"MIT"}, Relationship: RelationshipDirect},
+			{Manifest: "package.json", Name: "qs", Version: "6.5.2", Ecosystem: "npm", Licenses: []string{"BSD-3-Clause", "MIT"}, Vulnerabilities: 1},
+		},
+		Licenses:        []ReportLicense{{License: "MIT", Risk: LicenseRiskPermissive, Components: 2}},
+		Vulnerabilities: []ReportVulnerability{{Manifest: "package.json", VulnID: "GHSA-hrpp", Severity: "high", Component: "qs", Version: "6.5.2", FixedVersion: "6.5.3"}},
+		Severities:      []NamedCount{{Name: "critical"}, {Name: "high", Count: 1}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderReport(&buf, r))
+	html := buf.String()
+
+	require.Contains(t, html, "<h1>Shop &lt;API&gt;</h1>")
+	require.Contains(t, html, "generated 2026-03-02 12:00 UTC")
+	require.Contains(t, html, "<dd>6.4</dd>")
+	require.Contains(t, html, `<span class="tag">team=payments</span>`)
+	require.Contains(t, html, `<span class="tag">syft@1.0.0</span>`)
+	require.Contains(t, html, `<td>BSD-3-Clause, MIT</td>`)
+	require.Contains(t, html, `<span class="sev sev-high">high</span>`)
+	require.Contains(t, html, "6.5.3")
+	require.Contains(t, html, ".sev-critical")
+	require.NotContains(t, html, "<link")
+	require.NotContains(t, html, "<script")
+	require.True(t, strings.HasPrefix(html, "<!DOCTYPE html>"))
+}
+
+func TestReportFilename(t *testing.T) {
+	require.Equal(t, "shop-api-report-20260302.html",
+		ReportFilename("Shop API", time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,526 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	null "github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+const (
+	retentionBatchSize  = 100
+	retentionMaxBatches = 10
+)
+
+// Test seams for the storage side of retention.
+var (
+	listSBOMObjects      = ListSBOMObjects
+	deleteSBOMObjectKeys = DeleteSBOMObjectKeys
+	deleteSBOMObject     = DeleteSBOMObject
+)
+
+// RetentionPolicy bounds how many stored revisions of each manifest are kept
+// and for how long; SBOM rows of archived projects also expire by age. A nil
+// rule is not enforced. Organization policies override the plan's.
+type RetentionPolicy struct {
+	KeepRevisions *int   `json:"keep_revisions"`
+	MaxAgeDays    *int   `json:"max_age_days"`
+	LegalHold     bool   `json:"legal_hold"`
+	Source        string `json:"source"`
+}
+
+// Enforced reports whether the policy would purge anything.
+func (p RetentionPolicy) Enforced() bool {
+	return !p.LegalHold && (p.KeepRevisions != nil || p.MaxAgeDays != nil)
+}
+
+// LegalHold exempts a project from retention until it is released.
+type LegalHold struct {
+	ID          int       `json:"id"`
+	ProjectID   int       `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+	Reason      string    `json:"reason"`
+	CreatedAt   null.Time `json:"created_at"`
+}
+
+// RetentionReport lists what one enforcement pass purged, or would purge
+// when DryRun is set.
+type RetentionReport struct {
+	OrganizationID  int             `json:"organization_id"`
+	Policy          RetentionPolicy `json:"policy"`
+	DryRun          bool            `json:"dry_run"`
+	SBOMs           []string        `json:"sboms"`
+	Vulnerabilities int64           `json:"vulnerabilities"`
+	ScanJobs        int64           `json:"scan_jobs"`
+	Objects         []string        `json:"objects"`
+	HeldProjects    int             `json:"held_projects"`
+	StartedAt       time.Time       `json:"started_at"`
+	FinishedAt      time.Time       `json:"finished_at"`
+}
+
+// Purged reports whether the pass removed anything.
+func (r *RetentionReport) Purged() bool {
+	return len(r.SBOMs) > 0 || len(r.Objects) > 0
+}
+
+// LoadRetentionPolicy returns the organization's effective policy: its own
+// row in sbom_retention_policies, else the row for its subscription plan.
+func LoadRetentionPolicy(ctx context.Context, exec boil.ContextExecutor, orgID int) (RetentionPolicy, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	const query = `
+		SELECT rp.keep_revisions, rp.max_age_days, COALESCE(rp.legal_hold, FALSE),
+		       CASE WHEN rp.organization_id IS NULL THEN 'plan' ELSE 'organization' END
+		FROM organizations o
+		LEFT JOIN subscriptions sub ON sub.id = o.subscription_id
+		JOIN sbom_retention_policies rp
+		  ON rp.organization_id = o.id
+		  OR (rp.organization_id IS NULL AND rp.plan_id = sub.plan_id)
+		WHERE o.id = $1
+		ORDER BY rp.organization_id NULLS LAST
+		LIMIT 1
+	`
+	var (
+		p          RetentionPolicy
+		keep, days sql.NullInt64
+	)
+	err := exec.QueryRowContext(ctx, query, orgID).Scan(&keep, &days, &p.LegalHold, &p.Source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return RetentionPolicy{Source: "none"}, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	if keep.Valid {
+		v := int(keep.Int64)
+		p.KeepRevisions = &v
+	}
+	if days.Valid {
+		v := int(days.Int64)
+		p.MaxAgeDays = &v
+	}
+	return p, nil
+}
+
+// SaveOrgRetentionPolicy upserts the organization-level policy.
+func SaveOrgRetentionPolicy(ctx context.Context, exec boil.ContextExecutor, orgID int, p RetentionPolicy) error {
+	if exec == nil {
+		exec = db.Conn
+	}
+	_, err := exec.ExecContext(ctx, `
+		INSERT INTO sbom_retention_policies (organization_id, keep_revisions, max_age_days, legal_hold, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (organization_id) WHERE organization_id IS NOT NULL DO UPDATE
+		SET keep_revisions = EXCLUDED.keep_revisions,
+		    max_age_days = EXCLUDED.max_age_days,
+		    legal_hold = EXCLUDED.legal_hold,
+		    updated_at = NOW()
+	`, orgID, nullableInt(p.KeepRevisions), nullableInt(p.MaxAgeDays), p.LegalHold)
+	return err
+}
+
+// ListLegalHolds returns the organization's project holds.
+func ListLegalHolds(ctx context.Context, exec boil.ContextExecutor, orgID int) ([]LegalHold, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	rows, err := exec.QueryContext(ctx, `
+		SELECT h.id, h.project_id, p.name, COALESCE(h.reason, ''), h.created_at
+		FROM sbom_legal_holds h
+		JOIN projects p ON p.id = h.project_id
+		WHERE h.organization_id = $1
+		ORDER BY h.created_at DESC, h.id DESC
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []LegalHold{}
+	for rows.Next() {
+		var h LegalHold
+		if err := rows.Scan(&h.ID, &h.ProjectID, &h.ProjectName, &h.Reason, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		holds = append(holds, h)
+	}
+	return holds, rows.Err()
+}
+
+// AddLegalHold places a project on hold and returns the hold id.
+func AddLegalHold(ctx context.Context, exec boil.ContextExecutor, orgID, projectID int, reason string) (int, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	var id int
+	err := exec.QueryRowContext(ctx, `
+		INSERT INTO sbom_legal_holds (organization_id, project_id, reason, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), NOW())
+		RETURNING id
+	`, orgID, projectID, reason).Scan(&id)
+	return id, err
+}
+
+// RemoveLegalHold releases a hold. It reports false when the hold does not
+// belong to the organization.
+func RemoveLegalHold(ctx context.Context, exec boil.ContextExecutor, orgID, holdID int) (bool, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	res, err := exec.ExecContext(ctx, `DELETE FROM sbom_legal_holds WHERE id = $1 AND organization_id = $2`, holdID, orgID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// EnforceRetention purges the organization's stored revisions, and SBOM rows
+// of archived projects, that fall outside its policy, skipping projects under
+// legal hold.
+func EnforceRetention(ctx context.Context, conn *sql.DB, orgID int, dryRun bool) (*RetentionReport, error) {
+	if conn == nil {
+		conn = db.Conn
+	}
+	policy, err := LoadRetentionPolicy(ctx, conn, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("load retention policy: %w", err)
+	}
+	report := &RetentionReport{
+		OrganizationID: orgID,
+		Policy:         policy,
+		DryRun:         dryRun,
+		SBOMs:          []string{},
+		Objects:        []string{},
+		StartedAt:      time.Now().UTC(),
+	}
+	defer func() { report.FinishedAt = time.Now().UTC() }()
+	if !policy.Enforced() {
+		return report, nil
+	}
+
+	if err := purgeExpiredSBOMs(ctx, conn, orgID, policy, dryRun, report); err != nil {
+		return report, err
+	}
+	if err := purgeExpiredRevisions(ctx, conn, orgID, policy, dryRun, report); err != nil {
+		return report, err
+	}
+
+	if !dryRun && report.Purged() {
+		report.FinishedAt = time.Now().UTC()
+		if err := EnqueueOutboxEvent(ctx, conn, OutboxMessage{
+			Topic:     KafkaTopic,
+			EventType: "sbom.retention_purged",
+			Key:       fmt.Sprintf("org-%d", orgID),
+			Payload:   report,
+		}); err != nil {
+			log.Printf("[RETENTION][ERR] org=%d queue report: %v", orgID, err)
+		}
+	}
+	return report, nil
+}
+
+// purgeExpiredSBOMs deletes the SBOM rows of archived projects that were
+// not touched within the age limit. A live project keeps exactly one row per
+// manifest (UpsertSBOM overwrites it), so its rows are never purged; the
+// revision and age limits apply to its stored objects instead. Deleted
+// projects are cleaned up by the project deletion worker.
+func purgeExpiredSBOMs(ctx context.Context, conn *sql.DB, orgID int, policy RetentionPolicy, dryRun bool, report *RetentionReport) error {
+	if policy.MaxAgeDays == nil {
+		return nil
+	}
+	const query = `
+		SELECT s.id, COALESCE(s.updated_at, s.created_at) AS touched_at
+		FROM sboms s
+		JOIN projects p ON p.id = s.project_id
+		WHERE p.organization_id = $1
+		  AND COALESCE(p.is_archived, FALSE)
+		  AND COALESCE(s.updated_at, s.created_at) < NOW() - make_interval(days => $2::int)
+		  AND (COALESCE(s.updated_at, s.created_at), s.id::text) > ($3, $4)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM sbom_legal_holds h
+		      WHERE h.organization_id = $1 AND h.project_id = p.id
+		  )
+		  AND NOT EXISTS (
+		      SELECT 1 FROM project_deletions d
+		      WHERE d.project_id = p.id AND d.status <> 'completed'
+		  )
+		ORDER BY touched_at, s.id::text
+		LIMIT $5
+	`
+	// Page by (touched_at, id) so a dry run, which deletes nothing, still
+	// walks every batch.
+	var (
+		afterAt time.Time
+		afterID string
+	)
+	for batch := 0; batch < retentionMaxBatches; batch++ {
+		rows, err := conn.QueryContext(ctx, query, orgID, *policy.MaxAgeDays, afterAt, afterID, retentionBatchSize)
+		if err != nil {
+			return fmt.Errorf("select expired sboms: %w", err)
+		}
+		var ids []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id, &afterAt); err != nil {
+				rows.Close()
+				return fmt.Errorf("select expired sboms: %w", err)
+			}
+			ids = append(ids, id)
+			afterID = id
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("select expired sboms: %w", err)
+		}
+
+		if dryRun {
+			report.SBOMs = append(report.SBOMs, ids...)
+		} else {
+			for _, id := range ids {
+				deleted, err := deleteSBOMInTx(ctx, conn, id, orgID)
+				if err != nil {
+					return err
+				}
+				report.SBOMs = append(report.SBOMs, id)
+				report.Vulnerabilities += deleted.Vulnerabilities
+				report.ScanJobs += deleted.ScanJobs
+				if deleted.ObjectURL != "" {
+					if err := deleteSBOMObject(ctx, deleted.ObjectURL); err == nil {
+						report.Objects = append(report.Objects, objectKeyFromURL("", deleted.ObjectURL))
+					}
+				}
+			}
+		}
+		if len(ids) < retentionBatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+func deleteSBOMInTx(ctx context.Context, conn *sql.DB, sbomID string, orgID int) (*SBOMDeletion, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	deleted, err := DeleteSBOM(ctx, tx, sbomID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("delete sbom %s: %w", sbomID, err)
+	}
+	return deleted, tx.Commit()
+}
+
+// purgeExpiredRevisions removes superseded objects that uploads leave behind
+// under each project's storage prefix.
+func purgeExpiredRevisions(ctx context.Context, conn *sql.DB, orgID int, policy RetentionPolicy, dryRun bool, report *RetentionReport) error {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT p.id,
+		       EXISTS (SELECT 1 FROM sbom_legal_holds h WHERE h.organization_id = $1 AND h.project_id = p.id)
+		FROM projects p
+		WHERE p.organization_id = $1
+		ORDER BY p.id
+	`, orgID)
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+	var projectIDs []int
+	for rows.Next() {
+		var (
+			id   int
+			held bool
+		)
+		if err := rows.Scan(&id, &held); err != nil {
+			rows.Close()
+			return err
+		}
+		if held {
+			report.HeldProjects++
+			continue
+		}
+		projectIDs = append(projectIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var cutoff time.Time
+	if policy.MaxAgeDays != nil {
+		cutoff = time.Now().UTC().AddDate(0, 0, -*policy.MaxAgeDays)
+	}
+	keep := 0
+	if policy.KeepRevisions != nil {
+		keep = *policy.KeepRevisions
+	}
+
+	for _, projectID := range projectIDs {
+		objects, err := listSBOMObjects(ctx, fmt.Sprintf("sbom/org-%d/project-%d/", orgID, projectID))
+		if err != nil {
+			return fmt.Errorf("list objects of project %d: %w", projectID, err)
+		}
+		if len(objects) == 0 {
+			continue
+		}
+		urls, err := queryStrings(ctx, conn, `SELECT object_url FROM sboms WHERE project_id = $1 AND object_url IS NOT NULL`, projectID)
+		if err != nil {
+			return fmt.Errorf("load referenced objects: %w", err)
+		}
+		referenced := make(map[string]bool, len(urls))
+		for _, u := range urls {
+			if key := objectKeyFromURL("", u); key != "" {
+				referenced[key] = true
+			}
+		}
+
+		expired := selectExpiredRevisions(objects, referenced, keep, cutoff)
+		if len(expired) == 0 {
+			continue
+		}
+		if dryRun {
+			report.Objects = append(report.Objects, expired...)
+			continue
+		}
+		n, err := deleteSBOMObjectKeys(ctx, expired)
+		report.Objects = append(report.Objects, expired[:n]...)
+		if err != nil {
+			return fmt.Errorf("delete objects of project %d: %w", projectID, err)
+		}
+	}
+	return nil
+}
+
+// selectExpiredRevisions picks, per manifest folder, the objects beyond the
+// newest keep (0 disables the rule) and those last modified before cutoff
+// (zero disables the rule). Objects still referenced by an SBOM row are kept.
+func selectExpiredRevisions(objects []StoredObject, referenced map[string]bool, keep int, cutoff time.Time) []string {
+	byManifest := map[string][]StoredObject{}
+	for _, obj := range objects {
+		dir := path.Dir(obj.Key)
+		byManifest[dir] = append(byManifest[dir], obj)
+	}
+	dirs := make([]string, 0, len(byManifest))
+	for dir := range byManifest {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var expired []string
+	for _, dir := range dirs {
+		revs := byManifest[dir]
+		sort.Slice(revs, func(i, j int) bool {
+			if !revs[i].LastModified.Equal(revs[j].LastModified) {
+				return revs[i].LastModified.After(revs[j].LastModified)
+			}
+			return revs[i].Key > revs[j].Key
+		})
+		for i, obj := range revs {
+			if referenced[obj.Key] {
+				continue
+			}
+			tooMany := keep > 0 && i >= keep
+			tooOld := !cutoff.IsZero() && obj.LastModified.Before(cutoff)
+			if tooMany || tooOld {
+				expired = append(expired, obj.Key)
+			}
+		}
+	}
+	return expired
+}
+
+// StartRetentionWorker enforces retention for every organization that has a
+// policy, once per interval.
+func StartRetentionWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := enforceAllRetention(ctx); err != nil {
+				log.Printf("[RETENTION][ERR] pass failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				log.Println("[RETENTION] worker stopping")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func enforceAllRetention(ctx context.Context) error {
+	const query = `
+		SELECT DISTINCT o.id
+		FROM organizations o
+		LEFT JOIN subscriptions sub ON sub.id = o.subscription_id
+		JOIN sbom_retention_policies rp
+		  ON rp.organization_id = o.id
+		  OR (rp.organization_id IS NULL AND rp.plan_id = sub.plan_id)
+		ORDER BY o.id
+	`
+	rows, err := db.Conn.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	var orgIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		orgIDs = append(orgIDs, id)
+	}
+	rows.Close()
+
+	for _, orgID := range orgIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		report, err := EnforceRetention(ctx, db.Conn, orgID, false)
+		if err != nil {
+			log.Printf("[RETENTION][ERR] org=%d: %v", orgID, err)
+			continue
+		}
+		if report.Purged() {
+			log.Printf("[RETENTION] org=%d purged sboms=%d vulns=%d scan_jobs=%d objects=%d",
+				orgID, len(report.SBOMs), report.Vulnerabilities, report.ScanJobs, len(report.Objects))
+		}
+	}
+	return rows.Err()
+}
+
+func queryStrings(ctx context.Context, exec boil.ContextExecutor, query string, args ...interface{}) ([]string, error) {
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []string{}
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func nullableInt(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectExpiredRevisions(t *testing.T) {
+	now := time.Now().UTC()
+	dir := "sbom/org-1/project-2/package.json/"
+	objects := []StoredObject{
+		{Key: dir + "4.json", LastModified: now.Add(-1 * time.Hour)},
+		{Key: dir + "3.json", LastModified: now.Add(-2 * time.Hour)},
+		{Key: dir + "2.json", LastModified: now.Add(-3 * time.Hour)},
+		{Key: dir + "1.json", LastModified: now.Add(-40 * 24 * time.Hour)},
+		{Key: "sbom/org-1/project-2/go.mod/1.json", LastModified: now.Add(-40 * 24 * time.Hour)},
+	}
+	referenced := map[string]bool{dir + "2.json": true}
+
+	require.Equal(t, []string{dir + "1.json"}, selectExpiredRevisions(objects, referenced, 2, time.Time{}))
+	require.Equal(t,
+		[]string{"sbom/org-1/project-2/go.mod/1.json", dir + "1.json"},
+		selectExpiredRevisions(objects, referenced, 0, now.AddDate(0, 0, -30)))
+	require.Empty(t, selectExpiredRevisions(objects, referenced, 0, time.Time{}))
+}
+
+func TestEnforceRetention_LegalHoldSkipsPurge(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(`FROM organizations o`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"keep_revisions", "max_age_days", "legal_hold", "source"}).
+			AddRow(3, 30, true, "organization"))
+
+	report, err := EnforceRetention(context.Background(), sqlDB, 5, false)
+	require.NoError(t, err)
+	require.False(t, report.Purged())
+	require.True(t, report.Policy.LegalHold)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnforceRetention_DryRunReportsEveryBatch(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	origList, origDelete := listSBOMObjects, deleteSBOMObjectKeys
+	t.Cleanup(func() { listSBOMObjects, deleteSBOMObjectKeys = origList, origDelete })
+	now := time.Now().UTC()
+	listSBOMObjects = func(ctx context.Context, prefix string) ([]StoredObject, error) {
+		require.Equal(t, "sbom/org-5/project-9/", prefix)
+		return []StoredObject{
+			{Key: prefix + "package.json/2.json", LastModified: now},
+			{Key: prefix + "package.json/1.json", LastModified: now.Add(-time.Hour)},
+		}, nil
+	}
+	deleteSBOMObjectKeys = func(ctx context.Context, keys []string) (int, error) {
+		t.Fatal("dry run must not delete objects")
+		return 0, nil
+	}
+
+	mock.ExpectQuery(`FROM organizations o`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"keep_revisions", "max_age_days", "legal_hold", "source"}).
+			AddRow(1, 30, false, "plan"))
+	// A full first batch must not end the dry run.
+	old := now.AddDate(0, 0, -60)
+	first := sqlmock.NewRows([]string{"id", "touched_at"})
+	for i := 0; i < retentionBatchSize; i++ {
+		first.AddRow(fmt.Sprintf("sbom-%03d", i), old)
+	}
+	mock.ExpectQuery(`FROM sboms s`).
+		WithArgs(5, 30, time.Time{}, "", retentionBatchSize).
+		WillReturnRows(first)
+	mock.ExpectQuery(`FROM sboms s`).
+		WithArgs(5, 30, old, fmt.Sprintf("sbom-%03d", retentionBatchSize-1), retentionBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "touched_at"}).AddRow("old-sbom", old))
+	mock.ExpectQuery(`FROM projects p`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "held"}).AddRow(9, false).AddRow(10, true))
+	mock.ExpectQuery(`SELECT object_url FROM sboms`).
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"object_url"}).AddRow("s3://bom/sbom/org-5/project-9/package.json/2.json"))
+
+	report, err := EnforceRetention(context.Background(), sqlDB, 5, true)
+	require.NoError(t, err)
+	require.Len(t, report.SBOMs, retentionBatchSize+1)
+	require.Equal(t, "old-sbom", report.SBOMs[retentionBatchSize])
+	require.Equal(t, []string{"sbom/org-5/project-9/package.json/1.json"}, report.Objects)
+	require.Equal(t, 1, report.HeldProjects)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
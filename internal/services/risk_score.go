@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// Weights applied on top of a finding's base (CVSS or severity) score. Direct
+// dependencies are fully exposed; transitive ones are usually reachable through
+// fewer code paths. Findings with a fix are weighted fully since they are both
+// known-exploitable and actionable.
+const (
+	riskWeightDirect     = 1.0
+	riskWeightTransitive = 0.7
+	riskWeightUnknownRel = 0.85
+	riskWeightFixable    = 1.0
+	riskWeightNoFix      = 0.9
+)
+
+// severityBaseScores is used when a finding has no computable CVSS vector.
+var severityBaseScores = map[string]float64{
+	"critical": 9.5,
+	"high":     7.5,
+	"medium":   5.0,
+	"low":      2.5,
+	"unknown":  4.0,
+}
+
+// RiskFinding is one vulnerability of a project as seen by the scorer.
+type RiskFinding struct {
+	VulnID           string  `json:"vuln_id"`
+	ComponentName    string  `json:"component_name"`
+	ComponentVersion string  `json:"component_version"`
+	Severity         string  `json:"severity"`
+	CVSSVector       string  `json:"-"`
+	FixAvailable     bool    `json:"fix_available"`
+	Relationship     string  `json:"relationship"`
+	Score            float64 `json:"score"`
+}
+
+// ProjectRiskBreakdown explains how a project's risk score was derived.
+type ProjectRiskBreakdown struct {
+	ProjectID            int            `json:"project_id"`
+	Score                float64        `json:"score"`
+	MaxScore             float64        `json:"max_score"`
+	TotalVulnerabilities int            `json:"total_vulnerabilities"`
+	BySeverity           map[string]int `json:"by_severity"`
+	ByRelationship       map[string]int `json:"by_relationship"`
+	Fixable              int            `json:"fixable"`
+	Unfixable            int            `json:"unfixable"`
+	TopFindings          []RiskFinding  `json:"top_findings"`
+	ComputedAt           time.Time      `json:"computed_at"`
+}
+
+// ScoreFindings weights each finding and averages them into a 0-10 score.
+// Duplicate rows for the same component version and advisory (e.g. written by
+// both the vuln service and the offline matcher) count once.
+func ScoreFindings(findings []RiskFinding) ProjectRiskBreakdown {
+	b := ProjectRiskBreakdown{
+		BySeverity:     map[string]int{"critical": 0, "high": 0, "medium": 0, "low": 0, "unknown": 0},
+		ByRelationship: map[string]int{RelationshipDirect: 0, RelationshipTransitive: 0, RelationshipUnknown: 0},
+		TopFindings:    []RiskFinding{},
+		ComputedAt:     time.Now().UTC(),
+	}
+
+	seen := map[string]struct{}{}
+	var scored []RiskFinding
+	var sum float64
+	for _, f := range findings {
+		key := f.ComponentName + "@" + f.ComponentVersion + "#" + f.VulnID
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		base, ok := cvssV3BaseScore(f.CVSSVector)
+		severity := normalizeSeverity(f.Severity)
+		if ok {
+			if severity == "unknown" {
+				severity = severityFromCVSS(base)
+			}
+		} else {
+			base = severityBaseScores[severity]
+		}
+		f.Severity = severity
+
+		weight := riskWeightUnknownRel
+		switch f.Relationship {
+		case RelationshipDirect:
+			weight = riskWeightDirect
+		case RelationshipTransitive:
+			weight = riskWeightTransitive
+		default:
+			f.Relationship = RelationshipUnknown
+		}
+		if f.FixAvailable {
+			weight *= riskWeightFixable
+			b.Fixable++
+		} else {
+			weight *= riskWeightNoFix
+			b.Unfixable++
+		}
+
+		f.Score = roundScore(base * weight)
+		sum += f.Score
+		b.MaxScore = math.Max(b.MaxScore, f.Score)
+		b.BySeverity[severity]++
+		b.ByRelationship[f.Relationship]++
+		scored = append(scored, f)
+	}
+
+	b.TotalVulnerabilities = len(scored)
+	if len(scored) > 0 {
+		b.Score = roundScore(sum / float64(len(scored)))
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+	if len(scored) > 10 {
+		scored = scored[:10]
+	}
+	b.TopFindings = append(b.TopFindings, scored...)
+	return b
+}
+
+func roundScore(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ComputeProjectRisk loads the vulnerabilities of the project's current SBOMs
+// and scores them. A component is direct when the SBOM root depends on it.
+func ComputeProjectRisk(ctx context.Context, exec boil.ContextExecutor, projectID int) (*ProjectRiskBreakdown, error) {
+	const query = `
+		SELECT COALESCE(v.vuln_id, ''), v.component_name, v.component_version,
+		       COALESCE(v.severity, ''), COALESCE(v.cvss_vector, ''), COALESCE(v.fix_available, FALSE),
+		       CASE
+		         WHEN jsonb_typeof(s.sbom->'dependencies') IS DISTINCT FROM 'array' THEN NULL
+		         ELSE EXISTS (
+		           SELECT 1
+		           FROM jsonb_array_elements(COALESCE(s.sbom->'components', '[]'::jsonb)) c
+		           JOIN jsonb_array_elements(s.sbom->'dependencies') d
+		             ON d->>'ref' = s.sbom->'metadata'->'component'->>'bom-ref'
+		           WHERE ` + vulnComponentMatchSQL + `
+		             AND d->'dependsOn' ? (c->>'bom-ref')
+		         )
+		       END AS is_direct
+		FROM vulnerabilities v
+		JOIN sboms s ON s.id = v.sbom_id
+		WHERE s.project_id = $1
+	`
+	rows, err := exec.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("load project vulnerabilities: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []RiskFinding
+	for rows.Next() {
+		var f RiskFinding
+		var direct sql.NullBool
+		if err := rows.Scan(&f.VulnID, &f.ComponentName, &f.ComponentVersion,
+			&f.Severity, &f.CVSSVector, &f.FixAvailable, &direct); err != nil {
+			return nil, err
+		}
+		switch {
+		case !direct.Valid:
+			f.Relationship = RelationshipUnknown
+		case direct.Bool:
+			f.Relationship = RelationshipDirect
+		default:
+			f.Relationship = RelationshipTransitive
+		}
+		findings = append(findings, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	b := ScoreFindings(findings)
+	b.ProjectID = projectID
+	return &b, nil
+}
+
+// RecomputeProjectRisk scores the project and stores avg_risk_score and
+// total_vulnerabilities on its row. risk_evaluated_at records when the
+// findings were last evaluated; last_vuln_scan belongs to the vulnerability
+// service and is left alone.
+func RecomputeProjectRisk(ctx context.Context, exec boil.ContextExecutor, projectID int) (*ProjectRiskBreakdown, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	b, err := ComputeProjectRisk(ctx, exec, projectID)
+	if err != nil {
+		return nil, err
+	}
+	const update = `
+		UPDATE projects
+		SET avg_risk_score = $1, total_vulnerabilities = $2, risk_evaluated_at = NOW()
+		WHERE id = $3
+	`
+	if _, err := exec.ExecContext(ctx, update, fmt.Sprintf("%.2f", b.Score), b.TotalVulnerabilities, projectID); err != nil {
+		return nil, fmt.Errorf("store project risk: %w", err)
+	}
+	return b, nil
+}
+
+// StartRiskScoreWorker periodically recomputes projects whose vulnerabilities
+// changed after their last evaluation, which covers findings written or
+// deleted by the vulnerability service.
+func StartRiskScoreWorker(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			if err := recomputeStaleProjectRisk(ctx, 50); err != nil {
+				log.Printf("[RISK][ERR] recompute batch failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				log.Println("[RISK] worker stopping")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func recomputeStaleProjectRisk(ctx context.Context, batch int) error {
+	const query = `
+		SELECT p.id
+		FROM projects p
+		WHERE EXISTS (
+			SELECT 1
+			FROM vulnerabilities v
+			JOIN sboms s ON s.id = v.sbom_id
+			WHERE s.project_id = p.id
+			  AND (p.risk_evaluated_at IS NULL OR COALESCE(v.updated_at, v.created_at) > p.risk_evaluated_at)
+		)
+		-- A count that no longer matches catches deleted findings and rows
+		-- committed with a timestamp older than the last evaluation.
+		   OR COALESCE(p.total_vulnerabilities, 0) <> (
+			SELECT COUNT(DISTINCT (v.component_name, v.component_version, COALESCE(v.vuln_id, '')))
+			FROM vulnerabilities v
+			JOIN sboms s ON s.id = v.sbom_id
+			WHERE s.project_id = p.id
+		)
+		LIMIT $1
+	`
+	rows, err := db.Conn.QueryContext(ctx, query, batch)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if _, err := RecomputeProjectRisk(ctx, db.Conn, id); err != nil {
+			log.Printf("[RISK][WARN] project %d: %v", id, err)
+		}
+	}
+	if len(ids) > 0 {
+		log.Printf("[RISK] recomputed %d project(s): %s", len(ids), strings.Trim(fmt.Sprint(ids), "[]"))
+	}
+	return nil
+}
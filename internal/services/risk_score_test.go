@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreFindings_Weights(t *testing.T) {
+	b := ScoreFindings([]RiskFinding{
+		{VulnID: "CVE-1", ComponentName: "lodash", ComponentVersion: "4.17.20", Severity: "critical", FixAvailable: true, Relationship: RelationshipDirect},
+		{VulnID: "CVE-1", ComponentName: "lodash", ComponentVersion: "4.17.20", Severity: "CRITICAL", FixAvailable: true, Relationship: RelationshipDirect},
+		{VulnID: "CVE-2", ComponentName: "minimist", ComponentVersion: "1.2.0", Severity: "low", Relationship: RelationshipTransitive},
+		{VulnID: "CVE-3", ComponentName: "qs", ComponentVersion: "6.0.0",
+			CVSSVector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", FixAvailable: true},
+	})
+
+	require.Equal(t, 3, b.TotalVulnerabilities)
+	require.Equal(t, 2, b.BySeverity["critical"])
+	require.Equal(t, 1, b.BySeverity["low"])
+	require.Equal(t, 1, b.ByRelationship[RelationshipDirect])
+	require.Equal(t, 1, b.ByRelationship[RelationshipTransitive])
+	require.Equal(t, 1, b.ByRelationship[RelationshipUnknown])
+	require.Equal(t, 2, b.Fixable)
+	require.Equal(t, 1, b.Unfixable)
+
+	// critical direct fixable: 9.5; low transitive unfixed: 2.5*0.7*0.9;
+	// CVSS 9.8 unknown relationship: 9.8*0.85.
+	require.Equal(t, 9.5, b.MaxScore)
+	require.InDelta(t, (9.5+1.58+8.33)/3, b.Score, 0.01)
+	require.Equal(t, "lodash", b.TopFindings[0].ComponentName)
+	require.Equal(t, "minimist", b.TopFindings[2].ComponentName)
+}
+
+func TestScoreFindings_Empty(t *testing.T) {
+	b := ScoreFindings(nil)
+	require.Zero(t, b.Score)
+	require.Zero(t, b.TotalVulnerabilities)
+	require.NotNil(t, b.TopFindings)
+}
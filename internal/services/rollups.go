@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// Daily rollups hold one SBOM's contribution per (org-local day, org,
+// project): sbom_daily_rollups by source, sbom_daily_ecosystem_rollups and
+// sbom_daily_license_rollups by component. Writers add an SBOM's
+// contribution after storing it and subtract it before changing or deleting
+// it, so the tables always mirror the live sboms rows. Legacy rows without a
+// project_id are not counted, matching the org filter of the live queries.
+
+// rollupSourceSQL computes the day and dimensions of sboms s joined to its
+// project p, in the organization's time zone.
+const rollupSourceSQL = `
+	FROM sboms s
+	JOIN projects p ON p.id = s.project_id
+	LEFT JOIN organization_settings os ON os.organization_id = p.organization_id`
+
+const rollupDaySQL = `(s.created_at AT TIME ZONE COALESCE(NULLIF(os.time_zone, ''), 'UTC'))::date`
+
+const rollupComponentCountSQL = `(CASE WHEN jsonb_typeof(s.sbom->'components') = 'array'
+		THEN jsonb_array_length(s.sbom->'components') ELSE 0 END)`
+
+const rollupLicenseSQL = `COALESCE(l->'license'->>'id', l->'license'->>'name', l->>'expression', 'UNKNOWN')`
+
+const rollupLicensesJoinSQL = `
+	LEFT JOIN LATERAL jsonb_array_elements(
+		CASE WHEN jsonb_typeof(c->'licenses') = 'array' THEN c->'licenses' ELSE '[]'::jsonb END
+	) l ON TRUE`
+
+// rollupStatements insert contributions selected by filter, multiplied by the
+// sign bound as $1. Conflicting rows accumulate.
+func rollupStatements(filter string) []string {
+	return []string{
+		fmt.Sprintf(`
+			INSERT INTO sbom_daily_rollups (day, organization_id, project_id, source, sboms, components, updated_at)
+			SELECT %s, p.organization_id, p.id, COALESCE(NULLIF(LOWER(s.source), ''), 'unknown'),
+			       $1 * COUNT(*), $1 * SUM(%s), NOW()
+			%s
+			WHERE %s
+			GROUP BY 1, 2, 3, 4
+			ON CONFLICT (day, organization_id, project_id, source) DO UPDATE
+			SET sboms = sbom_daily_rollups.sboms + EXCLUDED.sboms,
+			    components = sbom_daily_rollups.components + EXCLUDED.components,
+			    updated_at = NOW()
+		`, rollupDaySQL, rollupComponentCountSQL, rollupSourceSQL, filter),
+		fmt.Sprintf(`
+			INSERT INTO sbom_daily_ecosystem_rollups (day, organization_id, project_id, ecosystem, components, updated_at)
+			SELECT %s, p.organization_id, p.id, %s, $1 * COUNT(*), NOW()
+			%s
+			%s
+			WHERE %s
+			GROUP BY 1, 2, 3, 4
+			ON CONFLICT (day, organization_id, project_id, ecosystem) DO UPDATE
+			SET components = sbom_daily_ecosystem_rollups.components + EXCLUDED.components,
+			    updated_at = NOW()
+		`, rollupDaySQL, componentEcosystemSQL, rollupSourceSQL, componentsJoinSQL, filter),
+		fmt.Sprintf(`
+			INSERT INTO sbom_daily_license_rollups (day, organization_id, project_id, license, components, updated_at)
+			SELECT %s, p.organization_id, p.id, %s, $1 * COUNT(*), NOW()
+			%s
+			%s
+			%s
+			WHERE %s
+			GROUP BY 1, 2, 3, 4
+			ON CONFLICT (day, organization_id, project_id, license) DO UPDATE
+			SET components = sbom_daily_license_rollups.components + EXCLUDED.components,
+			    updated_at = NOW()
+		`, rollupDaySQL, rollupLicenseSQL, rollupSourceSQL, componentsJoinSQL, rollupLicensesJoinSQL, filter),
+	}
+}
+
+var rollupTables = []string{
+	"sbom_daily_rollups",
+	"sbom_daily_ecosystem_rollups",
+	"sbom_daily_license_rollups",
+}
+
+// AddSBOMRollup adds the stored SBOM's contribution to the daily rollups.
+func AddSBOMRollup(ctx context.Context, exec boil.ContextExecutor, sbomID string) error {
+	return applySBOMRollup(ctx, exec, sbomID, 1)
+}
+
+// SubtractSBOMRollup removes the stored SBOM's contribution; call it before
+// the row changes or goes away.
+func SubtractSBOMRollup(ctx context.Context, exec boil.ContextExecutor, sbomID string) error {
+	return applySBOMRollup(ctx, exec, sbomID, -1)
+}
+
+func applySBOMRollup(ctx context.Context, exec boil.ContextExecutor, sbomID string, sign int) error {
+	if exec == nil {
+		exec = db.Conn
+	}
+	for _, stmt := range rollupStatements("s.id = $2") {
+		if _, err := exec.ExecContext(ctx, stmt, sign, sbomID); err != nil {
+			return fmt.Errorf("update rollups: %w", err)
+		}
+	}
+	return nil
+}
+
+// MoveProjectRollups re-keys a project's rollups to another organization.
+func MoveProjectRollups(ctx context.Context, exec boil.ContextExecutor, projectID, orgID int) error {
+	if exec == nil {
+		exec = db.Conn
+	}
+	for _, table := range rollupTables {
+		if _, err := exec.ExecContext(ctx,
+			fmt.Sprintf(`UPDATE %s SET organization_id = $2, updated_at = NOW() WHERE project_id = $1`, table),
+			projectID, orgID); err != nil {
+			return fmt.Errorf("move %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// DeleteProjectRollups drops whatever rollup rows a project still has.
+func DeleteProjectRollups(ctx context.Context, exec boil.ContextExecutor, projectID int) error {
+	if exec == nil {
+		exec = db.Conn
+	}
+	for _, table := range rollupTables {
+		if _, err := exec.ExecContext(ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE project_id = $1`, table), projectID); err != nil {
+			return fmt.Errorf("delete %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// RollupRebuild reports a RebuildRollups run.
+type RollupRebuild struct {
+	OrganizationID int           `json:"organization_id,omitempty"`
+	SBOMs          int64         `json:"sboms"`
+	Duration       time.Duration `json:"duration"`
+}
+
+// RebuildRollups recomputes the rollups of one organization, or of every
+// organization when orgID is 0, from the sboms table in a single transaction.
+func RebuildRollups(ctx context.Context, conn *sql.DB, orgID int) (*RollupRebuild, error) {
+	started := time.Now()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// An orgID of 0 selects every organization.
+	for _, table := range rollupTables {
+		if _, err := tx.ExecContext(ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE ($1 = 0 OR organization_id = $1)`, table), orgID); err != nil {
+			return nil, fmt.Errorf("clear %s: %w", table, err)
+		}
+	}
+	for _, stmt := range rollupStatements("($2 = 0 OR p.organization_id = $2)") {
+		if _, err := tx.ExecContext(ctx, stmt, 1, orgID); err != nil {
+			return nil, fmt.Errorf("rebuild rollups: %w", err)
+		}
+	}
+
+	out := &RollupRebuild{OrganizationID: orgID}
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(sboms), 0) FROM sbom_daily_rollups WHERE ($1 = 0 OR organization_id = $1)`,
+		orgID).Scan(&out.SBOMs); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	out.Duration = time.Since(started)
+	log.Printf("[ROLLUP] rebuilt org=%d sboms=%d in %s", orgID, out.SBOMs, out.Duration)
+	return out, nil
+}
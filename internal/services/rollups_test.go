@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func expectSBOMRollup(mock sqlmock.Sqlmock, sign int, sbomID string) {
+	mock.ExpectExec(`INSERT INTO sbom_daily_rollups .*WHERE s.id = \$2`).
+		WithArgs(sign, sbomID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO sbom_daily_ecosystem_rollups .*WHERE s.id = \$2`).
+		WithArgs(sign, sbomID).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO sbom_daily_license_rollups .*WHERE s.id = \$2`).
+		WithArgs(sign, sbomID).WillReturnResult(sqlmock.NewResult(0, 2))
+}
+
+func TestRebuildRollups_Org(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	for range rollupTables {
+		mock.ExpectExec(`DELETE FROM sbom_daily_\w*rollups WHERE \(\$1 = 0 OR organization_id = \$1\)`).
+			WithArgs(4).WillReturnResult(sqlmock.NewResult(0, 10))
+	}
+	mock.ExpectExec(`INSERT INTO sbom_daily_rollups .*AT TIME ZONE COALESCE\(NULLIF\(os.time_zone, ''\), 'UTC'\)`).
+		WithArgs(1, 4).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`INSERT INTO sbom_daily_ecosystem_rollups`).WithArgs(1, 4).WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectExec(`INSERT INTO sbom_daily_license_rollups`).WithArgs(1, 4).WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(sboms\), 0\) FROM sbom_daily_rollups`).
+		WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(12))
+	mock.ExpectCommit()
+
+	out, err := RebuildRollups(context.Background(), sqlDB, 4)
+	require.NoError(t, err)
+	require.EqualValues(t, 12, out.SBOMs)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// SBOM change types reported by ListSBOMChanges.
+const (
+	SBOMChangeUpserted = "upserted"
+	SBOMChangeDeleted  = "deleted"
+)
+
+const (
+	defaultSBOMChangeLimit = 100
+	sbomChangeSequenceSize = 1000
+	// sbomChangeSequenceLock is the advisory lock key of SequenceSBOMChanges.
+	sbomChangeSequenceLock = 0x5b0c4a9e
+)
+
+const sbomChangeEventTypesSQL = `('sbom.created', 'sbom.batch_created', 'sbom.deleted')`
+
+// SBOMChange is one SBOM upload or deletion, read back from the outbox.
+type SBOMChange struct {
+	Seq          int64
+	EventID      string
+	Type         string
+	SBOMID       string
+	ProjectID    int
+	ProjectName  string
+	ManifestName string
+	ChangedAt    time.Time
+}
+
+// SBOMChangeQuery selects changes of one organization after AfterSeq, a
+// position in the outbox_events.change_seq order.
+type SBOMChangeQuery struct {
+	OrganizationID int
+	ProjectID      int
+	AfterSeq       int64
+	Limit          int
+}
+
+// SBOMChangePage is one ListSBOMChanges result. AfterSeq is where the next
+// call continues, and More reports that the event limit was reached.
+type SBOMChangePage struct {
+	Changes  []SBOMChange
+	AfterSeq int64
+	More     bool
+}
+
+// SequenceSBOMChanges numbers committed SBOM change events in
+// outbox_events.change_seq. Numbers are handed out under a transaction-level
+// advisory lock, so once a reader has seen number N no event numbered below
+// N can appear later, however late its producer committed. Readers page on
+// change_seq instead of created_at for that reason.
+func SequenceSBOMChanges(ctx context.Context, conn *sql.DB) (int64, error) {
+	if conn == nil {
+		conn = db.Conn
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, sbomChangeSequenceLock); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE outbox_events o
+		SET change_seq = n.seq
+		FROM (
+			SELECT id, nextval('outbox_events_change_seq') AS seq
+			FROM (
+				SELECT id
+				FROM outbox_events
+				WHERE change_seq IS NULL
+				  AND topic = $1
+				  AND event_type IN `+sbomChangeEventTypesSQL+`
+				ORDER BY created_at, id
+				LIMIT $2
+			) pending
+		) n
+		WHERE o.id = n.id
+	`, KafkaTopic, sbomChangeSequenceSize)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return n, tx.Commit()
+}
+
+// SBOMChangePosition returns the position to read from to get the changes
+// created after since: just before the first numbered event created after
+// it, or the latest position when there is none yet.
+func SBOMChangePosition(ctx context.Context, exec boil.ContextExecutor, since time.Time) (int64, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	var seq int64
+	err := exec.QueryRowContext(ctx, `
+		SELECT COALESCE(
+			(SELECT MIN(change_seq) - 1 FROM outbox_events WHERE change_seq IS NOT NULL AND created_at > $1),
+			(SELECT MAX(change_seq) FROM outbox_events),
+			0)
+	`, since).Scan(&seq)
+	return seq, err
+}
+
+// ListSBOMChanges returns SBOM changes in change_seq order. Limit counts
+// events, so a batch upload is never split across two calls. The cursor
+// moves past every event read, including events that name no SBOM.
+func ListSBOMChanges(ctx context.Context, exec boil.ContextExecutor, q SBOMChangeQuery) (*SBOMChangePage, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultSBOMChangeLimit
+	}
+
+	rows, err := exec.QueryContext(ctx, `
+		WITH ev AS (
+			SELECT change_seq, id::text AS id, event_type, payload, created_at
+			FROM outbox_events
+			WHERE change_seq > $4
+			  AND topic = $1
+			  AND event_type IN `+sbomChangeEventTypesSQL+`
+			  AND payload->>'organization_id' = $2::text
+			  AND ($3 = 0 OR payload->>'project_id' = $3::text)
+			ORDER BY change_seq
+			LIMIT $5
+		)
+		SELECT ev.change_seq, ev.id, ev.event_type, r.sbom_id,
+		       COALESCE((ev.payload->>'project_id')::int, 0),
+		       COALESCE(ev.payload->>'project_name', ev.payload->>'project', ''),
+		       COALESCE(ev.payload->>'manifest_name', s.manifest_name, ''),
+		       ev.created_at
+		FROM ev
+		LEFT JOIN LATERAL (
+			SELECT ev.payload->>'sbom_id' AS sbom_id WHERE ev.event_type <> 'sbom.batch_created'
+			UNION ALL
+			SELECT rec->>'id' FROM jsonb_array_elements(
+				CASE WHEN ev.event_type = 'sbom.batch_created' AND jsonb_typeof(ev.payload->'sbom_records') = 'array'
+				THEN ev.payload->'sbom_records' ELSE '[]'::jsonb END) rec
+		) r ON TRUE
+		LEFT JOIN sboms s ON s.id::text = r.sbom_id
+		ORDER BY ev.change_seq, r.sbom_id
+	`, KafkaTopic, q.OrganizationID, q.ProjectID, q.AfterSeq, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &SBOMChangePage{AfterSeq: q.AfterSeq}
+	events := 0
+	for rows.Next() {
+		var (
+			c         SBOMChange
+			eventType string
+			sbomID    sql.NullString
+		)
+		if err := rows.Scan(&c.Seq, &c.EventID, &eventType, &sbomID, &c.ProjectID, &c.ProjectName, &c.ManifestName, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		if c.Seq != page.AfterSeq {
+			page.AfterSeq = c.Seq
+			events++
+		}
+		if !sbomID.Valid || sbomID.String == "" {
+			continue
+		}
+		c.SBOMID = sbomID.String
+		c.Type = SBOMChangeUpserted
+		if eventType == "sbom.deleted" {
+			c.Type = SBOMChangeDeleted
+		}
+		page.Changes = append(page.Changes, c)
+	}
+	page.More = events >= q.Limit
+	return page, rows.Err()
+}
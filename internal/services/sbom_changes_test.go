@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSBOMChanges_AdvancesPastEventsWithoutSBOMs(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	at := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	cols := []string{"change_seq", "id", "event_type", "sbom_id", "project_id", "project_name", "manifest_name", "created_at"}
+	mock.ExpectQuery(`FROM outbox_events`).
+		WithArgs(KafkaTopic, 7, 0, int64(10), 2).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(11, "ev1", "sbom.batch_created", nil, 5, "shop", "", at).
+			AddRow(12, "ev2", "sbom.created", nil, 5, "shop", "", at))
+	mock.ExpectQuery(`FROM outbox_events`).
+		WithArgs(KafkaTopic, 7, 0, int64(12), 2).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(15, "ev3", "sbom.batch_created", "sb1", 5, "shop", "go.mod", at).
+			AddRow(15, "ev3", "sbom.batch_created", "sb2", 5, "shop", "package.json", at))
+
+	q := SBOMChangeQuery{OrganizationID: 7, AfterSeq: 10, Limit: 2}
+	page, err := ListSBOMChanges(context.Background(), sqlDB, q)
+	require.NoError(t, err)
+	require.Empty(t, page.Changes)
+	require.EqualValues(t, 12, page.AfterSeq)
+	require.True(t, page.More)
+
+	q.AfterSeq = page.AfterSeq
+	page, err = ListSBOMChanges(context.Background(), sqlDB, q)
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 2)
+	require.EqualValues(t, 15, page.AfterSeq)
+	require.False(t, page.More)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -35,6 +35,10 @@ func CreateSBOM(ctx context.Context, db *sql.DB, project string, sbomJSON []byte
 	return sbom.ID, "create", err
 }
 
+// UpsertSBOM stores the SBOM of a project manifest, replacing the previous
+// one, and moves its counts in the daily rollups. The row and the rollups
+// must change together, so exec should be a transaction; UpsertSBOMInTx
+// opens one.
 func UpsertSBOM(ctx context.Context, exec boil.ContextExecutor, projectID int, projectName string, manifestName string, sbomJSON []byte, source, objectURL string) (string, string, error) {
 	//Generate summary from sbomjson
 	summary, err := ParseSBOMSummary(sbomJSON)
@@ -43,19 +47,28 @@ func UpsertSBOM(ctx context.Context, exec boil.ContextExecutor, projectID int, p
 	}
 	summaryBytes, _ := json.Marshal(summary)
 
-	//Check existing SBOM
+	//Check existing SBOM: keyed on project_id so renames keep the history;
+	//rows written before project_id was set are matched by name.
 	existing, err := models.Sboms(
-		qm.Where("project_name=? AND manifest_name=?", projectName, manifestName),
+		qm.Where("manifest_name=?", manifestName),
+		qm.Where("(project_id=? OR (project_id IS NULL AND project_name=?))", projectID, projectName),
+		qm.OrderBy("project_id NULLS LAST"),
 	).One(ctx, exec)
 
 	if err == nil && existing != nil {
+		if err := SubtractSBOMRollup(ctx, exec, existing.ID); err != nil {
+			return "", "", err
+		}
 		existing.ProjectID = null.IntFrom(projectID)
+		existing.ProjectName = projectName
 		existing.Sbom = sbomJSON
 		existing.ObjectURL = null.StringFrom(objectURL)
 		existing.Summary = null.JSONFrom(summaryBytes)
 		existing.Source = source
-		_, err := existing.Update(ctx, exec, boil.Infer())
-		return existing.ID, "update", err
+		if _, err := existing.Update(ctx, exec, boil.Infer()); err != nil {
+			return "", "", err
+		}
+		return existing.ID, "update", AddSBOMRollup(ctx, exec, existing.ID)
 	}
 	//Insert if not found
 	// INSERT NEW
@@ -71,31 +84,28 @@ func UpsertSBOM(ctx context.Context, exec boil.ContextExecutor, projectID int, p
 		ObjectURL:    null.StringFrom(objectURL),
 	}
 
-	err = sbom.Insert(ctx, exec, boil.Infer())
-	return sbom.ID, "create", err
-}
-
-func GetSBOM(ctx context.Context, db *sql.DB, id string) (*models.Sbom, error) {
-	return models.FindSbom(ctx, db, id)
+	if err := sbom.Insert(ctx, exec, boil.Infer()); err != nil {
+		return "", "", err
+	}
+	return sbom.ID, "create", AddSBOMRollup(ctx, exec, sbom.ID)
 }
 
-func ListSBOM(ctx context.Context, db *sql.DB, project string, limit int, orgID int) ([]*models.Sbom, error) {
-	queryMods := []qm.QueryMod{
-		qm.OrderBy("created_at desc"),
-		qm.Limit(limit),
-		qm.Where(
-			"EXISTS (SELECT 1 FROM projects p WHERE p.organization_id = ? AND (p.id = sboms.project_id OR p.name = sboms.project_name))",
-			orgID,
-		),
-	}
-	if project != "" {
-		queryMods = append(queryMods, qm.Where("project_name = ?", project))
+// UpsertSBOMInTx runs UpsertSBOM in its own transaction.
+func UpsertSBOMInTx(ctx context.Context, conn *sql.DB, projectID int, projectName string, manifestName string, sbomJSON []byte, source, objectURL string) (string, string, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return "", "", err
 	}
-	sboms, err := models.Sboms(queryMods...).All(ctx, db)
+	defer tx.Rollback()
+	id, action, err := UpsertSBOM(ctx, tx, projectID, projectName, manifestName, sbomJSON, source, objectURL)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
-	return sboms, nil
+	return id, action, tx.Commit()
+}
+
+func GetSBOM(ctx context.Context, db *sql.DB, id string) (*models.Sbom, error) {
+	return models.FindSbom(ctx, db, id)
 }
 
 // UpdateProjectSBOMMeta updates related project when new SBOM is uploaded.
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+	"myesi-sbom-service-golang/models"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+)
+
+// ErrSBOMNotFound is returned when the SBOM to delete does not exist.
+var ErrSBOMNotFound = errors.New("sbom not found")
+
+// SBOMDeletion describes what DeleteSBOM removed. ObjectURL is left for the
+// caller to purge from storage once the transaction has committed.
+type SBOMDeletion struct {
+	SBOMID          string `json:"sbom_id"`
+	ProjectID       int    `json:"project_id,omitempty"`
+	ProjectName     string `json:"project_name"`
+	ManifestName    string `json:"manifest_name,omitempty"`
+	ObjectURL       string `json:"-"`
+	Vulnerabilities int64  `json:"vulnerabilities"`
+	ScanJobs        int64  `json:"scan_jobs"`
+}
+
+// sbomDependentTables hold rows keyed by sbom_id that go with the SBOM.
+var sbomDependentTables = []string{
+	"vulnerabilities",
+	"scan_jobs",
+	"sbom_supply_chain_findings",
+	"sbom_malicious_matches",
+	"sbom_labels",
+}
+
+// DeleteSBOM removes an SBOM with its vulnerabilities, scan jobs and findings,
+// refreshes the project's risk score and enqueues an sbom.deleted event. Run
+// it inside a transaction; the storage object is removed by DeleteSBOMObject.
+func DeleteSBOM(ctx context.Context, exec boil.ContextExecutor, sbomID string, orgID int) (*SBOMDeletion, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+
+	sbom, err := models.Sboms(
+		qm.Select("id", "project_id", "project_name", "manifest_name", "object_url"),
+		qm.Where("id = ?", sbomID),
+		qm.Where(orgSBOMFilterWhere, orgID),
+		qm.For("UPDATE"),
+	).One(ctx, exec)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSBOMNotFound
+		}
+		return nil, fmt.Errorf("load sbom: %w", err)
+	}
+
+	out := &SBOMDeletion{
+		SBOMID:       sbom.ID,
+		ProjectID:    sbom.ProjectID.Int,
+		ProjectName:  sbom.ProjectName,
+		ManifestName: sbom.ManifestName.String,
+		ObjectURL:    sbom.ObjectURL.String,
+	}
+
+	// Captured before sbom_labels goes with the rest.
+	labels := eventLabels(ctx, exec, out.ProjectID, sbomID)
+	if err := SubtractSBOMRollup(ctx, exec, sbomID); err != nil {
+		return nil, err
+	}
+
+	for _, table := range sbomDependentTables {
+		res, err := exec.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE sbom_id = $1", table), sbomID)
+		if err != nil {
+			return nil, fmt.Errorf("delete %s: %w", table, err)
+		}
+		n, _ := res.RowsAffected()
+		switch table {
+		case "vulnerabilities":
+			out.Vulnerabilities = n
+		case "scan_jobs":
+			out.ScanJobs = n
+		}
+	}
+
+	if _, err := exec.ExecContext(ctx, "DELETE FROM sboms WHERE id = $1", sbomID); err != nil {
+		return nil, fmt.Errorf("delete sbom: %w", err)
+	}
+
+	if out.ProjectID > 0 {
+		if _, err := RecomputeProjectRisk(ctx, exec, out.ProjectID); err != nil {
+			return nil, fmt.Errorf("recompute project risk: %w", err)
+		}
+	}
+
+	err = EnqueueOutboxEvent(ctx, exec, OutboxMessage{
+		Topic:     KafkaTopic,
+		EventType: "sbom.deleted",
+		Key:       sbomID,
+		Payload: map[string]interface{}{
+			"sbom_id":         sbomID,
+			"project_id":      out.ProjectID,
+			"project_name":    out.ProjectName,
+			"manifest_name":   out.ManifestName,
+			"organization_id": orgID,
+			"labels":          labels,
+			"deleted_at":      time.Now().UTC(),
+		},
+		DedupKey: "sbom.deleted:" + sbomID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("queue sbom.deleted: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteSBOM_CascadesAndQueuesEvent(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(`SELECT "id", "project_id", "project_name", "manifest_name", "object_url" FROM "sboms" WHERE \(id = \$1\) AND \(\s+EXISTS \(\s+SELECT 1 FROM projects p\s+WHERE p.id = sboms.project_id\s+AND p.organization_id = \$2\s+\)\s+\) LIMIT 1 FOR UPDATE`).
+		WithArgs("sb-1", 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "project_id", "project_name", "manifest_name", "object_url"}).
+			AddRow("sb-1", nil, "shop", "package.json", "s3://bom/sbom/org-4/project-9/package.json/1-x.json"))
+	mock.ExpectQuery(`FROM project_labels WHERE project_id = \$1\s+UNION ALL`).
+		WithArgs(0, "sb-1").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value", "precedence"}).AddRow("team", "payments", 1))
+	expectSBOMRollup(mock, -1, "sb-1")
+	mock.ExpectExec(`DELETE FROM vulnerabilities WHERE sbom_id = \$1`).WithArgs("sb-1").WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`DELETE FROM scan_jobs WHERE sbom_id = \$1`).WithArgs("sb-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM sbom_supply_chain_findings`).WithArgs("sb-1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM sbom_malicious_matches`).WithArgs("sb-1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM sbom_labels`).WithArgs("sb-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM sboms WHERE id = \$1`).WithArgs("sb-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO outbox_events`).
+		WithArgs(sqlmock.AnyArg(), KafkaTopic, "sb-1", "sbom.deleted", sqlmock.AnyArg(), sqlmock.AnyArg(), "pending", "sbom.deleted:sb-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	out, err := DeleteSBOM(context.Background(), sqlDB, "sb-1", 4)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, out.Vulnerabilities)
+	require.EqualValues(t, 1, out.ScanJobs)
+	require.Equal(t, "s3://bom/sbom/org-4/project-9/package.json/1-x.json", out.ObjectURL)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSBOM_NotFound(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	mock.ExpectQuery(`FROM "sboms"`).WithArgs("missing", 4).WillReturnError(sql.ErrNoRows)
+
+	_, err = DeleteSBOM(context.Background(), sqlDB, "missing", 4)
+	require.ErrorIs(t, err, ErrSBOMNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"myesi-sbom-service-golang/models"
+
+	null "github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+	"github.com/aarondl/sqlboiler/v4/types"
+)
+
+// ErrInvalidCursor is returned when a list cursor is malformed or was issued
+// for a different sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const componentCountExpr = `CASE WHEN jsonb_typeof(sboms.sbom->'components') = 'array'
+	THEN jsonb_array_length(sboms.sbom->'components') ELSE 0 END`
+
+// sbomSortExprs whitelists the sort keys of ListSBOM. Every expression is
+// non-null so keyset comparisons stay well defined.
+var sbomSortExprs = map[string]string{
+	"created_at":    "COALESCE(sboms.created_at, 'epoch'::timestamp)",
+	"updated_at":    "COALESCE(sboms.updated_at, sboms.created_at, 'epoch'::timestamp)",
+	"project_name":  "sboms.project_name",
+	"manifest_name": "COALESCE(sboms.manifest_name, '')",
+	"components":    componentCountExpr,
+}
+
+// SBOMListQuery filters, sorts and pages the organization's SBOMs.
+type SBOMListQuery struct {
+	Project       string
+	Source        string
+	Manifest      string
+	Ecosystem     string
+	From          *time.Time
+	To            *time.Time
+	MinComponents *int
+	MaxComponents *int
+	Sort          string
+	Order         string
+	Limit         int
+	Cursor        string
+	// IncludeSBOM adds the full SBOM document to every item.
+	IncludeSBOM bool
+}
+
+// SBOMListItem is the list projection of an SBOM: metadata, summary and
+// component count, plus the document itself only when requested.
+type SBOMListItem struct {
+	ID             string      `boil:"id" json:"id"`
+	ProjectID      null.Int    `boil:"project_id" json:"project_id,omitempty"`
+	ProjectName    string      `boil:"project_name" json:"project_name"`
+	ManifestName   null.String `boil:"manifest_name" json:"manifest_name,omitempty"`
+	Source         string      `boil:"source" json:"source"`
+	Summary        null.JSON   `boil:"summary" json:"summary,omitempty"`
+	ObjectURL      null.String `boil:"object_url" json:"-"`
+	HasObject      bool        `boil:"-" json:"has_object"`
+	CreatedAt      null.Time   `boil:"created_at" json:"created_at,omitempty"`
+	UpdatedAt      null.Time   `boil:"updated_at" json:"updated_at,omitempty"`
+	ComponentCount int         `boil:"component_count" json:"component_count"`
+	Sbom           types.JSON  `boil:"sbom" json:"sbom,omitempty"`
+}
+
+// SBOMPage is one page of ListSBOM. NextCursor is empty on the last page.
+type SBOMPage struct {
+	Items      []*SBOMListItem
+	NextCursor string
+	Total      int64
+}
+
+type sbomCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// ValidSBOMSort reports whether key is an accepted sort field.
+func ValidSBOMSort(key string) bool {
+	_, ok := sbomSortExprs[key]
+	return ok
+}
+
+// ListSBOM returns SBOMs of the organization using keyset pagination over
+// (sort value, id), so pages stay stable while new SBOMs arrive.
+func ListSBOM(ctx context.Context, db *sql.DB, orgID int, q SBOMListQuery) (*SBOMPage, error) {
+	if q.Sort == "" {
+		q.Sort = "created_at"
+	}
+	sortExpr, ok := sbomSortExprs[q.Sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort field %q", q.Sort)
+	}
+	q.Order = strings.ToLower(q.Order)
+	if q.Order != "asc" {
+		q.Order = "desc"
+	}
+	if q.Limit <= 0 || q.Limit > 200 {
+		q.Limit = 50
+	}
+
+	filters := []qm.QueryMod{
+		qm.Where(orgSBOMFilterWhere, orgID),
+	}
+	if q.Project != "" {
+		filters = append(filters, qm.Where("sboms.project_name = ?", q.Project))
+	}
+	if q.Source != "" && q.Source != "all" {
+		filters = append(filters, qm.Where("LOWER(sboms.source) = ?", strings.ToLower(q.Source)))
+	}
+	if q.Manifest != "" {
+		filters = append(filters, qm.Where("LOWER(sboms.manifest_name) = LOWER(?)", q.Manifest))
+	}
+	if q.Ecosystem != "" {
+		eco := strings.ToLower(q.Ecosystem)
+		if eco == "go" {
+			eco = "golang"
+		}
+		filters = append(filters, qm.Where(`EXISTS (
+			SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(sboms.sbom->'components') = 'array'
+				THEN sboms.sbom->'components' ELSE '[]'::jsonb END) c
+			WHERE LOWER(c->>'purl') LIKE 'pkg:' || ? || '/%'
+		)`, eco))
+	}
+	if q.From != nil {
+		filters = append(filters, qm.Where("sboms.created_at >= ?", *q.From))
+	}
+	if q.To != nil {
+		filters = append(filters, qm.Where("sboms.created_at < ?", *q.To))
+	}
+	if q.MinComponents != nil {
+		filters = append(filters, qm.Where(componentCountExpr+" >= ?", *q.MinComponents))
+	}
+	if q.MaxComponents != nil {
+		filters = append(filters, qm.Where(componentCountExpr+" <= ?", *q.MaxComponents))
+	}
+
+	mods := append([]qm.QueryMod{}, filters...)
+	if q.Cursor != "" {
+		cur, err := decodeSBOMCursor(q.Cursor)
+		if err != nil || cur.Sort != q.Sort || cur.Order != q.Order {
+			return nil, ErrInvalidCursor
+		}
+		value, err := cursorValue(q.Sort, cur.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		op := "<"
+		if q.Order == "asc" {
+			op = ">"
+		}
+		mods = append(mods, qm.Where(fmt.Sprintf("(%s, sboms.id) %s (?, ?)", sortExpr, op), value, cur.ID))
+	}
+
+	total, err := models.Sboms(filters...).Count(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := []string{
+		"sboms.id", "sboms.project_id", "sboms.project_name", "sboms.manifest_name", "sboms.source",
+		"sboms.summary", "sboms.object_url", "sboms.created_at", "sboms.updated_at",
+		componentCountExpr + " AS component_count",
+	}
+	if q.IncludeSBOM {
+		columns = append(columns, "sboms.sbom")
+	}
+	mods = append(mods,
+		qm.Select(columns...),
+		qm.OrderBy(fmt.Sprintf("%s %s, sboms.id %s", sortExpr, q.Order, q.Order)),
+		qm.Limit(q.Limit+1),
+	)
+
+	var items []*SBOMListItem
+	if err := models.Sboms(mods...).Bind(ctx, db, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*SBOMListItem{}
+	}
+	for _, it := range items {
+		it.HasObject = it.ObjectURL.Valid && it.ObjectURL.String != ""
+	}
+
+	page := &SBOMPage{Items: items, Total: total}
+	if len(items) > q.Limit {
+		page.Items = items[:q.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = encodeSBOMCursor(sbomCursor{
+			Sort:  q.Sort,
+			Order: q.Order,
+			Value: sortValueOf(q.Sort, last),
+			ID:    last.ID,
+		})
+	}
+	return page, nil
+}
+
+func encodeSBOMCursor(c sbomCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeSBOMCursor(s string) (sbomCursor, error) {
+	var c sbomCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(raw, &c)
+	if err == nil && c.ID == "" {
+		err = ErrInvalidCursor
+	}
+	return c, err
+}
+
+// sortValueOf renders the sort key of an SBOM the same way the SQL
+// expression in sbomSortExprs computes it.
+func sortValueOf(sort string, s *SBOMListItem) string {
+	epoch := time.Unix(0, 0).UTC()
+	switch sort {
+	case "created_at":
+		if s.CreatedAt.Valid {
+			return s.CreatedAt.Time.Format(time.RFC3339Nano)
+		}
+		return epoch.Format(time.RFC3339Nano)
+	case "updated_at":
+		switch {
+		case s.UpdatedAt.Valid:
+			return s.UpdatedAt.Time.Format(time.RFC3339Nano)
+		case s.CreatedAt.Valid:
+			return s.CreatedAt.Time.Format(time.RFC3339Nano)
+		}
+		return epoch.Format(time.RFC3339Nano)
+	case "project_name":
+		return s.ProjectName
+	case "manifest_name":
+		return s.ManifestName.String
+	case "components":
+		return strconv.Itoa(s.ComponentCount)
+	}
+	return ""
+}
+
+func cursorValue(sort, v string) (interface{}, error) {
+	switch sort {
+	case "created_at", "updated_at":
+		return time.Parse(time.RFC3339Nano, v)
+	case "components":
+		return strconv.Atoi(v)
+	}
+	return v, nil
+}
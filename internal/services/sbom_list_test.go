@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSBOM_KeysetPaging(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	cursor := encodeSBOMCursor(sbomCursor{Sort: "created_at", Order: "desc", Value: created.Format(time.RFC3339Nano), ID: "sb-9"})
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM "sboms" WHERE \(\s+EXISTS \(\s+SELECT 1 FROM projects p\s+WHERE p.id = sboms.project_id\s+AND p.organization_id = \$1\s+\)\s+\)`).
+		WithArgs(3, "manual").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT "sboms"."id", .*AS component_count FROM "sboms".*\(COALESCE\(sboms.created_at, 'epoch'::timestamp\), sboms.id\) < \(\$3, \$4\)`).
+		WithArgs(3, "manual", created, "sb-9").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "project_name", "source", "summary", "created_at", "component_count"}).
+			AddRow("sb-8", "shop", "manual", []byte(`{"total":4}`), created.Add(-time.Hour), 4).
+			AddRow("sb-7", "shop", "manual", []byte(`{}`), created.Add(-2*time.Hour), 1))
+
+	page, err := ListSBOM(context.Background(), sqlDB, 3, SBOMListQuery{Source: "manual", Limit: 1, Cursor: cursor})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, page.Total)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, "sb-8", page.Items[0].ID)
+	require.Equal(t, 4, page.Items[0].ComponentCount)
+	require.Nil(t, page.Items[0].Sbom)
+
+	next, err := decodeSBOMCursor(page.NextCursor)
+	require.NoError(t, err)
+	require.Equal(t, "sb-8", next.ID)
+	require.Equal(t, created.Add(-time.Hour).Format(time.RFC3339Nano), next.Value)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListSBOM_CursorSortMismatch(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	cursor := encodeSBOMCursor(sbomCursor{Sort: "created_at", Order: "desc", Value: "x", ID: "a"})
+	_, err = ListSBOM(context.Background(), sqlDB, 3, SBOMListQuery{Sort: "project_name", Cursor: cursor})
+	require.ErrorIs(t, err, ErrInvalidCursor)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,9 +3,14 @@ package services
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +18,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/gofrs/uuid"
 )
 
@@ -34,6 +40,27 @@ func UploadSBOMJSON(ctx context.Context, orgID, projectID int, projectName, mani
 	}
 
 	key := buildSBOMObjectKey(orgID, projectID, manifestName)
+	s3Client, err := newS3Client(ctx)
+	if err != nil {
+		return "", nil
+	}
+
+	_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(sbomJSON),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return "", nil
+	}
+
+	return buildSBOMObjectURL(bucket, key), nil
+}
+
+// newS3Client builds a client from the S3_* environment, honouring
+// S3_ENDPOINT for S3-compatible stores.
+func newS3Client(ctx context.Context) (*s3.Client, error) {
 	awsCfg, err := config.LoadDefaultConfig(ctx,
 		config.WithRegion("us-east-2"),
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
@@ -43,7 +70,7 @@ func UploadSBOMJSON(ctx context.Context, orgID, projectID int, projectName, mani
 		)),
 	)
 	if err != nil {
-		return "", nil
+		return nil, err
 	}
 
 	if ep := os.Getenv("S3_ENDPOINT"); ep != "" {
@@ -55,19 +82,245 @@ func UploadSBOMJSON(ctx context.Context, orgID, projectID int, projectName, mani
 				}, nil
 			})
 	}
+	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		// S3-compatible stores (MinIO, localstack) address buckets by path.
+		o.UsePathStyle = os.Getenv("S3_ENDPOINT") != ""
+	}), nil
+}
 
-	s3Client := s3.NewFromConfig(awsCfg)
-	_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(sbomJSON),
-		ContentType: aws.String("application/json"),
+// DefaultPresignTTL applies when S3_PRESIGN_TTL is unset or invalid.
+const DefaultPresignTTL = 15 * time.Minute
+
+// maxPresignTTL is the longest lifetime SigV4 accepts for a presigned URL.
+const maxPresignTTL = 7 * 24 * time.Hour
+
+// PresignTTL returns the configured lifetime of presigned SBOM URLs, read from
+// S3_PRESIGN_TTL as a Go duration ("10m") or a number of seconds.
+func PresignTTL() time.Duration {
+	raw := strings.TrimSpace(os.Getenv("S3_PRESIGN_TTL"))
+	if raw == "" {
+		return DefaultPresignTTL
+	}
+	ttl, err := time.ParseDuration(raw)
+	if err != nil {
+		secs, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			return DefaultPresignTTL
+		}
+		ttl = time.Duration(secs) * time.Second
+	}
+	if ttl <= 0 {
+		return DefaultPresignTTL
+	}
+	if ttl > maxPresignTTL {
+		return maxPresignTTL
+	}
+	return ttl
+}
+
+// PresignSBOMObject returns a short-lived GET URL for the object behind
+// objectURL. Objects stay private; this is the only way clients reach them.
+func PresignSBOMObject(ctx context.Context, objectURL string, ttl time.Duration) (string, time.Time, error) {
+	bucket := os.Getenv("S3_BUCKET")
+	key := objectKeyFromURL(bucket, objectURL)
+	if bucket == "" || key == "" {
+		return "", time.Time{}, ErrSBOMObjectNotFound
+	}
+	s3Client, err := newS3Client(ctx)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req, err := s3.NewPresignClient(s3Client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:                     aws.String(bucket),
+		Key:                        aws.String(key),
+		ResponseContentType:        aws.String("application/json"),
+		ResponseContentDisposition: aws.String("attachment"),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return req.URL, time.Now().UTC().Add(ttl), nil
+}
+
+// ErrSBOMObjectNotFound means the SBOM has no readable object in storage.
+var ErrSBOMObjectNotFound = errors.New("sbom object not found")
+
+// OpenSBOMObject streams the stored object behind objectURL. Callers fall back
+// to the Postgres copy on any error.
+func OpenSBOMObject(ctx context.Context, objectURL string) (io.ReadCloser, error) {
+	bucket := os.Getenv("S3_BUCKET")
+	key := objectKeyFromURL(bucket, objectURL)
+	if bucket == "" || key == "" {
+		return nil, ErrSBOMObjectNotFound
+	}
+	s3Client, err := newS3Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
 	})
 	if err != nil {
-		return "", nil
+		var noKey *types.NoSuchKey
+		if errors.As(err, &noKey) {
+			return nil, ErrSBOMObjectNotFound
+		}
+		return nil, err
 	}
+	return out.Body, nil
+}
 
-	return buildSBOMObjectURL(bucket, key), nil
+// DeleteSBOMObject removes the stored object behind objectURL. A missing
+// bucket configuration or object is not an error.
+func DeleteSBOMObject(ctx context.Context, objectURL string) error {
+	bucket := os.Getenv("S3_BUCKET")
+	key := objectKeyFromURL(bucket, objectURL)
+	if bucket == "" || key == "" {
+		return nil
+	}
+	s3Client, err := newS3Client(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		log.Printf("[STORAGE][ERR] delete object %s: %v", key, err)
+		return err
+	}
+	return nil
+}
+
+// StoredObject is one SBOM revision in object storage.
+type StoredObject struct {
+	Key          string
+	LastModified time.Time
+}
+
+// ListSBOMObjects lists every object under prefix. It returns nothing when
+// S3 is not configured.
+func ListSBOMObjects(ctx context.Context, prefix string) ([]StoredObject, error) {
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" {
+		return nil, nil
+	}
+	s3Client, err := newS3Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []StoredObject
+	pages := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			out = append(out, StoredObject{Key: aws.ToString(obj.Key), LastModified: aws.ToTime(obj.LastModified)})
+		}
+	}
+	return out, nil
+}
+
+// DeleteSBOMObjectKeys removes objects in batches of up to 1000 keys and
+// returns how many were deleted.
+func DeleteSBOMObjectKeys(ctx context.Context, keys []string) (int, error) {
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" || len(keys) == 0 {
+		return 0, nil
+	}
+	s3Client, err := newS3Client(ctx)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for start := 0; start < len(keys); start += 1000 {
+		end := start + 1000
+		if end > len(keys) {
+			end = len(keys)
+		}
+		ids := make([]types.ObjectIdentifier, 0, end-start)
+		for _, k := range keys[start:end] {
+			ids = append(ids, types.ObjectIdentifier{Key: aws.String(k)})
+		}
+		out, err := s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{Objects: ids, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += len(ids) - len(out.Errors)
+		for _, e := range out.Errors {
+			log.Printf("[STORAGE][ERR] delete object %s: %s", aws.ToString(e.Key), aws.ToString(e.Message))
+		}
+	}
+	return deleted, nil
+}
+
+// CopySBOMObject copies an object to a new key within the bucket.
+func CopySBOMObject(ctx context.Context, srcKey, dstKey string) error {
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" {
+		return nil
+	}
+	s3Client, err := newS3Client(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(bucket),
+		CopySource: aws.String(escapeCopySource(bucket + "/" + srcKey)),
+		Key:        aws.String(dstKey),
+	})
+	return err
+}
+
+func escapeCopySource(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// objectKeyFromURL recovers the object key from an sboms.object_url value,
+// including the public-looking URLs stored before s3:// references.
+func objectKeyFromURL(bucket, objectURL string) string {
+	objectURL = strings.TrimSpace(objectURL)
+	if objectURL == "" {
+		return ""
+	}
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return ""
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if bucket != "" {
+		key = strings.TrimPrefix(key, bucket+"/")
+	}
+	if i := strings.Index(key, "sbom/org-"); i > 0 {
+		key = key[i:]
+	}
+	if !strings.HasPrefix(key, "sbom/") {
+		return ""
+	}
+	return key
+}
+
+// SBOMDownloadFilename builds a Content-Disposition friendly file name from
+// the project and manifest names.
+func SBOMDownloadFilename(projectName, manifestName string) string {
+	name := sanitizePathSegment(projectName)
+	if m := strings.TrimSpace(manifestName); m != "" {
+		name += "-" + sanitizePathSegment(m)
+	}
+	return name + ".sbom.json"
 }
 
 func buildSBOMObjectKey(orgID, projectID int, manifestName string) string {
@@ -105,12 +358,8 @@ func sanitizePathSegment(s string) string {
 	}, s)
 }
 
+// buildSBOMObjectURL returns the internal reference stored in sboms.object_url.
+// It is never handed to clients; they get presigned URLs instead.
 func buildSBOMObjectURL(bucket, key string) string {
-	if publicBase := strings.TrimSpace(os.Getenv("S3_PUBLIC_URL")); publicBase != "" {
-		return fmt.Sprintf("%s/%s", strings.TrimRight(publicBase, "/"), key)
-	}
-	if ep := strings.TrimSpace(os.Getenv("S3_ENDPOINT")); ep != "" {
-		return fmt.Sprintf("%s/%s/%s", strings.TrimRight(ep, "/"), bucket, key)
-	}
-	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", bucket, key)
+	return fmt.Sprintf("s3://%s/%s", bucket, key)
 }
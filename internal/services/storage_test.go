@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectKeyFromURL(t *testing.T) {
+	key := "sbom/org-3/project-9/package.json/1-abc.json"
+	require.Equal(t, key, objectKeyFromURL("bom", "https://bom.s3.amazonaws.com/"+key))
+	require.Equal(t, key, objectKeyFromURL("bom", "http://localhost:9000/bom/"+key))
+	require.Equal(t, key, objectKeyFromURL("bom", "https://cdn.example.com/assets/"+key))
+	require.Empty(t, objectKeyFromURL("bom", "https://bom.s3.amazonaws.com/other/file.json"))
+	require.Empty(t, objectKeyFromURL("bom", ""))
+}
+
+func TestObjectKeyFromURL_S3Reference(t *testing.T) {
+	key := "sbom/org-3/project-9/package.json/1-abc.json"
+	require.Equal(t, key, objectKeyFromURL("bom", buildSBOMObjectURL("bom", key)))
+}
+
+func TestPresignTTL(t *testing.T) {
+	t.Setenv("S3_PRESIGN_TTL", "")
+	require.Equal(t, DefaultPresignTTL, PresignTTL())
+	t.Setenv("S3_PRESIGN_TTL", "90s")
+	require.Equal(t, 90*time.Second, PresignTTL())
+	t.Setenv("S3_PRESIGN_TTL", "600")
+	require.Equal(t, 10*time.Minute, PresignTTL())
+	t.Setenv("S3_PRESIGN_TTL", "720h")
+	require.Equal(t, maxPresignTTL, PresignTTL())
+}
+
+func TestPresignSBOMObject_LocalEndpoint(t *testing.T) {
+	t.Setenv("S3_BUCKET", "bom")
+	t.Setenv("S3_ENDPOINT", "http://localhost:9000")
+	t.Setenv("S3_ACCESS_KEY", "minio")
+	t.Setenv("S3_SECRET_KEY", "minio123")
+
+	key := "sbom/org-3/project-9/package.json/1-abc.json"
+	u, expires, err := PresignSBOMObject(context.Background(), buildSBOMObjectURL("bom", key), 5*time.Minute)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(u, "http://localhost:9000/bom/"+key+"?"), u)
+	require.Contains(t, u, "X-Amz-Expires=300")
+	require.WithinDuration(t, time.Now().Add(5*time.Minute), expires, 5*time.Second)
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"myesi-sbom-service-golang/internal/db"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// Kinds of supply-chain findings recorded per SBOM.
+const (
+	FindingTyposquat           = "typosquat"
+	FindingDependencyConfusion = "dependency_confusion"
+)
+
+//go:embed data/popular_packages.json
+var popularPackagesJSON []byte
+
+var (
+	popularOnce     sync.Once
+	popularPackages map[string][]string
+	popularSets     map[string]map[string]struct{}
+)
+
+// publicRegistryHosts are the default registries purls resolve to when they
+// carry no repository_url qualifier.
+var publicRegistryHosts = map[string]struct{}{
+	"registry.npmjs.org":     {},
+	"registry.yarnpkg.com":   {},
+	"pypi.org":               {},
+	"files.pythonhosted.org": {},
+	"repo.maven.apache.org":  {},
+	"repo1.maven.org":        {},
+	"proxy.golang.org":       {},
+	"crates.io":              {},
+	"rubygems.org":           {},
+	"api.nuget.org":          {},
+	"packagist.org":          {},
+}
+
+// SupplyChainFinding is a suspicious component found in an SBOM.
+type SupplyChainFinding struct {
+	Kind             string  `json:"kind"`
+	ComponentName    string  `json:"component_name"`
+	ComponentVersion string  `json:"component_version"`
+	Ecosystem        string  `json:"ecosystem"`
+	Purl             string  `json:"purl,omitempty"`
+	SimilarTo        string  `json:"similar_to,omitempty"`
+	Distance         float64 `json:"distance,omitempty"`
+	Reason           string  `json:"reason"`
+}
+
+// InternalPackage is a package name an organization publishes privately. A
+// trailing "*" matches by prefix (e.g. "@acme/*"); an empty ecosystem matches
+// any ecosystem.
+type InternalPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// Matches reports whether a component name in ecosystem is covered.
+func (p InternalPackage) Matches(ecosystem, name string) bool {
+	if p.Ecosystem != "" && !strings.EqualFold(p.Ecosystem, ecosystem) {
+		return false
+	}
+	pattern := strings.ToLower(strings.TrimSpace(p.Name))
+	name = strings.ToLower(name)
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return prefix != "" && strings.HasPrefix(name, prefix)
+	}
+	return pattern == name
+}
+
+func loadPopularPackages() {
+	popularOnce.Do(func() {
+		popularPackages = map[string][]string{}
+		if err := json.Unmarshal(popularPackagesJSON, &popularPackages); err != nil {
+			log.Printf("[SUPPLY][ERR] invalid popular package list: %v", err)
+		}
+		popularSets = make(map[string]map[string]struct{}, len(popularPackages))
+		for eco, names := range popularPackages {
+			set := make(map[string]struct{}, len(names))
+			for _, n := range names {
+				set[popularKey(eco, n)] = struct{}{}
+			}
+			popularSets[eco] = set
+		}
+	})
+}
+
+// DetectSupplyChainRisks flags components whose names are likely typosquats
+// of popular packages and internal package names resolved from a public
+// registry.
+func DetectSupplyChainRisks(comps []map[string]string, internal []InternalPackage) []SupplyChainFinding {
+	loadPopularPackages()
+
+	var findings []SupplyChainFinding
+	seen := map[string]struct{}{}
+	for _, comp := range comps {
+		eco := comp["type"]
+		name := componentFullName(comp)
+		if name == "" {
+			continue
+		}
+		key := eco + "|" + strings.ToLower(name) + "@" + comp["version"]
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		base := SupplyChainFinding{
+			ComponentName:    name,
+			ComponentVersion: comp["version"],
+			Ecosystem:        eco,
+			Purl:             comp["purl"],
+		}
+
+		isInternal := false
+		for _, p := range internal {
+			if p.Matches(eco, name) {
+				isInternal = true
+				if host, public := publicRegistry(comp["purl"]); public {
+					f := base
+					f.Kind = FindingDependencyConfusion
+					f.SimilarTo = p.Name
+					f.Reason = fmt.Sprintf("internal package %q resolved from public registry %s", name, host)
+					findings = append(findings, f)
+				}
+				break
+			}
+		}
+		if isInternal {
+			continue
+		}
+
+		if target, dist, ok := closestPopular(eco, name); ok {
+			f := base
+			f.Kind = FindingTyposquat
+			f.SimilarTo = target
+			f.Distance = dist
+			f.Reason = fmt.Sprintf("%q is %.1f edits away from popular package %q", name, dist, target)
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// componentFullName prefers the registry name from the purl so Maven groups
+// and npm scopes take part in the comparison.
+func componentFullName(comp map[string]string) string {
+	if p, ok := ParsePackageURL(comp["purl"]); ok {
+		return p.FullName()
+	}
+	return strings.TrimSpace(comp["name"])
+}
+
+// publicRegistry reports whether a purl resolves from a public registry; a
+// purl without repository_url uses its ecosystem's default registry.
+func publicRegistry(purl string) (string, bool) {
+	p, ok := ParsePackageURL(purl)
+	if !ok {
+		return "", false
+	}
+	repo := p.Qualifiers["repository_url"]
+	if repo == "" {
+		return "default " + p.Type + " registry", true
+	}
+	if !strings.Contains(repo, "://") {
+		repo = "https://" + repo
+	}
+	u, err := url.Parse(repo)
+	if err != nil {
+		return "", false
+	}
+	host := strings.ToLower(u.Hostname())
+	_, public := publicRegistryHosts[host]
+	return host, public
+}
+
+// closestPopular returns the popular package name nearest to name when it is
+// close enough to look like a typo. Popular packages themselves never match.
+func closestPopular(eco, name string) (string, float64, bool) {
+	set := popularSets[eco]
+	if len(set) == 0 {
+		return "", 0, false
+	}
+	lower := popularKey(eco, name)
+	if eco == "golang" {
+		lower = goMajorSuffix.ReplaceAllString(lower, "")
+	}
+	if _, ok := set[lower]; ok {
+		return "", 0, false
+	}
+
+	best, bestDist := "", 0.0
+	for _, candidate := range popularPackages[eco] {
+		cl := popularKey(eco, candidate)
+		if len(cl) < 4 || abs(len(cl)-len(lower)) > 2 {
+			continue
+		}
+		var d float64
+		if stripSeparators(cl) == stripSeparators(lower) {
+			d = 0.5
+		} else {
+			d = typoDistance(lower, cl)
+		}
+		threshold := 0.5
+		if len(cl) >= 5 {
+			threshold = 1
+		}
+		if d > threshold {
+			continue
+		}
+		if best == "" || d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	return best, bestDist, best != ""
+}
+
+// popularKey is the form names are compared in: lower case, and the PEP 503
+// name for PyPI, where python_dateutil and python-dateutil are one package.
+func popularKey(eco, name string) string {
+	if eco == "pypi" {
+		return pep503Name(name)
+	}
+	return strings.ToLower(name)
+}
+
+// goMajorSuffix strips the major-version element of a Go module path.
+var goMajorSuffix = regexp.MustCompile(`/v[0-9]+$`)
+
+func stripSeparators(s string) string {
+	return strings.NewReplacer("-", "", "_", "", ".", "").Replace(s)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+var keyboardPos = func() map[rune][2]int {
+	pos := map[rune][2]int{}
+	for r, row := range keyboardRows {
+		for c, ch := range row {
+			pos[ch] = [2]int{r, c}
+		}
+	}
+	return pos
+}()
+
+// lookalikes are characters commonly swapped to fool a reader.
+var lookalikes = map[[2]rune]struct{}{
+	{'l', '1'}: {}, {'1', 'l'}: {}, {'i', '1'}: {}, {'1', 'i'}: {}, {'i', 'l'}: {}, {'l', 'i'}: {},
+	{'o', '0'}: {}, {'0', 'o'}: {}, {'s', '5'}: {}, {'5', 's'}: {}, {'-', '_'}: {}, {'_', '-'}: {},
+}
+
+// substitutionCost is cheaper for neighbouring keys and lookalike characters.
+func substitutionCost(a, b rune) float64 {
+	if a == b {
+		return 0
+	}
+	if _, ok := lookalikes[[2]rune{a, b}]; ok {
+		return 0.5
+	}
+	pa, okA := keyboardPos[a]
+	pb, okB := keyboardPos[b]
+	if okA && okB && abs(pa[0]-pb[0]) <= 1 && abs(pa[1]-pb[1]) <= 1 {
+		return 0.5
+	}
+	return 1
+}
+
+// typoDistance is a Damerau-Levenshtein (optimal string alignment) distance
+// with keyboard-aware substitution costs and half-cost transpositions.
+func typoDistance(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]float64, len(ra)+1)
+	for i := range d {
+		d[i] = make([]float64, len(rb)+1)
+		d[i][0] = float64(i)
+	}
+	for j := range d[0] {
+		d[0][j] = float64(j)
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			best := d[i-1][j] + 1
+			if v := d[i][j-1] + 1; v < best {
+				best = v
+			}
+			if v := d[i-1][j-1] + substitutionCost(ra[i-1], rb[j-1]); v < best {
+				best = v
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				if v := d[i-2][j-2] + 0.5; v < best {
+					best = v
+				}
+			}
+			d[i][j] = best
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// LoadInternalPackages returns the internal package names configured for the
+// organization.
+func LoadInternalPackages(ctx context.Context, exec boil.ContextExecutor, orgID int) ([]InternalPackage, error) {
+	if exec == nil {
+		exec = db.Conn
+	}
+	rows, err := exec.QueryContext(ctx, `
+		SELECT COALESCE(ecosystem, ''), name
+		FROM organization_internal_packages
+		WHERE organization_id = $1
+		ORDER BY ecosystem, name
+	`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []InternalPackage{}
+	for rows.Next() {
+		var p InternalPackage
+		if err := rows.Scan(&p.Ecosystem, &p.Name); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// StoreSupplyChainFindings replaces the findings of an SBOM and queues a
+// sbom.supply_chain_risk event when there is anything to report.
+func StoreSupplyChainFindings(ctx context.Context, exec boil.ContextExecutor, sbomID, projectName string, projectID, orgID int, findings []SupplyChainFinding) error {
+	if exec == nil {
+		exec = db.Conn
+	}
+	if _, err := exec.ExecContext(ctx, `DELETE FROM sbom_supply_chain_findings WHERE sbom_id = $1`, sbomID); err != nil {
+		return fmt.Errorf("clear supply-chain findings: %w", err)
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	const insert = `
+		INSERT INTO sbom_supply_chain_findings
+			(sbom_id, organization_id, project_id, kind, component_name, component_version,
+			 ecosystem, purl, similar_to, distance, reason, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,NULLIF($8,''),NULLIF($9,''),$10,$11,NOW())
+	`
+	for _, f := range findings {
+		if _, err := exec.ExecContext(ctx, insert, sbomID, orgID, projectID, f.Kind, f.ComponentName,
+			f.ComponentVersion, f.Ecosystem, f.Purl, f.SimilarTo, f.Distance, f.Reason); err != nil {
+			return fmt.Errorf("store supply-chain finding: %w", err)
+		}
+	}
+
+	return EnqueueOutboxEvent(ctx, exec, OutboxMessage{
+		Topic:     KafkaTopic,
+		EventType: "sbom.supply_chain_risk",
+		Key:       sbomID,
+		Payload: map[string]interface{}{
+			"sbom_id":         sbomID,
+			"project_id":      projectID,
+			"project_name":    projectName,
+			"organization_id": orgID,
+			"findings":        findings,
+			"labels":          eventLabels(ctx, exec, projectID, sbomID),
+			"detected_at":     time.Now().UTC(),
+		},
+	})
+}
+
+// CheckSupplyChain runs the detector against an SBOM's components with the
+// organization's internal package list and stores the result.
+func CheckSupplyChain(ctx context.Context, exec boil.ContextExecutor, sbomID, projectName string, projectID, orgID int, comps []map[string]string) ([]SupplyChainFinding, error) {
+	internal, err := LoadInternalPackages(ctx, exec, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("load internal packages: %w", err)
+	}
+	findings := DetectSupplyChainRisks(comps, internal)
+	if err := StoreSupplyChainFindings(ctx, exec, sbomID, projectName, projectID, orgID, findings); err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
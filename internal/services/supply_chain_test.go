@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectSupplyChainRisks_Typosquats(t *testing.T) {
+	comps := []map[string]string{
+		{"name": "lodash", "version": "4.17.21", "type": "npm", "purl": "pkg:npm/lodash@4.17.21"},
+		{"name": "lodahs", "version": "1.0.0", "type": "npm", "purl": "pkg:npm/lodahs@1.0.0"},
+		{"name": "reqeusts", "version": "2.0.0", "type": "pypi"},
+		{"name": "python_dateutil", "version": "2.8.2", "type": "pypi"},
+		{"name": "Python.Dateutil", "version": "2.8.2", "type": "pypi"},
+		{"name": "1odash", "version": "0.0.1", "type": "npm"},
+		{"name": "fiber", "version": "v2.52.0", "type": "golang", "purl": "pkg:golang/github.com/gofiber/fiber/v2@v2.52.0"},
+		{"name": "left-pad", "version": "1.3.0", "type": "npm"},
+	}
+
+	findings := DetectSupplyChainRisks(comps, nil)
+	got := map[string]string{}
+	for _, f := range findings {
+		require.Equal(t, FindingTyposquat, f.Kind)
+		got[f.ComponentName] = f.SimilarTo
+	}
+	require.Equal(t, map[string]string{
+		"lodahs":          "lodash",
+		"reqeusts":        "requests",
+		"1odash":          "lodash",
+	}, got)
+}
+
+func TestDetectSupplyChainRisks_DependencyConfusion(t *testing.T) {
+	internal := []InternalPackage{
+		{Ecosystem: "npm", Name: "@acme/*"},
+		{Name: "acme-billing"},
+	}
+	comps := []map[string]string{
+		{"name": "core", "version": "9.9.9", "type": "npm", "purl": "pkg:npm/%40acme/core@9.9.9"},
+		{"name": "ui", "version": "1.0.0", "type": "npm", "purl": "pkg:npm/%40acme/ui@1.0.0?repository_url=https://npm.acme.internal"},
+		{"name": "acme-billing", "version": "0.1.0", "type": "pypi", "purl": "pkg:pypi/acme-billing@0.1.0?repository_url=pypi.org"},
+		{"name": "acme-billing", "version": "0.1.0", "type": "pypi"},
+	}
+
+	findings := DetectSupplyChainRisks(comps, internal)
+	require.Len(t, findings, 2)
+	require.Equal(t, FindingDependencyConfusion, findings[0].Kind)
+	require.Equal(t, "@acme/core", findings[0].ComponentName)
+	require.Equal(t, "@acme/*", findings[0].SimilarTo)
+	require.Equal(t, "acme-billing", findings[1].ComponentName)
+	require.Contains(t, findings[1].Reason, "pypi.org")
+}
+
+func TestTypoDistance(t *testing.T) {
+	require.Equal(t, 0.0, typoDistance("express", "express"))
+	require.Equal(t, 0.5, typoDistance("exprezs", "express"))
+	require.Equal(t, 0.5, typoDistance("epxress", "express"))
+	require.Equal(t, 1.0, typoDistance("expres", "express"))
+	require.Equal(t, 1.0, typoDistance("exprqss", "express"))
+}
@@ -0,0 +1,219 @@
+package versioning
+
+import "strings"
+
+// compareDebian implements dpkg's comparison of [epoch:]upstream[-revision].
+func compareDebian(a, b string) int {
+	aEpoch, aUp, aRev := splitDebian(a)
+	bEpoch, bUp, bRev := splitDebian(b)
+	if aEpoch != bEpoch {
+		return aEpoch - bEpoch
+	}
+	if c := dpkgVerRevCmp(aUp, bUp); c != 0 {
+		return c
+	}
+	return dpkgVerRevCmp(aRev, bRev)
+}
+
+func splitDebian(v string) (int, string, string) {
+	epoch := 0
+	if i := strings.Index(v, ":"); i >= 0 {
+		epoch = atoiDefault(v[:i], 0)
+		v = v[i+1:]
+	}
+	rev := ""
+	if i := strings.LastIndex(v, "-"); i >= 0 {
+		rev = v[i+1:]
+		v = v[:i]
+	}
+	return epoch, v, rev
+}
+
+// dpkgOrder ranks a character for dpkg: '~' sorts before everything, even the
+// end of the string, letters before other symbols.
+func dpkgOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case isASCIIDigit(c):
+		return 0
+	case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func dpkgVerRevCmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		firstDiff := 0
+		for (i < len(a) && !isASCIIDigit(a[i])) || (j < len(b) && !isASCIIDigit(b[j])) {
+			ac, bc := dpkgOrder(a, i), dpkgOrder(b, j)
+			if ac != bc {
+				return ac - bc
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		for i < len(a) && j < len(b) && isASCIIDigit(a[i]) && isASCIIDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isASCIIDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isASCIIDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
+
+// compareRPMEVR compares [epoch:]version[-release] strings with rpmvercmp.
+func compareRPMEVR(a, b string) int {
+	aEpoch, aVer, aRel := splitRPM(a)
+	bEpoch, bVer, bRel := splitRPM(b)
+	if aEpoch != bEpoch {
+		return aEpoch - bEpoch
+	}
+	if c := rpmVerCmp(aVer, bVer); c != 0 {
+		return c
+	}
+	if aRel == "" || bRel == "" {
+		return 0
+	}
+	return rpmVerCmp(aRel, bRel)
+}
+
+func splitRPM(v string) (int, string, string) {
+	epoch := 0
+	if i := strings.Index(v, ":"); i >= 0 {
+		epoch = atoiDefault(v[:i], 0)
+		v = v[i+1:]
+	}
+	rel := ""
+	if i := strings.LastIndex(v, "-"); i >= 0 {
+		rel = v[i+1:]
+		v = v[:i]
+	}
+	return epoch, v, rel
+}
+
+func isASCIIAlnum(c byte) bool {
+	return isASCIIDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// rpmVerCmp is a port of librpm's rpmvercmp, including '~' (sorts before
+// anything) and '^' (sorts after the base version but before any addition).
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isASCIIAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+		for j < len(b) && !isASCIIAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+
+		aTilde := i < len(a) && a[i] == '~'
+		bTilde := j < len(b) && b[j] == '~'
+		if aTilde || bTilde {
+			if !aTilde {
+				return 1
+			}
+			if !bTilde {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		aCaret := i < len(a) && a[i] == '^'
+		bCaret := j < len(b) && b[j] == '^'
+		if aCaret || bCaret {
+			if i >= len(a) {
+				return -1
+			}
+			if j >= len(b) {
+				return 1
+			}
+			if !aCaret {
+				return 1
+			}
+			if !bCaret {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		si, sj := i, j
+		isNum := isASCIIDigit(a[i])
+		if isNum {
+			for i < len(a) && isASCIIDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isASCIIDigit(b[j]) {
+				j++
+			}
+		} else {
+			for i < len(a) && isASCIIAlnum(a[i]) && !isASCIIDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isASCIIAlnum(b[j]) && !isASCIIDigit(b[j]) {
+				j++
+			}
+		}
+		segA, segB := a[si:i], b[sj:j]
+		if segB == "" {
+			// Segments of different types: numeric is newer.
+			if isNum {
+				return 1
+			}
+			return -1
+		}
+		if isNum {
+			if c := compareDigits(segA, segB); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := strings.Compare(segA, segB); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case i >= len(a) && j >= len(b):
+		return 0
+	case i < len(a):
+		return 1
+	default:
+		return -1
+	}
+}
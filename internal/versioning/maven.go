@@ -0,0 +1,214 @@
+package versioning
+
+import (
+	"strconv"
+	"strings"
+)
+
+// mavenItem is one element of a parsed Maven version: an integer, a qualifier
+// string or a nested list (started by '-' or a digit/letter transition).
+type mavenItem struct {
+	kind  int // mavenInt, mavenString or mavenList
+	num   string
+	str   string
+	items []*mavenItem
+}
+
+const (
+	mavenInt = iota
+	mavenString
+	mavenList
+)
+
+// mavenQualifiers lists well-known qualifiers in ascending order; "" is the
+// release itself. Unknown qualifiers sort after all of these, lexically.
+var mavenQualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+var mavenAliases = map[string]string{
+	"ga":      "",
+	"final":   "",
+	"release": "",
+	"cr":      "rc",
+}
+
+// compareMaven implements org.apache.maven.artifact.versioning.ComparableVersion.
+func compareMaven(a, b string) int {
+	return parseMaven(a).compare(parseMaven(b))
+}
+
+func parseMaven(v string) *mavenItem {
+	v = strings.ToLower(v)
+	root := &mavenItem{kind: mavenList}
+	list := root
+	var stack []*mavenItem
+	stack = append(stack, list)
+
+	isDigit := false
+	start := 0
+	newList := func() {
+		sub := &mavenItem{kind: mavenList}
+		list.items = append(list.items, sub)
+		list = sub
+		stack = append(stack, list)
+	}
+
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c == '.':
+			if i == start {
+				list.items = append(list.items, &mavenItem{kind: mavenInt, num: "0"})
+			} else {
+				list.items = append(list.items, newMavenItem(isDigit, v[start:i], false))
+			}
+			start = i + 1
+		case c == '-':
+			if i == start {
+				list.items = append(list.items, &mavenItem{kind: mavenInt, num: "0"})
+			} else {
+				list.items = append(list.items, newMavenItem(isDigit, v[start:i], false))
+			}
+			start = i + 1
+			newList()
+		case isASCIIDigit(c):
+			if !isDigit && i > start {
+				list.items = append(list.items, newMavenItem(false, v[start:i], true))
+				start = i
+				newList()
+			}
+			isDigit = true
+		default:
+			if isDigit && i > start {
+				list.items = append(list.items, newMavenItem(true, v[start:i], false))
+				start = i
+				newList()
+			}
+			isDigit = false
+		}
+	}
+	if len(v) > start {
+		list.items = append(list.items, newMavenItem(isDigit, v[start:], false))
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		stack[i].normalize()
+	}
+	return root
+}
+
+func newMavenItem(isDigit bool, s string, followedByDigit bool) *mavenItem {
+	if isDigit {
+		return &mavenItem{kind: mavenInt, num: strings.TrimLeft(s, "0")}
+	}
+	if followedByDigit && len(s) == 1 {
+		switch s {
+		case "a":
+			s = "alpha"
+		case "b":
+			s = "beta"
+		case "m":
+			s = "milestone"
+		}
+	}
+	if alias, ok := mavenAliases[s]; ok {
+		s = alias
+	}
+	return &mavenItem{kind: mavenString, str: s}
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (m *mavenItem) isNull() bool {
+	switch m.kind {
+	case mavenInt:
+		return m.num == "" || m.num == "0"
+	case mavenString:
+		return m.str == ""
+	default:
+		return len(m.items) == 0
+	}
+}
+
+// normalize drops trailing null items ("1.0.0" == "1").
+func (m *mavenItem) normalize() {
+	for i := len(m.items) - 1; i >= 0; i-- {
+		last := m.items[i]
+		if last.isNull() {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+		} else if last.kind != mavenList {
+			break
+		}
+	}
+}
+
+func comparableQualifier(q string) string {
+	for i, known := range mavenQualifiers {
+		if q == known {
+			return strconv.Itoa(i)
+		}
+	}
+	return strconv.Itoa(len(mavenQualifiers)) + "-" + q
+}
+
+// compare orders m against other; a nil other stands for a padding item.
+func (m *mavenItem) compare(other *mavenItem) int {
+	switch m.kind {
+	case mavenInt:
+		if other == nil {
+			if m.isNull() {
+				return 0
+			}
+			return 1
+		}
+		switch other.kind {
+		case mavenInt:
+			return compareDigits(m.num, other.num)
+		default:
+			return 1
+		}
+	case mavenString:
+		if other == nil {
+			return strings.Compare(comparableQualifier(m.str), comparableQualifier(""))
+		}
+		switch other.kind {
+		case mavenString:
+			return strings.Compare(comparableQualifier(m.str), comparableQualifier(other.str))
+		default:
+			return -1
+		}
+	default:
+		if other == nil {
+			if len(m.items) == 0 {
+				return 0
+			}
+			return m.items[0].compare(nil)
+		}
+		switch other.kind {
+		case mavenInt:
+			return -1
+		case mavenString:
+			return 1
+		}
+		for i := 0; i < len(m.items) || i < len(other.items); i++ {
+			var l, r *mavenItem
+			if i < len(m.items) {
+				l = m.items[i]
+			}
+			if i < len(other.items) {
+				r = other.items[i]
+			}
+			var c int
+			if l == nil {
+				c = -r.compare(nil)
+			} else {
+				c = l.compare(r)
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+}
@@ -0,0 +1,112 @@
+package versioning
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// pep440Pattern is the canonical regular expression from PEP 440, Appendix B.
+var pep440Pattern = regexp.MustCompile(`^\s*v?` +
+	`(?:(\d+)!)?` + // epoch
+	`(\d+(?:\.\d+)*)` + // release
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d+)?)?` + // pre
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?` + // post
+	`(?:[-_.]?(dev)[-_.]?(\d+)?)?` + // dev
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?\s*$`) // local
+
+type pep440Version struct {
+	epoch   int
+	release []string
+	pre     [2]int // phase rank, number
+	post    int
+	dev     int
+	local   string
+}
+
+var pep440PreRank = map[string]int{
+	"a": 0, "alpha": 0,
+	"b": 1, "beta": 1,
+	"c": 2, "rc": 2, "pre": 2, "preview": 2,
+}
+
+func parsePEP440(v string) (pep440Version, bool) {
+	m := pep440Pattern.FindStringSubmatch(strings.ToLower(v))
+	if m == nil {
+		return pep440Version{}, false
+	}
+	pv := pep440Version{
+		epoch:   atoiDefault(m[1], 0),
+		release: strings.Split(m[2], "."),
+		local:   m[10],
+	}
+
+	hasPre := m[3] != ""
+	hasPost := m[5] != "" || m[6] != ""
+	hasDev := m[8] != ""
+
+	// Sort keys follow packaging.version._cmpkey.
+	switch {
+	case hasPre:
+		pv.pre = [2]int{pep440PreRank[m[3]], atoiDefault(m[4], 0)}
+	case !hasPost && hasDev:
+		pv.pre = [2]int{math.MinInt32, 0}
+	default:
+		pv.pre = [2]int{math.MaxInt32, 0}
+	}
+
+	switch {
+	case m[5] != "":
+		pv.post = atoiDefault(m[5], 0)
+	case hasPost:
+		pv.post = atoiDefault(m[7], 0)
+	default:
+		pv.post = math.MinInt32
+	}
+
+	if hasDev {
+		pv.dev = atoiDefault(m[9], 0)
+	} else {
+		pv.dev = math.MaxInt32
+	}
+	return pv, true
+}
+
+// comparePEP440 orders Python versions; unparseable input falls back to the
+// generic comparison.
+func comparePEP440(a, b string) int {
+	av, okA := parsePEP440(a)
+	bv, okB := parsePEP440(b)
+	if !okA || !okB {
+		return compareGeneric(a, b)
+	}
+	if av.epoch != bv.epoch {
+		return av.epoch - bv.epoch
+	}
+	if c := compareSegments(av.release, bv.release); c != 0 {
+		return c
+	}
+	for _, pair := range [][2]int{
+		{av.pre[0], bv.pre[0]},
+		{av.pre[1], bv.pre[1]},
+		{av.post, bv.post},
+		{av.dev, bv.dev},
+	} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	// A local version label sorts after the same public version.
+	switch {
+	case av.local == bv.local:
+		return 0
+	case av.local == "":
+		return -1
+	case bv.local == "":
+		return 1
+	}
+	return compareSegments(splitAlnum(av.local), splitAlnum(bv.local))
+}
@@ -0,0 +1,320 @@
+package versioning
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Constraint is a single comparator such as ">=1.2.0".
+type Constraint struct {
+	Op      string `json:"op"`
+	Version string `json:"version"`
+}
+
+// Range is a disjunction of constraint groups; a version is in the range when
+// it satisfies every constraint of at least one group.
+type Range struct {
+	Scheme Scheme
+	Any    bool
+	Groups [][]Constraint
+}
+
+var constraintPattern = regexp.MustCompile(`^(>=|<=|!=|==|~=|=|<|>|\^|~)?\s*(\S+)$`)
+
+// ParseRange parses a version range expression for the given ecosystem. It
+// accepts comparator lists ("<4.17.21", ">=1.0, <2.0", "^1.2 || ~2.3"),
+// Maven/NuGet interval notation ("[1.0,2.0)", "(,1.5]") and vers URIs
+// ("vers:npm/>=1.0.0|<2.0.0"). An empty expression or "*" matches everything.
+func ParseRange(ecosystem, expr string) (Range, error) {
+	expr = strings.TrimSpace(expr)
+	r := Range{Scheme: SchemeFor(ecosystem)}
+
+	switch {
+	case expr == "" || expr == "*":
+		r.Any = true
+		return r, nil
+	case strings.HasPrefix(expr, "vers:"):
+		return parseVers(ecosystem, expr)
+	case strings.HasPrefix(expr, "[") || strings.HasPrefix(expr, "("):
+		return parseIntervals(r, expr)
+	}
+
+	for _, alt := range strings.Split(expr, "||") {
+		var group []Constraint
+		for _, tok := range tokenizeConstraints(alt) {
+			cs, err := expandConstraint(r.Scheme, tok)
+			if err != nil {
+				return Range{}, err
+			}
+			group = append(group, cs...)
+		}
+		if len(group) == 0 {
+			return Range{}, fmt.Errorf("empty range alternative in %q", expr)
+		}
+		r.Groups = append(r.Groups, group)
+	}
+	return r, nil
+}
+
+// Contains reports whether version lies in the range.
+func (r Range) Contains(version string) bool {
+	if r.Any {
+		return true
+	}
+	for _, group := range r.Groups {
+		ok := true
+		for _, c := range group {
+			if !c.Matches(r.Scheme, version) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the range as a comparator expression.
+func (r Range) String() string {
+	if r.Any {
+		return "*"
+	}
+	alts := make([]string, 0, len(r.Groups))
+	for _, group := range r.Groups {
+		parts := make([]string, 0, len(group))
+		for _, c := range group {
+			parts = append(parts, c.Op+c.Version)
+		}
+		alts = append(alts, strings.Join(parts, ", "))
+	}
+	return strings.Join(alts, " || ")
+}
+
+// Matches reports whether version satisfies the constraint under scheme.
+func (c Constraint) Matches(s Scheme, version string) bool {
+	cmp := s.Compare(version, c.Version)
+	switch c.Op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// tokenizeConstraints splits "> = 1.0, <2.0" style input into comparator
+// tokens, gluing a bare operator to the version that follows it.
+func tokenizeConstraints(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	var tokens []string
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if constraintPattern.MatchString(f) && !isOperatorOnly(f) {
+			tokens = append(tokens, f)
+			continue
+		}
+		if isOperatorOnly(f) && i+1 < len(fields) {
+			tokens = append(tokens, f+fields[i+1])
+			i++
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
+
+func isOperatorOnly(s string) bool {
+	switch s {
+	case ">=", "<=", "!=", "==", "~=", "=", "<", ">", "^", "~":
+		return true
+	}
+	return false
+}
+
+// expandConstraint turns one token into primitive comparators, expanding the
+// caret, tilde and PEP 440 compatible-release shorthands.
+func expandConstraint(s Scheme, tok string) ([]Constraint, error) {
+	m := constraintPattern.FindStringSubmatch(tok)
+	if m == nil || isOperatorOnly(m[2]) {
+		return nil, fmt.Errorf("invalid version constraint %q", tok)
+	}
+	op, v := m[1], m[2]
+	switch op {
+	case "", "=", "==":
+		if strings.HasSuffix(v, ".*") {
+			prefix := strings.TrimSuffix(v, ".*")
+			return []Constraint{{">=", prefix}, {"<", bumpAt(prefix, len(strings.Split(prefix, "."))-1)}}, nil
+		}
+		return []Constraint{{"=", v}}, nil
+	case "^":
+		parts := strings.Split(strings.TrimLeft(v, "v"), ".")
+		idx := 0
+		for idx < len(parts)-1 && strings.Trim(parts[idx], "0") == "" {
+			idx++
+		}
+		return []Constraint{{">=", v}, {"<", bumpAt(v, idx)}}, nil
+	case "~":
+		parts := strings.Split(strings.TrimLeft(v, "v"), ".")
+		if len(parts) == 1 {
+			return []Constraint{{">=", v}, {"<", bumpAt(v, 0)}}, nil
+		}
+		return []Constraint{{">=", v}, {"<", bumpAt(v, 1)}}, nil
+	case "~=":
+		parts := strings.Split(v, ".")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("compatible release %q needs two release segments", tok)
+		}
+		return []Constraint{{">=", v}, {"<", bumpAt(v, len(parts)-2)}}, nil
+	default:
+		return []Constraint{{op, v}}, nil
+	}
+}
+
+// bumpAt increments the numeric release segment at idx and truncates the rest,
+// e.g. bumpAt("1.4.5", 1) == "1.5".
+func bumpAt(v string, idx int) string {
+	core := strings.TrimLeft(v, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if idx >= len(parts) {
+		idx = len(parts) - 1
+	}
+	n, _ := strconv.Atoi(parts[idx])
+	parts[idx] = strconv.Itoa(n + 1)
+	return strings.Join(parts[:idx+1], ".")
+}
+
+// intervalPattern matches one Maven/NuGet interval such as "[1.0,2.0)".
+var intervalPattern = regexp.MustCompile(`([\[(])\s*([^,\[\]()]*?)\s*(?:,\s*([^,\[\]()]*?)\s*)?([\])])`)
+
+func parseIntervals(r Range, expr string) (Range, error) {
+	matches := intervalPattern.FindAllStringSubmatch(expr, -1)
+	if len(matches) == 0 {
+		return Range{}, fmt.Errorf("invalid interval range %q", expr)
+	}
+	for _, m := range matches {
+		open, lower, upper, closing := m[1], m[2], m[3], m[4]
+		isSingle := !strings.Contains(m[0], ",")
+		if isSingle {
+			if open != "[" || closing != "]" || lower == "" {
+				return Range{}, fmt.Errorf("invalid exact interval %q", m[0])
+			}
+			r.Groups = append(r.Groups, []Constraint{{"=", lower}})
+			continue
+		}
+		var group []Constraint
+		if lower != "" {
+			op := ">"
+			if open == "[" {
+				op = ">="
+			}
+			group = append(group, Constraint{op, lower})
+		}
+		if upper != "" {
+			op := "<"
+			if closing == "]" {
+				op = "<="
+			}
+			group = append(group, Constraint{op, upper})
+		}
+		if len(group) == 0 {
+			r.Any = true
+			continue
+		}
+		r.Groups = append(r.Groups, group)
+	}
+	return r, nil
+}
+
+// parseVers implements the version range specifier from the purl project:
+// "vers:<scheme>/<constraint>|<constraint>...". Constraints are sorted and
+// consecutive lower/upper bounds pair up into intervals.
+func parseVers(ecosystem, expr string) (Range, error) {
+	body := strings.TrimPrefix(expr, "vers:")
+	scheme, list, ok := strings.Cut(body, "/")
+	if !ok {
+		return Range{}, fmt.Errorf("invalid vers %q: missing scheme", expr)
+	}
+	if ecosystem == "" {
+		ecosystem = scheme
+	}
+	r := Range{Scheme: SchemeFor(ecosystem)}
+	if strings.TrimSpace(list) == "*" {
+		r.Any = true
+		return r, nil
+	}
+
+	var bounds, excludes []Constraint
+	for _, raw := range strings.Split(list, "|") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		m := constraintPattern.FindStringSubmatch(raw)
+		if m == nil {
+			return Range{}, fmt.Errorf("invalid vers constraint %q", raw)
+		}
+		c := Constraint{Op: m[1], Version: m[2]}
+		switch c.Op {
+		case "", "==":
+			c.Op = "="
+		case "^", "~", "~=":
+			return Range{}, fmt.Errorf("operator %q not allowed in vers", c.Op)
+		}
+		if c.Op == "!=" {
+			excludes = append(excludes, c)
+			continue
+		}
+		bounds = append(bounds, c)
+	}
+	sort.SliceStable(bounds, func(i, j int) bool {
+		return r.Scheme.Compare(bounds[i].Version, bounds[j].Version) < 0
+	})
+
+	var pending *Constraint
+	for i := range bounds {
+		c := bounds[i]
+		switch c.Op {
+		case "=":
+			r.Groups = append(r.Groups, []Constraint{c})
+		case ">", ">=":
+			if pending != nil {
+				r.Groups = append(r.Groups, []Constraint{*pending})
+			}
+			pending = &bounds[i]
+		case "<", "<=":
+			if pending != nil {
+				r.Groups = append(r.Groups, []Constraint{*pending, c})
+				pending = nil
+			} else {
+				r.Groups = append(r.Groups, []Constraint{c})
+			}
+		}
+	}
+	if pending != nil {
+		r.Groups = append(r.Groups, []Constraint{*pending})
+	}
+	if len(r.Groups) == 0 && len(excludes) > 0 {
+		r.Groups = [][]Constraint{{}}
+	}
+	for i := range r.Groups {
+		r.Groups[i] = append(r.Groups[i], excludes...)
+	}
+	return r, nil
+}
@@ -0,0 +1,63 @@
+package versioning
+
+import (
+	"regexp"
+	"strings"
+)
+
+var gemSegmentPattern = regexp.MustCompile(`[0-9]+|[a-zA-Z]+`)
+
+// compareRubyGems implements Gem::Version#<=>. Letter segments mark a
+// prerelease and sort before any numeric segment, so "1.0.a" < "1.0".
+func compareRubyGems(a, b string) int {
+	aSegs := gemSegments(a)
+	bSegs := gemSegments(b)
+	for i := 0; i < len(aSegs) || i < len(bSegs); i++ {
+		x, y := "0", "0"
+		if i < len(aSegs) {
+			x = aSegs[i]
+		}
+		if i < len(bSegs) {
+			y = bSegs[i]
+		}
+		if x == y {
+			continue
+		}
+		xNum, yNum := isDigits(x), isDigits(y)
+		switch {
+		case xNum && yNum:
+			return compareDigits(x, y)
+		case xNum:
+			return 1
+		case yNum:
+			return -1
+		default:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
+
+// gemSegments mirrors Gem::Version#canonical_segments: trailing zeros of the
+// release and of the prerelease part are dropped.
+func gemSegments(v string) []string {
+	segs := gemSegmentPattern.FindAllString(strings.TrimSpace(v), -1)
+	preAt := len(segs)
+	for i, s := range segs {
+		if !isDigits(s) {
+			preAt = i
+			break
+		}
+	}
+	release := trimZeroSegments(segs[:preAt])
+	pre := trimZeroSegments(segs[preAt:])
+	return append(append([]string{}, release...), pre...)
+}
+
+func trimZeroSegments(segs []string) []string {
+	end := len(segs)
+	for end > 0 && isDigits(segs[end-1]) && strings.Trim(segs[end-1], "0") == "" {
+		end--
+	}
+	return segs[:end]
+}
@@ -0,0 +1,71 @@
+package versioning
+
+import "strings"
+
+// compareSemver implements SemVer 2.0 precedence. It tolerates a leading "v"
+// or "=", missing minor/patch parts and Go's "+incompatible" suffix.
+func compareSemver(a, b string) int {
+	aCore, aPre := splitSemver(a)
+	bCore, bPre := splitSemver(b)
+	if c := compareSegments(aCore, bCore); c != 0 {
+		return c
+	}
+	return comparePrerelease(aPre, bPre, false)
+}
+
+func splitSemver(v string) ([]string, string) {
+	v = strings.TrimLeft(v, "v=")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	core, pre, _ := strings.Cut(v, "-")
+	return strings.Split(core, "."), pre
+}
+
+// comparePrerelease orders dot-separated prerelease identifiers. An empty
+// prerelease (a release) sorts after any prerelease.
+func comparePrerelease(a, b string, foldCase bool) int {
+	if foldCase {
+		a, b = strings.ToLower(a), strings.ToLower(b)
+	}
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		x, y := aIDs[i], bIDs[i]
+		xNum, yNum := isDigits(x), isDigits(y)
+		switch {
+		case xNum && yNum:
+			if c := compareDigits(x, y); c != 0 {
+				return c
+			}
+		case xNum:
+			return -1
+		case yNum:
+			return 1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return len(aIDs) - len(bIDs)
+}
+
+// compareNuGet orders NuGet versions: up to four numeric parts followed by a
+// case-insensitive SemVer prerelease. Build metadata is ignored.
+func compareNuGet(a, b string) int {
+	aCore, aPre := splitSemver(a)
+	bCore, bPre := splitSemver(b)
+	if c := compareSegments(aCore, bCore); c != 0 {
+		return c
+	}
+	return comparePrerelease(aPre, bPre, true)
+}
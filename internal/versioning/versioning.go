@@ -0,0 +1,205 @@
+// Package versioning implements ecosystem-aware version ordering and range
+// matching for package versions found in SBOMs.
+package versioning
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Scheme identifies a version ordering.
+type Scheme int
+
+const (
+	// SchemeGeneric compares dotted versions segment by segment.
+	SchemeGeneric Scheme = iota
+	// SchemeSemver follows SemVer 2.0 (npm, Go modules, Cargo, Composer).
+	SchemeSemver
+	// SchemePEP440 follows Python's PEP 440.
+	SchemePEP440
+	// SchemeMaven follows Maven's ComparableVersion.
+	SchemeMaven
+	// SchemeRubyGems follows Gem::Version.
+	SchemeRubyGems
+	// SchemeNuGet follows NuGet's SemVer 2.0 superset with a fourth part.
+	SchemeNuGet
+	// SchemeDebian follows dpkg's version comparison.
+	SchemeDebian
+	// SchemeRPM follows rpmvercmp on epoch:version-release.
+	SchemeRPM
+)
+
+var schemeNames = map[Scheme]string{
+	SchemeGeneric:  "generic",
+	SchemeSemver:   "semver",
+	SchemePEP440:   "pep440",
+	SchemeMaven:    "maven",
+	SchemeRubyGems: "rubygems",
+	SchemeNuGet:    "nuget",
+	SchemeDebian:   "debian",
+	SchemeRPM:      "rpm",
+}
+
+func (s Scheme) String() string {
+	if name, ok := schemeNames[s]; ok {
+		return name
+	}
+	return "generic"
+}
+
+// SchemeFor maps an ecosystem label onto its version scheme. It accepts both
+// the labels produced by component extraction ("pypi", "golang") and OSV
+// ecosystem names ("PyPI", "Go", "crates.io", "Debian:12").
+func SchemeFor(ecosystem string) Scheme {
+	eco := strings.ToLower(strings.TrimSpace(ecosystem))
+	if i := strings.Index(eco, ":"); i >= 0 {
+		eco = eco[:i]
+	}
+	switch eco {
+	case "npm", "go", "golang", "cargo", "crates.io", "composer", "packagist", "hex", "pub", "swift":
+		return SchemeSemver
+	case "pypi", "python":
+		return SchemePEP440
+	case "maven", "gradle", "java":
+		return SchemeMaven
+	case "gem", "rubygems", "ruby":
+		return SchemeRubyGems
+	case "nuget", "dotnet":
+		return SchemeNuGet
+	case "deb", "debian", "ubuntu":
+		return SchemeDebian
+	case "rpm", "red hat", "redhat", "rocky linux", "almalinux", "suse", "opensuse", "fedora", "centos":
+		return SchemeRPM
+	default:
+		return SchemeGeneric
+	}
+}
+
+// Compare orders two versions of the given ecosystem, returning -1, 0 or 1.
+func Compare(ecosystem, a, b string) int {
+	return SchemeFor(ecosystem).Compare(a, b)
+}
+
+// Compare orders two versions under the scheme, returning -1, 0 or 1.
+func (s Scheme) Compare(a, b string) int {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	var c int
+	switch s {
+	case SchemeSemver:
+		c = compareSemver(a, b)
+	case SchemePEP440:
+		c = comparePEP440(a, b)
+	case SchemeMaven:
+		c = compareMaven(a, b)
+	case SchemeRubyGems:
+		c = compareRubyGems(a, b)
+	case SchemeNuGet:
+		c = compareNuGet(a, b)
+	case SchemeDebian:
+		c = compareDebian(a, b)
+	case SchemeRPM:
+		c = compareRPMEVR(a, b)
+	default:
+		c = compareGeneric(a, b)
+	}
+	return sign(c)
+}
+
+// compareGeneric compares dotted versions segment by segment, numerically
+// where both segments are numbers. A "-" suffix sorts before the release.
+func compareGeneric(a, b string) int {
+	a = strings.TrimPrefix(a, "v")
+	b = strings.TrimPrefix(b, "v")
+
+	aMain, aPre, _ := strings.Cut(a, "-")
+	bMain, bPre, _ := strings.Cut(b, "-")
+	if c := compareSegments(splitAlnum(aMain), splitAlnum(bMain)); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareSegments(splitAlnum(aPre), splitAlnum(bPre))
+}
+
+func splitAlnum(v string) []string {
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// compareSegments compares two segment lists, treating missing segments as 0.
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := "0", "0"
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareNumericOrText(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareNumericOrText compares numerically when both are integers, otherwise
+// lexically.
+func compareNumericOrText(x, y string) int {
+	if isDigits(x) && isDigits(y) {
+		return compareDigits(x, y)
+	}
+	return strings.Compare(x, y)
+}
+
+// compareDigits compares arbitrarily long decimal strings without overflow.
+func compareDigits(x, y string) int {
+	x = strings.TrimLeft(x, "0")
+	y = strings.TrimLeft(y, "0")
+	if len(x) != len(y) {
+		if len(x) < len(y) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(x, y)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func atoiDefault(s string, def int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func sign(c int) int {
+	switch {
+	case c < 0:
+		return -1
+	case c > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,115 @@
+package versioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare_Ordering(t *testing.T) {
+	cases := []struct {
+		ecosystem string
+		lower     string
+		higher    string
+	}{
+		{"npm", "1.0.0-alpha", "1.0.0-alpha.1"},
+		{"npm", "1.0.0-alpha.beta", "1.0.0-beta"},
+		{"npm", "1.0.0-rc.1", "1.0.0"},
+		{"npm", "4.17.20", "4.17.21"},
+		{"golang", "v0.0.0-20210101000000-abcdef", "v0.1.0"},
+		{"golang", "v1.9.0", "v1.10.0"},
+		{"crates.io", "0.9.9", "0.10.0"},
+
+		{"pypi", "1.0.dev1", "1.0a1"},
+		{"pypi", "1.0a1", "1.0b2"},
+		{"pypi", "1.0rc1", "1.0"},
+		{"pypi", "1.0", "1.0.post1"},
+		{"pypi", "1.0", "1.0+local"},
+		{"pypi", "1.0", "1!0.5"},
+		{"pypi", "2.9", "2.10"},
+
+		{"maven", "1.0-alpha-1", "1.0-beta"},
+		{"maven", "1.0-rc1", "1.0"},
+		{"maven", "1.0-SNAPSHOT", "1.0"},
+		{"maven", "1.0", "1.0-sp1"},
+		{"maven", "2.0-beta9", "2.15.0"},
+		{"maven", "1.0.9", "1.0.10"},
+		{"maven", "1.0", "1.0.1"},
+
+		{"rubygems", "1.0.a", "1.0"},
+		{"rubygems", "1.0.a", "1.0.b"},
+		{"rubygems", "1.9", "1.10"},
+
+		{"nuget", "1.0.0-Beta", "1.0.0"},
+		{"nuget", "1.0.0.1", "1.0.0.2"},
+
+		{"debian", "1.0~rc1", "1.0"},
+		{"debian", "1.0-1", "1.0-2"},
+		{"debian", "2.0", "1:1.0"},
+		{"debian", "1.0a", "1.0+"},
+
+		{"rpm", "1.0~rc1", "1.0"},
+		{"rpm", "1.0", "1.0^git1"},
+		{"rpm", "1.0^git1", "1.0.1"},
+		{"rpm", "1.0a", "1.0.1"},
+		{"rpm", "1.0-1.el8", "1.0-2.el8"},
+	}
+	for _, tc := range cases {
+		require.Equal(t, -1, Compare(tc.ecosystem, tc.lower, tc.higher), "%s: %s < %s", tc.ecosystem, tc.lower, tc.higher)
+		require.Equal(t, 1, Compare(tc.ecosystem, tc.higher, tc.lower), "%s: %s > %s", tc.ecosystem, tc.higher, tc.lower)
+	}
+}
+
+func TestCompare_Equality(t *testing.T) {
+	require.Equal(t, 0, Compare("npm", "1.2.3", "v1.2.3"))
+	require.Equal(t, 0, Compare("npm", "1.2.3+build.5", "1.2.3"))
+	require.Equal(t, 0, Compare("pypi", "1.0", "1.0.0"))
+	require.Equal(t, 0, Compare("pypi", "1.0-post1", "1.0.post1"))
+	require.Equal(t, 0, Compare("maven", "1.0", "1.0.0-ga"))
+	require.Equal(t, 0, Compare("maven", "1.0-final", "1"))
+	require.Equal(t, 0, Compare("rubygems", "1.0.0", "1"))
+	require.Equal(t, 0, Compare("nuget", "1.0", "1.0.0.0"))
+	require.Equal(t, 0, Compare("debian", "0:1.0", "1.0"))
+}
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		ecosystem string
+		expr      string
+		in        []string
+		out       []string
+	}{
+		{"npm", "< 4.17.21", []string{"4.17.20", "3.0.0"}, []string{"4.17.21", "5.0.0"}},
+		{"npm", ">=1.0.0, <2.0.0", []string{"1.0.0", "1.9.9"}, []string{"0.9.0", "2.0.0"}},
+		{"npm", "^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"2.0.0", "1.2.2"}},
+		{"npm", "^0.2.3", []string{"0.2.9"}, []string{"0.3.0"}},
+		{"npm", "~1.2.3", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"npm", "<1.0.0 || >=3.0.0", []string{"0.5.0", "3.1.0"}, []string{"2.0.0"}},
+		{"pypi", "~=2.2", []string{"2.2", "2.9"}, []string{"3.0", "2.1"}},
+		{"pypi", "==1.4.*", []string{"1.4.0", "1.4.9"}, []string{"1.5.0"}},
+		{"maven", "[2.0-beta9,2.15.0)", []string{"2.0", "2.14.1"}, []string{"2.15.0", "1.2"}},
+		{"maven", "(,1.0],[1.2,)", []string{"1.0", "1.3"}, []string{"1.1"}},
+		{"", "vers:npm/>=1.0.0|<2.0.0|>=3.0.0", []string{"1.5.0", "3.2.0"}, []string{"2.5.0", "0.1.0"}},
+		{"", "vers:pypi/1.0|1.2|!=1.3", []string{"1.0", "1.2"}, []string{"1.1", "1.3"}},
+		{"npm", "*", []string{"0.0.1"}, nil},
+	}
+	for _, tc := range cases {
+		r, err := ParseRange(tc.ecosystem, tc.expr)
+		require.NoError(t, err, tc.expr)
+		for _, v := range tc.in {
+			require.True(t, r.Contains(v), "%s should contain %s", tc.expr, v)
+		}
+		for _, v := range tc.out {
+			require.False(t, r.Contains(v), "%s should not contain %s", tc.expr, v)
+		}
+	}
+}
+
+func TestParseRange_Invalid(t *testing.T) {
+	_, err := ParseRange("npm", ">=")
+	require.Error(t, err)
+	_, err = ParseRange("npm", "vers:npm")
+	require.Error(t, err)
+	_, err = ParseRange("maven", "[1.0")
+	require.Error(t, err)
+}
@@ -0,0 +1,3 @@
+-- Risk scoring records its own evaluation time instead of overwriting
+-- last_vuln_scan, which belongs to the vulnerability service.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS risk_evaluated_at TIMESTAMPTZ;
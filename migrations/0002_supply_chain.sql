@@ -0,0 +1,37 @@
+-- Typosquatting and dependency-confusion findings, replaced per SBOM on every
+-- check.
+CREATE TABLE IF NOT EXISTS sbom_supply_chain_findings (
+    id                BIGSERIAL PRIMARY KEY,
+    sbom_id           UUID NOT NULL REFERENCES sboms (id) ON DELETE CASCADE,
+    organization_id   INTEGER NOT NULL,
+    project_id        INTEGER NOT NULL,
+    kind              TEXT NOT NULL,
+    component_name    TEXT NOT NULL,
+    component_version TEXT,
+    ecosystem         TEXT,
+    purl              TEXT,
+    similar_to        TEXT,
+    distance          DOUBLE PRECISION,
+    reason            TEXT,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS sbom_supply_chain_findings_sbom_idx
+    ON sbom_supply_chain_findings (sbom_id, kind);
+CREATE INDEX IF NOT EXISTS sbom_supply_chain_findings_project_idx
+    ON sbom_supply_chain_findings (project_id);
+
+-- Package names an organization publishes internally; a public package with
+-- one of these names is reported as dependency confusion. A NULL ecosystem
+-- matches every ecosystem.
+CREATE TABLE IF NOT EXISTS organization_internal_packages (
+    id              BIGSERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    ecosystem       TEXT,
+    name            TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Backs ON CONFLICT DO NOTHING in PUT /api/sbom/internal-packages.
+CREATE UNIQUE INDEX IF NOT EXISTS organization_internal_packages_name_key
+    ON organization_internal_packages (organization_id, COALESCE(ecosystem, ''), name);
@@ -0,0 +1,29 @@
+-- Known-malicious packages found in uploads. sbom_id is NULL when the upload
+-- was blocked and no SBOM was stored.
+CREATE TABLE IF NOT EXISTS sbom_malicious_matches (
+    id                BIGSERIAL PRIMARY KEY,
+    sbom_id           UUID REFERENCES sboms (id) ON DELETE CASCADE,
+    organization_id   INTEGER NOT NULL,
+    project_id        INTEGER NOT NULL,
+    manifest_name     TEXT,
+    advisory_id       TEXT NOT NULL,
+    component_name    TEXT NOT NULL,
+    component_version TEXT,
+    ecosystem         TEXT,
+    summary           TEXT,
+    blocked           BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS sbom_malicious_matches_sbom_idx
+    ON sbom_malicious_matches (sbom_id);
+CREATE INDEX IF NOT EXISTS sbom_malicious_matches_project_idx
+    ON sbom_malicious_matches (project_id);
+
+-- Per-organization switches; one row per organization (SaveOrgSettings
+-- upserts on organization_id).
+CREATE TABLE IF NOT EXISTS organization_settings (
+    organization_id          INTEGER PRIMARY KEY,
+    block_malicious_packages BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at               TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
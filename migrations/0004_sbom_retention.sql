@@ -0,0 +1,32 @@
+-- Retention rules per subscription plan (organization_id NULL) or per
+-- organization; an organization row overrides its plan's. A NULL rule is not
+-- enforced.
+CREATE TABLE IF NOT EXISTS sbom_retention_policies (
+    id              SERIAL PRIMARY KEY,
+    organization_id INTEGER,
+    plan_id         INTEGER,
+    keep_revisions  INTEGER CHECK (keep_revisions IS NULL OR keep_revisions >= 1),
+    max_age_days    INTEGER CHECK (max_age_days IS NULL OR max_age_days >= 1),
+    legal_hold      BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (organization_id IS NOT NULL OR plan_id IS NOT NULL)
+);
+
+-- Backs ON CONFLICT (organization_id) WHERE organization_id IS NOT NULL in
+-- SaveOrgRetentionPolicy.
+CREATE UNIQUE INDEX IF NOT EXISTS sbom_retention_policies_org_key
+    ON sbom_retention_policies (organization_id) WHERE organization_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS sbom_retention_policies_plan_key
+    ON sbom_retention_policies (plan_id) WHERE organization_id IS NULL;
+
+-- Projects exempt from retention until the hold is released.
+CREATE TABLE IF NOT EXISTS sbom_legal_holds (
+    id              SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    project_id      INTEGER NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    reason          TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS sbom_legal_holds_org_project_idx
+    ON sbom_legal_holds (organization_id, project_id);
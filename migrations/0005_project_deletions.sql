@@ -0,0 +1,19 @@
+-- Progress of asynchronous project deletions. Rows outlive the project row
+-- they describe, so project_id has no foreign key.
+CREATE TABLE IF NOT EXISTS project_deletions (
+    project_id      INTEGER PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    project_name    TEXT NOT NULL,
+    status          TEXT NOT NULL,
+    sboms_deleted   INTEGER NOT NULL DEFAULT 0,
+    objects_deleted INTEGER NOT NULL DEFAULT 0,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    requested_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at    TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Lets the deletion worker find unfinished deletions to resume.
+CREATE INDEX IF NOT EXISTS project_deletions_pending_idx
+    ON project_deletions (updated_at) WHERE status <> 'completed';
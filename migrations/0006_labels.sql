@@ -0,0 +1,24 @@
+-- Key/value labels on projects and SBOMs. Writes replace an owner's whole
+-- set, so each key appears once per owner.
+CREATE TABLE IF NOT EXISTS project_labels (
+    project_id INTEGER NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    key        VARCHAR(63) NOT NULL,
+    value      VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, key)
+);
+
+-- Serves label selectors, which filter by key and value.
+CREATE INDEX IF NOT EXISTS project_labels_key_value_idx
+    ON project_labels (key, value);
+
+CREATE TABLE IF NOT EXISTS sbom_labels (
+    sbom_id    UUID NOT NULL REFERENCES sboms (id) ON DELETE CASCADE,
+    key        VARCHAR(63) NOT NULL,
+    value      VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (sbom_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS sbom_labels_key_value_idx
+    ON sbom_labels (key, value);
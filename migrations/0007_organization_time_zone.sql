@@ -0,0 +1,2 @@
+-- IANA zone used to bucket analytics days; NULL or empty means UTC.
+ALTER TABLE organization_settings ADD COLUMN IF NOT EXISTS time_zone TEXT;
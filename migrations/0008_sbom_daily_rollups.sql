@@ -0,0 +1,43 @@
+-- Daily SBOM and component counts per organization-local day and project.
+-- Writers accumulate into these rows with ON CONFLICT on the primary keys.
+-- Fill them for existing SBOMs with `go run ./cmd/rebuild-rollups` once the
+-- tables exist.
+CREATE TABLE IF NOT EXISTS sbom_daily_rollups (
+    day             DATE NOT NULL,
+    organization_id INTEGER NOT NULL,
+    project_id      INTEGER NOT NULL,
+    source          TEXT NOT NULL,
+    sboms           BIGINT NOT NULL DEFAULT 0,
+    components      BIGINT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (day, organization_id, project_id, source)
+);
+
+CREATE TABLE IF NOT EXISTS sbom_daily_ecosystem_rollups (
+    day             DATE NOT NULL,
+    organization_id INTEGER NOT NULL,
+    project_id      INTEGER NOT NULL,
+    ecosystem       TEXT NOT NULL,
+    components      BIGINT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (day, organization_id, project_id, ecosystem)
+);
+
+CREATE TABLE IF NOT EXISTS sbom_daily_license_rollups (
+    day             DATE NOT NULL,
+    organization_id INTEGER NOT NULL,
+    project_id      INTEGER NOT NULL,
+    license         TEXT NOT NULL,
+    components      BIGINT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (day, organization_id, project_id, license)
+);
+
+-- Analytics read an organization's window of days; transfers and deletions
+-- rewrite a project's rows.
+CREATE INDEX IF NOT EXISTS sbom_daily_rollups_org_day_idx ON sbom_daily_rollups (organization_id, day);
+CREATE INDEX IF NOT EXISTS sbom_daily_rollups_project_idx ON sbom_daily_rollups (project_id);
+CREATE INDEX IF NOT EXISTS sbom_daily_ecosystem_rollups_org_day_idx ON sbom_daily_ecosystem_rollups (organization_id, day);
+CREATE INDEX IF NOT EXISTS sbom_daily_ecosystem_rollups_project_idx ON sbom_daily_ecosystem_rollups (project_id);
+CREATE INDEX IF NOT EXISTS sbom_daily_license_rollups_org_day_idx ON sbom_daily_license_rollups (organization_id, day);
+CREATE INDEX IF NOT EXISTS sbom_daily_license_rollups_project_idx ON sbom_daily_license_rollups (project_id);
@@ -0,0 +1,16 @@
+-- Commit order of SBOM change events. created_at is taken when the producing
+-- transaction starts, so a long transaction can commit an event older than
+-- ones already read; SequenceSBOMChanges numbers events only after they have
+-- committed, under an advisory lock, and StreamSBOMChanges pages on it.
+CREATE SEQUENCE IF NOT EXISTS outbox_events_change_seq;
+
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS change_seq BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS outbox_events_change_seq_key
+    ON outbox_events (change_seq) WHERE change_seq IS NOT NULL;
+-- Finds the events still waiting for a number.
+CREATE INDEX IF NOT EXISTS outbox_events_unsequenced_idx
+    ON outbox_events (created_at, id) WHERE change_seq IS NULL;
+-- Resolves the `since` of a new stream to a position.
+CREATE INDEX IF NOT EXISTS outbox_events_sequenced_created_idx
+    ON outbox_events (created_at) WHERE change_seq IS NOT NULL;
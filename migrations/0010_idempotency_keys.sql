@@ -0,0 +1,20 @@
+-- Responses replayed for retried POSTs that carry an Idempotency-Key header.
+-- Rows expire after 24 hours and are purged hourly.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    organization_id INTEGER NOT NULL,
+    scope           TEXT NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    fingerprint     TEXT NOT NULL,
+    status          TEXT NOT NULL,
+    response_status INTEGER,
+    content_type    TEXT,
+    response_body   BYTEA,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at    TIMESTAMPTZ,
+    -- Backs ON CONFLICT (organization_id, scope, idempotency_key) in
+    -- BeginIdempotentRequest.
+    PRIMARY KEY (organization_id, scope, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_created_idx
+    ON idempotency_keys (created_at);